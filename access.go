@@ -0,0 +1,182 @@
+package activitypub
+
+import "github.com/go-ap/errors"
+
+// MembershipFn returns true if the viewer actor is a member of the col collection,
+// eg: it's one of the followers of an actor
+type MembershipFn func(col IRI, viewer IRI) bool
+
+// CanView returns true if the viewer actor is allowed to see the "it" item.
+//
+// An item can be seen by anyone if it's addressed to the Public collection, and by its author, which is
+// the actor of an activity or the actor an object is attributed to.
+// Otherwise, the viewer must be one of the to, bto, cc, bcc or audience recipients, or must be part
+// of one of the recipient collections, which is checked using the isMember function.
+// An empty viewer IRI represents an anonymous request, which can see only public items.
+//
+// Items without any recipients are visible only to their author, with the exception of actors and
+// collections, which are considered public. Links are always visible, as they don't disclose the
+// content they reference.
+func CanView(viewer IRI, it Item, isMember MembershipFn) bool {
+	if IsNil(it) {
+		return false
+	}
+	if it.IsLink() {
+		return true
+	}
+
+	var recipients ItemCollection
+	_ = OnObject(it, func(o *Object) error {
+		for _, aud := range []ItemCollection{o.To, o.Bto, o.CC, o.BCC, o.Audience} {
+			recipients = append(recipients, aud...)
+		}
+		return nil
+	})
+	if len(recipients) == 0 && (ActorTypes.Contains(it.GetType()) || it.IsCollection()) {
+		return true
+	}
+	for _, rec := range recipients {
+		if !IsNil(rec) && isPublicCollection(rec.GetLink()) {
+			return true
+		}
+	}
+	if len(viewer) == 0 {
+		return false
+	}
+	if isAuthor(viewer, it) {
+		return true
+	}
+	for _, rec := range recipients {
+		if IsNil(rec) {
+			continue
+		}
+		iri := rec.GetLink()
+		if iri.Equals(viewer, true) {
+			return true
+		}
+		if isMember != nil && isMember(iri, viewer) {
+			return true
+		}
+	}
+	return false
+}
+
+// isAuthor returns true if the viewer is the actor of the "it" activity, or if it is attributed to it
+func isAuthor(viewer IRI, it Item) bool {
+	author := false
+	typ := it.GetType()
+	if ActivityTypes.Contains(typ) || IntransitiveActivityTypes.Contains(typ) {
+		_ = OnIntransitiveActivity(it, func(act *IntransitiveActivity) error {
+			author = !IsNil(act.Actor) && act.Actor.GetLink().Equals(viewer, true)
+			return nil
+		})
+	}
+	if author {
+		return true
+	}
+	_ = OnObject(it, func(o *Object) error {
+		for _, a := range objectsOf(o.AttributedTo) {
+			if !IsNil(a) && a.GetLink().Equals(viewer, true) {
+				author = true
+				break
+			}
+		}
+		return nil
+	})
+	return author
+}
+
+// FilterCollection returns a copy of the col OrderedCollection, OrderedCollectionPage, Collection
+// or CollectionPage, which contains only the items the viewer actor can see, as determined by CanView.
+//
+// The bto and bcc recipients are removed from the copy, and from its items using Clean(), and the
+// totalItems property is decreased with the number of items that were filtered out.
+// The col collection is not modified.
+func FilterCollection(viewer IRI, col Item, isMember MembershipFn) (CollectionInterface, error) {
+	if IsNil(col) {
+		return nil, errors.NotValidf("nil collection")
+	}
+	typ := col.GetType()
+	ordered := typ == OrderedCollectionType || typ == OrderedCollectionPageType
+	if !ordered && typ != CollectionType && typ != CollectionPageType {
+		return nil, errors.NotSupportedf("unable to filter %T[%s], it is not a collection", col, typ)
+	}
+	// collections passed by value are converted to pointers, so they can be copied below
+	var err error
+	switch typ {
+	case OrderedCollectionType:
+		col, err = ToOrderedCollection(col)
+	case OrderedCollectionPageType:
+		col, err = ToOrderedCollectionPage(col)
+	case CollectionType:
+		col, err = ToCollection(col)
+	case CollectionPageType:
+		col, err = ToCollectionPage(col)
+	}
+	if err != nil {
+		return nil, errors.Annotatef(err, "unable to filter %T[%s]", col, typ)
+	}
+	// the collection is copied shallowly, as it's not modified other than replacing
+	// its items and recipients, the items we return are copied in full, as Clean() modifies them
+	cp := shallowCopy(col)
+	filter := func(ob *Object, items *ItemCollection, total *uint) error {
+		visible := make(ItemCollection, 0, len(*items))
+		for _, it := range *items {
+			if !CanView(viewer, it, isMember) {
+				continue
+			}
+			it, err := deepCopy(it)
+			if err != nil {
+				return err
+			}
+			visible = append(visible, it)
+		}
+		if removed := uint(len(*items) - len(visible)); *total >= removed {
+			*total -= removed
+		} else {
+			*total = 0
+		}
+		visible.Clean()
+		*items = visible
+		ob.Bto = nil
+		ob.BCC = nil
+		return nil
+	}
+	if ordered {
+		err = OnOrderedCollection(cp, func(c *OrderedCollection) error {
+			return OnObject(c, func(ob *Object) error {
+				return filter(ob, &c.OrderedItems, &c.TotalItems)
+			})
+		})
+	} else {
+		err = OnCollection(cp, func(c *Collection) error {
+			return OnObject(c, func(ob *Object) error {
+				return filter(ob, &c.Items, &c.TotalItems)
+			})
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	res, ok := cp.(CollectionInterface)
+	if !ok {
+		return nil, errors.Newf("unable to filter %T[%s], it is not a collection", col, typ)
+	}
+	return res, nil
+}
+
+// deepCopy returns a copy of the "it" item which doesn't share any of its properties with the original
+func deepCopy(it Item) (Item, error) {
+	if IsNil(it) || it.IsLink() {
+		return it, nil
+	}
+	data, err := MarshalJSON(it)
+	if err != nil {
+		return nil, errors.Annotatef(err, "unable to copy %s", it.GetLink())
+	}
+	cp, err := UnmarshalJSON(data)
+	if err != nil {
+		return nil, errors.Annotatef(err, "unable to copy %s", it.GetLink())
+	}
+	return cp, nil
+}
@@ -0,0 +1,191 @@
+package activitypub
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCanView(t *testing.T) {
+	alice := IRI("https://example.com/~alice")
+	bob := IRI("https://example.com/~bob")
+	followers := Followers.IRI(alice)
+	isMember := func(col, viewer IRI) bool {
+		return col.Equals(followers, true) && viewer.Equals(bob, true)
+	}
+
+	tests := []struct {
+		name   string
+		viewer IRI
+		it     Item
+		want   bool
+	}{
+		{name: "nil", viewer: bob, it: nil, want: false},
+		{name: "link", viewer: "", it: IRI("https://example.com/notes/1"), want: true},
+		{name: "public for anonymous", viewer: "", it: &Object{Type: NoteType, To: ItemCollection{PublicNS}}, want: true},
+		{name: "public alias in cc", viewer: "", it: &Object{Type: NoteType, CC: ItemCollection{IRI("as:Public")}}, want: true},
+		{name: "private for anonymous", viewer: "", it: &Object{Type: NoteType, To: ItemCollection{bob}}, want: false},
+		{name: "recipient", viewer: bob, it: &Object{Type: NoteType, To: ItemCollection{bob}}, want: true},
+		{name: "bcc recipient", viewer: bob, it: &Object{Type: NoteType, BCC: ItemCollection{bob}}, want: true},
+		{name: "not a recipient", viewer: "https://example.com/~jane", it: &Object{Type: NoteType, To: ItemCollection{bob}}, want: false},
+		{name: "follower", viewer: bob, it: &Object{Type: NoteType, To: ItemCollection{followers}}, want: true},
+		{name: "not a follower", viewer: "https://example.com/~jane", it: &Object{Type: NoteType, To: ItemCollection{followers}}, want: false},
+		{name: "author of object", viewer: alice, it: &Object{Type: NoteType, AttributedTo: alice}, want: true},
+		{name: "actor of activity", viewer: alice, it: &Activity{Type: LikeType, Actor: alice, To: ItemCollection{bob}}, want: true},
+		{name: "without recipients", viewer: bob, it: &Object{Type: NoteType, AttributedTo: alice}, want: false},
+		{name: "actor without recipients", viewer: "", it: &Actor{ID: alice, Type: PersonType}, want: true},
+		{name: "collection without recipients", viewer: "", it: &OrderedCollection{ID: followers, Type: OrderedCollectionType}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CanView(tt.viewer, tt.it, isMember); got != tt.want {
+				t.Errorf("CanView() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterCollection(t *testing.T) {
+	alice := IRI("https://example.com/~alice")
+	bob := IRI("https://example.com/~bob")
+	jane := IRI("https://example.com/~jane")
+
+	public := &Object{ID: "https://example.com/notes/1", Type: NoteType, To: ItemCollection{PublicNS}, BCC: ItemCollection{jane}}
+	private := &Object{ID: "https://example.com/notes/2", Type: NoteType, To: ItemCollection{bob}}
+	link := IRI("https://example.com/notes/3")
+
+	t.Run("ordered collection page", func(t *testing.T) {
+		col := &OrderedCollectionPage{
+			ID:           "https://example.com/~alice/inbox?page=1",
+			Type:         OrderedCollectionPageType,
+			PartOf:       IRI("https://example.com/~alice/inbox"),
+			Bto:          ItemCollection{bob},
+			OrderedItems: ItemCollection{public, private, link},
+			TotalItems:   10,
+		}
+		filtered, err := FilterCollection(alice, col, nil)
+		if err != nil {
+			t.Fatalf("FilterCollection() error = %v", err)
+		}
+		if filtered.GetType() != OrderedCollectionPageType {
+			t.Errorf("FilterCollection() type = %s, want %s", filtered.GetType(), OrderedCollectionPageType)
+		}
+		want := IRIs{public.ID, link}
+		var got IRIs
+		for _, it := range filtered.Collection() {
+			got = append(got, it.GetLink())
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("FilterCollection() items = %v, want %v", got, want)
+		}
+		_ = OnOrderedCollectionPage(filtered, func(p *OrderedCollectionPage) error {
+			if p.TotalItems != 9 {
+				t.Errorf("FilterCollection() totalItems = %d, want 9", p.TotalItems)
+			}
+			if len(p.Bto) > 0 {
+				t.Errorf("FilterCollection() bto = %v, want it removed", p.Bto)
+			}
+			if !p.PartOf.GetLink().Equals(col.PartOf.GetLink(), true) {
+				t.Errorf("FilterCollection() partOf = %v, want %v", p.PartOf, col.PartOf)
+			}
+			return nil
+		})
+		_ = OnObject(filtered.Collection().First(), func(o *Object) error {
+			if len(o.BCC) > 0 {
+				t.Errorf("FilterCollection() item bcc = %v, want it removed", o.BCC)
+			}
+			return nil
+		})
+		if len(public.BCC) != 1 || len(col.OrderedItems) != 3 || len(col.Bto) != 1 {
+			t.Errorf("FilterCollection() must not modify the original collection")
+		}
+	})
+
+	t.Run("collection", func(t *testing.T) {
+		col := &Collection{ID: "https://example.com/~bob/liked", Type: CollectionType, Items: ItemCollection{public, private}, TotalItems: 2}
+		filtered, err := FilterCollection(bob, col, nil)
+		if err != nil {
+			t.Fatalf("FilterCollection() error = %v", err)
+		}
+		if filtered.Count() != 2 {
+			t.Errorf("FilterCollection() count = %d, want 2", filtered.Count())
+		}
+	})
+
+	t.Run("collection values", func(t *testing.T) {
+		values := []Item{
+			OrderedCollection{ID: "https://example.com/~alice/outbox", Type: OrderedCollectionType, OrderedItems: ItemCollection{public, private}, TotalItems: 2},
+			OrderedCollectionPage{ID: "https://example.com/~alice/outbox?page=1", Type: OrderedCollectionPageType, OrderedItems: ItemCollection{public, private}, TotalItems: 2},
+			Collection{ID: "https://example.com/~alice/liked", Type: CollectionType, Items: ItemCollection{public, private}, TotalItems: 2},
+			CollectionPage{ID: "https://example.com/~alice/liked?page=1", Type: CollectionPageType, Items: ItemCollection{public, private}, TotalItems: 2},
+		}
+		for _, col := range values {
+			filtered, err := FilterCollection(alice, col, nil)
+			if err != nil {
+				t.Fatalf("FilterCollection(%T) error = %v", col, err)
+			}
+			if filtered.GetType() != col.GetType() {
+				t.Errorf("FilterCollection(%T) type = %s, want %s", col, filtered.GetType(), col.GetType())
+			}
+			if filtered.Count() != 1 || !filtered.Collection().First().GetLink().Equals(public.ID, true) {
+				t.Errorf("FilterCollection(%T) items = %v, want %v", col, filtered.Collection(), ItemCollection{public})
+			}
+		}
+	})
+
+	t.Run("items with nested collections", func(t *testing.T) {
+		note := &Object{
+			ID:   "https://example.com/notes/4",
+			Type: NoteType,
+			To:   ItemCollection{PublicNS},
+			Replies: &Collection{
+				ID:   "https://example.com/notes/4/replies",
+				Type: CollectionType,
+				First: &CollectionPage{
+					ID:     "https://example.com/notes/4/replies?page=1",
+					Type:   CollectionPageType,
+					PartOf: IRI("https://example.com/notes/4/replies"),
+					Items:  ItemCollection{IRI("https://example.org/notes/1")},
+				},
+			},
+			Likes: &OrderedCollection{
+				ID:           "https://example.com/notes/4/likes",
+				Type:         OrderedCollectionType,
+				OrderedItems: ItemCollection{IRI("https://example.org/likes/1"), IRI("https://example.org/likes/2")},
+				TotalItems:   2,
+			},
+		}
+		col := &OrderedCollection{ID: "https://example.com/~alice/outbox", Type: OrderedCollectionType, OrderedItems: ItemCollection{note}, TotalItems: 1}
+		filtered, err := FilterCollection(alice, col, nil)
+		if err != nil {
+			t.Fatalf("FilterCollection() error = %v", err)
+		}
+		ob, err := ToObject(filtered.Collection().First())
+		if err != nil {
+			t.Fatalf("FilterCollection() item = %v, want the Note", filtered.Collection().First())
+		}
+		if ob == note {
+			t.Errorf("FilterCollection() item must be a copy of the original")
+		}
+		replies, ok := ob.Replies.(*Collection)
+		if !ok {
+			t.Fatalf("FilterCollection() replies = %T, want *Collection", ob.Replies)
+		}
+		page, ok := replies.First.(*CollectionPage)
+		if !ok || len(page.Items) != 1 || page.Items[0].GetLink() != IRI("https://example.org/notes/1") {
+			t.Errorf("FilterCollection() replies first page = %#v, want the embedded page", replies.First)
+		}
+		likes, ok := ob.Likes.(*OrderedCollection)
+		if !ok || likes.TotalItems != 2 || len(likes.OrderedItems) != 2 {
+			t.Errorf("FilterCollection() likes = %#v, want the embedded collection", ob.Likes)
+		}
+		if ok && likes == note.Likes {
+			t.Errorf("FilterCollection() likes must be a copy of the original")
+		}
+	})
+
+	t.Run("not a collection", func(t *testing.T) {
+		if _, err := FilterCollection(alice, public, nil); err == nil {
+			t.Errorf("FilterCollection() of a %s should fail", public.Type)
+		}
+	})
+}
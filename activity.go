@@ -355,6 +355,8 @@ type Activity struct {
 	// as a form of provenance, or to support future editing by clients.
 	// In general, clients do the conversion from source to content, not the other way around.
 	Source Source `jsonld:"source,omitempty"`
+
+	Extensions Extensions `jsonld:"-"`
 	// CanReceiveActivities describes one or more entities that either performed or are expected to perform the activity.
 	// Any single activity can have multiple actors. The actor may be specified using an indirect Link.
 	Actor Item `jsonld:"actor,omitempty"`
@@ -449,12 +451,17 @@ func removeFromAudience(a *Activity, items ...Item) error {
 	return nil
 }
 
-// Recipients performs recipient de-duplication on the Activity's To, Bto, CC and BCC properties
+// Recipients performs recipient de-duplication on the Activity's To, Bto, CC and BCC properties.
+// The actor of the activity, and the object of a Block, are removed from the recipients, as
+// activities are not delivered to them.
 func (a *Activity) Recipients() ItemCollection {
 	var alwaysRemove ItemCollection
 	if a.GetType() == BlockType && a.Object != nil {
 		alwaysRemove = append(alwaysRemove, a.Object)
 	}
+	if !IsNil(a.Actor) {
+		alwaysRemove = append(alwaysRemove, a.Actor)
+	}
 	if len(alwaysRemove) > 0 {
 		_ = removeFromAudience(a, alwaysRemove...)
 	}
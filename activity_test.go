@@ -985,7 +985,7 @@ func TestActivity_MarshalJSON(t *testing.T) {
 		ID           ID
 		Type         ActivityVocabularyType
 		Name         NaturalLanguageValues
-		Attachment   Item
+		Attachment   ItemCollection
 		AttributedTo Item
 		Audience     ItemCollection
 		Content      NaturalLanguageValues
@@ -1122,10 +1122,10 @@ func TestActivity_MarshalJSON(t *testing.T) {
 		{
 			name: "Attachment",
 			fields: fields{
-				Attachment: &Object{
+				Attachment: ItemCollection{&Object{
 					ID:   "some example",
 					Type: VideoType,
-				},
+				}},
 			},
 			want:    []byte(`{"attachment":{"id":"some example","type":"Video"}}`),
 			wantErr: false,
@@ -1228,7 +1228,7 @@ func TestIntransitiveActivity_MarshalJSON(t *testing.T) {
 		ID           ID
 		Type         ActivityVocabularyType
 		Name         NaturalLanguageValues
-		Attachment   Item
+		Attachment   ItemCollection
 		AttributedTo Item
 		Audience     ItemCollection
 		Content      NaturalLanguageValues
@@ -1364,10 +1364,10 @@ func TestIntransitiveActivity_MarshalJSON(t *testing.T) {
 		{
 			name: "Attachment",
 			fields: fields{
-				Attachment: &Object{
+				Attachment: ItemCollection{&Object{
 					ID:   "some example",
 					Type: VideoType,
-				},
+				}},
 			},
 			want:    []byte(`{"attachment":{"id":"some example","type":"Video"}}`),
 			wantErr: false,
@@ -1469,7 +1469,7 @@ func TestActivity_Equals(t *testing.T) {
 		ID           ID
 		Type         ActivityVocabularyType
 		Name         NaturalLanguageValues
-		Attachment   Item
+		Attachment   ItemCollection
 		AttributedTo Item
 		Audience     ItemCollection
 		Content      NaturalLanguageValues
@@ -135,6 +135,8 @@ type Actor struct {
 	// as a form of provenance, or to support future editing by clients.
 	// In general, clients do the conversion from source to content, not the other way around.
 	Source Source `jsonld:"source,omitempty"`
+
+	Extensions Extensions `jsonld:"-"`
 	// A reference to an [ActivityStreams] OrderedCollection comprised of all the messages received by the actor;
 	// see 5.2 Inbox.
 	Inbox Item `jsonld:"inbox,omitempty"`
@@ -160,6 +162,21 @@ type Actor struct {
 	// A list of supplementary Collections which may be of interest.
 	Streams   ItemCollection `jsonld:"streams,omitempty"`
 	PublicKey PublicKey      `jsonld:"publicKey,omitempty"`
+	// ManuallyApprovesFollowers indicates that the actor reviews the Follow activities it receives,
+	// instead of accepting them automatically.
+	ManuallyApprovesFollowers bool `jsonld:"manuallyApprovesFollowers,omitempty"`
+	// Discoverable indicates that the actor agrees to be listed in directories and search results.
+	Discoverable bool `jsonld:"discoverable,omitempty"`
+	// Indexable indicates that the actor agrees for their public objects to be included in full text searches.
+	Indexable bool `jsonld:"indexable,omitempty"`
+	// Featured a link to an OrderedCollection of the objects pinned by the actor to their profile.
+	Featured Item `jsonld:"featured,omitempty"`
+	// FeaturedTags a link to a Collection of the Hashtags featured by the actor on their profile.
+	FeaturedTags Item `jsonld:"featuredTags,omitempty"`
+	// AlsoKnownAs identifies other actors representing the same entity, it's used for account migrations.
+	AlsoKnownAs ItemCollection `jsonld:"alsoKnownAs,omitempty"`
+	// MovedTo identifies the actor that this actor has migrated to.
+	MovedTo Item `jsonld:"movedTo,omitempty"`
 }
 
 // GetID returns the ID corresponding to the current Actor
@@ -400,6 +417,27 @@ func (a Actor) MarshalJSON() ([]byte, error) {
 			notEmpty = JSONWriteProp(&b, "publicKey", v) || notEmpty
 		}
 	}
+	if a.ManuallyApprovesFollowers {
+		notEmpty = JSONWriteBoolProp(&b, "manuallyApprovesFollowers", a.ManuallyApprovesFollowers) || notEmpty
+	}
+	if a.Discoverable {
+		notEmpty = JSONWriteBoolProp(&b, "discoverable", a.Discoverable) || notEmpty
+	}
+	if a.Indexable {
+		notEmpty = JSONWriteBoolProp(&b, "indexable", a.Indexable) || notEmpty
+	}
+	if a.Featured != nil {
+		notEmpty = JSONWriteItemProp(&b, "featured", a.Featured) || notEmpty
+	}
+	if a.FeaturedTags != nil {
+		notEmpty = JSONWriteItemProp(&b, "featuredTags", a.FeaturedTags) || notEmpty
+	}
+	if len(a.AlsoKnownAs) > 0 {
+		notEmpty = JSONWriteItemCollectionProp(&b, "alsoKnownAs", a.AlsoKnownAs, false) || notEmpty
+	}
+	if a.MovedTo != nil {
+		notEmpty = JSONWriteItemProp(&b, "movedTo", a.MovedTo) || notEmpty
+	}
 
 	if notEmpty {
 		JSONWrite(&b, '}')
@@ -444,7 +444,7 @@ func TestActor_Equals(t *testing.T) {
 		ID                ID
 		Type              ActivityVocabularyType
 		Name              NaturalLanguageValues
-		Attachment        Item
+		Attachment        ItemCollection
 		AttributedTo      Item
 		Audience          ItemCollection
 		Content           NaturalLanguageValues
@@ -569,3 +569,50 @@ func TestActor_Equals(t *testing.T) {
 		})
 	}
 }
+
+func TestActor_ExtensionProperties(t *testing.T) {
+	data := []byte(`{"id":"https://example.com/~jdoe","type":"Person","manuallyApprovesFollowers":true,"discoverable":true,"indexable":true,"featured":"https://example.com/~jdoe/featured","featuredTags":"https://example.com/~jdoe/tags","alsoKnownAs":["https://example.org/~jdoe"],"movedTo":"https://example.org/~jdoe"}`)
+	want := &Actor{
+		ID:                        "https://example.com/~jdoe",
+		Type:                      PersonType,
+		ManuallyApprovesFollowers: true,
+		Discoverable:              true,
+		Indexable:                 true,
+		Featured:                  IRI("https://example.com/~jdoe/featured"),
+		FeaturedTags:              IRI("https://example.com/~jdoe/tags"),
+		AlsoKnownAs:               ItemCollection{IRI("https://example.org/~jdoe")},
+		MovedTo:                   IRI("https://example.org/~jdoe"),
+	}
+
+	got := new(Actor)
+	if err := got.UnmarshalJSON(data); err != nil {
+		t.Fatalf("UnmarshalJSON() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("UnmarshalJSON() got = %#v, want %#v", got, want)
+	}
+
+	raw, err := got.GobEncode()
+	if err != nil {
+		t.Fatalf("GobEncode() error = %v", err)
+	}
+	fromGob := new(Actor)
+	if err := fromGob.GobDecode(raw); err != nil {
+		t.Fatalf("GobDecode() error = %v", err)
+	}
+	if !reflect.DeepEqual(fromGob, want) {
+		t.Errorf("GobDecode() got = %#v, want %#v", fromGob, want)
+	}
+
+	if !notEmptyActor(&Actor{Discoverable: true}) {
+		t.Errorf("notEmptyActor() should be true for an Actor with only extension properties")
+	}
+	flat := FlattenActorProperties(&Actor{Featured: &OrderedCollection{ID: "https://example.com/~jdoe/featured", Type: OrderedCollectionType}})
+	if flat.Featured != IRI("https://example.com/~jdoe/featured") {
+		t.Errorf("FlattenActorProperties() featured = %v, want IRI", flat.Featured)
+	}
+	updated, _ := UpdatePersonProperties(&Actor{}, want)
+	if !reflect.DeepEqual(updated, want) {
+		t.Errorf("UpdatePersonProperties() got = %#v, want %#v", updated, want)
+	}
+}
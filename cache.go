@@ -0,0 +1,156 @@
+package activitypub
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-ap/errors"
+)
+
+// Invalidator is implemented by the caches which need to drop the items modified by the processed activities
+type Invalidator interface {
+	Invalidate(iris ...IRI)
+}
+
+// CachingDereferencer is a Dereferencer which keeps the items fetched over HTTP in an in-memory cache.
+//
+// The items are stored in their JSON encoding, so every Load returns a fresh copy which can be
+// modified by the caller. Items younger than TTL are returned without making a request, older
+// ones are revalidated with a conditional request using the ETag and Last-Modified headers
+// of the previous response.
+// When the cache grows over MaxEntries items, the least recently used ones are dropped.
+//
+// The cache is safe for concurrent use, and can be passed as an Invalidator to an InboxProcessor
+// so the objects modified by Update and Delete activities are dropped.
+type CachingDereferencer struct {
+	HTTP HTTPDereferencer
+	// TTL is the duration a fetched item is used without being revalidated
+	TTL time.Duration
+	// MaxEntries is the maximum number of items kept in the cache, zero meaning no limit
+	MaxEntries int
+
+	mu      sync.Mutex
+	entries map[IRI]*list.Element
+	lru     *list.List
+	now     func() time.Time
+}
+
+type cacheEntry struct {
+	iri     IRI
+	data    []byte
+	v       validators
+	fetched time.Time
+}
+
+var (
+	_ Dereferencer = new(CachingDereferencer)
+	_ Invalidator  = new(CachingDereferencer)
+)
+
+// CachingDereferencerNew initializes a CachingDereferencer which fetches items using the c HTTP client
+func CachingDereferencerNew(c HTTPClient, ttl time.Duration, maxEntries int) *CachingDereferencer {
+	return &CachingDereferencer{
+		HTTP:       HTTPDereferencer{Client: c},
+		TTL:        ttl,
+		MaxEntries: maxEntries,
+	}
+}
+
+func (c *CachingDereferencer) init() {
+	if c.entries == nil {
+		c.entries = make(map[IRI]*list.Element)
+		c.lru = list.New()
+	}
+	if c.now == nil {
+		c.now = time.Now
+	}
+}
+
+// Load returns the item identified by the iri, from the cache if possible
+func (c *CachingDereferencer) Load(iri IRI) (Item, error) {
+	return c.LoadContext(context.Background(), iri)
+}
+
+// LoadContext returns the item identified by the iri, from the cache if possible,
+// using ctx for the request if one needs to be made
+func (c *CachingDereferencer) LoadContext(ctx context.Context, iri IRI) (Item, error) {
+	c.mu.Lock()
+	c.init()
+	var cached cacheEntry
+	if el, ok := c.entries[iri]; ok {
+		c.lru.MoveToFront(el)
+		cached = *el.Value.(*cacheEntry)
+	}
+	now := c.now()
+	c.mu.Unlock()
+
+	if len(cached.data) > 0 && now.Sub(cached.fetched) < c.TTL {
+		return UnmarshalJSON(cached.data)
+	}
+
+	it, v, modified, err := c.HTTP.fetch(ctx, iri, cached.v)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.Invalidate(iri)
+		}
+		return nil, err
+	}
+	if !modified {
+		if len(cached.data) == 0 {
+			return nil, errors.Newf("unexpected not modified response for %s", iri)
+		}
+		cached.fetched = now
+		c.store(cached)
+		return UnmarshalJSON(cached.data)
+	}
+
+	data, err := MarshalJSON(it)
+	if err != nil {
+		return nil, errors.Annotatef(err, "unable to encode %s", iri)
+	}
+	c.store(cacheEntry{iri: iri, data: data, v: v, fetched: now})
+	return it, nil
+}
+
+// store saves the e entry in the cache, evicting the least recently used entries if needed
+func (c *CachingDereferencer) store(e cacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.init()
+
+	if el, ok := c.entries[e.iri]; ok {
+		el.Value = &e
+		c.lru.MoveToFront(el)
+	} else {
+		c.entries[e.iri] = c.lru.PushFront(&e)
+	}
+	for c.MaxEntries > 0 && c.lru.Len() > c.MaxEntries {
+		last := c.lru.Back()
+		c.lru.Remove(last)
+		delete(c.entries, last.Value.(*cacheEntry).iri)
+	}
+}
+
+// Invalidate drops the items identified by the iris from the cache
+func (c *CachingDereferencer) Invalidate(iris ...IRI) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.init()
+
+	for _, iri := range iris {
+		if el, ok := c.entries[iri]; ok {
+			c.lru.Remove(el)
+			delete(c.entries, iri)
+		}
+	}
+}
+
+// Len returns the number of items in the cache
+func (c *CachingDereferencer) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.init()
+	return c.lru.Len()
+}
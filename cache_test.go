@@ -0,0 +1,157 @@
+package activitypub
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+type mockRemote struct {
+	sync.Mutex
+	requests    map[string]int
+	conditional map[string]int
+	version     int
+}
+
+func (m *mockRemote) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.Lock()
+	defer m.Unlock()
+	m.requests[r.URL.Path]++
+
+	etag := fmt.Sprintf(`"v%d"`, m.version)
+	if r.Header.Get("If-None-Match") == etag {
+		m.conditional[r.URL.Path]++
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	if r.URL.Path == "/missing" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Content-Type", ActivityJSONType)
+	_, _ = fmt.Fprintf(w, `{"id":"http://%s%s","type":"Person","name":"v%d"}`, r.Host, r.URL.Path, m.version)
+}
+
+func (m *mockRemote) count(path string) (int, int) {
+	m.Lock()
+	defer m.Unlock()
+	return m.requests[path], m.conditional[path]
+}
+
+func TestCachingDereferencer_Load(t *testing.T) {
+	remote := &mockRemote{requests: make(map[string]int), conditional: make(map[string]int)}
+	srv := httptest.NewServer(remote)
+	defer srv.Close()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := CachingDereferencerNew(srv.Client(), time.Minute, 2)
+	c.now = func() time.Time { return now }
+
+	alice := IRI(srv.URL + "/alice")
+	name := func(it Item) string {
+		var n string
+		_ = OnObject(it, func(o *Object) error {
+			n = o.Name.First().String()
+			return nil
+		})
+		return n
+	}
+
+	it, err := c.Load(alice)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if it.GetType() != PersonType || name(it) != "v0" {
+		t.Errorf("Load() = %s[%s], want Person v0", it.GetType(), name(it))
+	}
+
+	// a modified copy must not alter the cached item
+	_ = OnObject(it, func(o *Object) error {
+		o.Name = DefaultNaturalLanguageValue("changed")
+		return nil
+	})
+	if it, _ = c.Load(alice); name(it) != "v0" {
+		t.Errorf("Load() = %s, want the cached v0", name(it))
+	}
+	if req, _ := remote.count("/alice"); req != 1 {
+		t.Errorf("requests = %d, want 1 while the item is fresh", req)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if it, _ = c.Load(alice); name(it) != "v0" {
+		t.Errorf("Load() = %s, want the revalidated v0", name(it))
+	}
+	if req, cond := remote.count("/alice"); req != 2 || cond != 1 {
+		t.Errorf("requests = %d, conditional = %d, want a conditional request after the TTL", req, cond)
+	}
+
+	remote.Lock()
+	remote.version = 1
+	remote.Unlock()
+
+	update := &Activity{Type: UpdateType, Actor: alice, Object: &Actor{ID: alice, Type: PersonType}}
+	p := InboxProcessorNew(MemoryStoreNew(&Actor{ID: alice, Type: PersonType}))
+	p.Cache = c
+	if _, err = p.Process(update); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	if it, _ = c.Load(alice); name(it) != "v1" {
+		t.Errorf("Load() = %s, want v1 after the Update invalidated the cache", name(it))
+	}
+
+	for _, path := range []string{"/bob", "/jane"} {
+		if _, err = c.Load(IRI(srv.URL + path)); err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+	}
+	if c.Len() != 2 {
+		t.Errorf("Len() = %d, want the cache bounded to 2 items", c.Len())
+	}
+	if _, err = c.Load(alice); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if req, _ := remote.count("/alice"); req != 4 {
+		t.Errorf("requests = %d, want the least recently used item to be evicted", req)
+	}
+
+	if _, err = c.Load(IRI(srv.URL + "/missing")); err == nil {
+		t.Errorf("Load() of a missing item should fail")
+	}
+}
+
+func TestCachingDereferencer_LoadCollection(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", ActivityJSONType)
+		_, _ = fmt.Fprintf(w, `{"id":"http://%s/outbox","type":"OrderedCollection","totalItems":2,"orderedItems":[`+
+			`{"id":"http://%s/notes/1","type":"Note"},"http://%s/notes/2"]}`, r.Host, r.Host, r.Host)
+	}))
+	defer srv.Close()
+
+	c := CachingDereferencerNew(srv.Client(), time.Minute, 0)
+	outbox := IRI(srv.URL + "/outbox")
+	if _, err := c.Load(outbox); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	// the second Load returns the cached copy
+	it, err := c.Load(outbox)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	col, ok := it.(*OrderedCollection)
+	if !ok {
+		t.Fatalf("Load() = %T[%s], want *OrderedCollection", it, it.GetType())
+	}
+	if col.ID != outbox || col.TotalItems != 2 || len(col.OrderedItems) != 2 {
+		t.Fatalf("Load() = %s with %d items, want %s with 2 items", col.ID, len(col.OrderedItems), outbox)
+	}
+	if first := col.OrderedItems[0]; first.IsLink() || first.GetType() != NoteType || first.GetLink() != IRI(srv.URL+"/notes/1") {
+		t.Errorf("Load() first item = %s[%s], want the embedded Note", first.GetType(), first.GetLink())
+	}
+	if second := col.OrderedItems[1]; !second.IsLink() || second.GetLink() != IRI(srv.URL+"/notes/2") {
+		t.Errorf("Load() second item = %v, want the IRI of the second Note", second)
+	}
+}
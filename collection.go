@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/gob"
 	"fmt"
+	"iter"
 	"time"
 	"unsafe"
 
@@ -145,6 +146,8 @@ type Collection struct {
 	// as a form of provenance, or to support future editing by clients.
 	// In general, clients do the conversion from source to content, not the other way around.
 	Source Source `jsonld:"source,omitempty"`
+
+	Extensions Extensions `jsonld:"-"`
 	// In a paged Collection, indicates the page that contains the most recently updated member items.
 	Current ObjectOrLink `jsonld:"current,omitempty"`
 	// In a paged Collection, indicates the furthest preceding page of items in the collection.
@@ -221,6 +224,11 @@ func (c Collection) Collection() ItemCollection {
 	return c.Items
 }
 
+// All returns an iterator over the Collection's items
+func (c Collection) All() iter.Seq[Item] {
+	return c.Items.All()
+}
+
 // Append adds an element to a Collection
 func (c *Collection) Append(it ...Item) error {
 	for _, ob := range it {
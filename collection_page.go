@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/gob"
 	"fmt"
+	"iter"
 	"time"
 
 	"github.com/valyala/fastjson"
@@ -105,6 +106,8 @@ type CollectionPage struct {
 	// as a form of provenance, or to support future editing by clients.
 	// In general, clients do the conversion from source to content, not the other way around.
 	Source Source `jsonld:"source,omitempty"`
+
+	Extensions Extensions `jsonld:"-"`
 	// In a paged Collection, indicates the page that contains the most recently updated member items.
 	Current ObjectOrLink `jsonld:"current,omitempty"`
 	// In a paged Collection, indicates the furthest preceding page of items in the collection.
@@ -159,6 +162,11 @@ func (c CollectionPage) Collection() ItemCollection {
 	return c.Items
 }
 
+// All returns an iterator over the CollectionPage's items
+func (c CollectionPage) All() iter.Seq[Item] {
+	return c.Items.All()
+}
+
 // Count returns the maximum between the length of Items in the collection page and its TotalItems property
 func (c *CollectionPage) Count() uint {
 	if c == nil {
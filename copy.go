@@ -99,6 +99,7 @@ func CopyObjectProperties(to, from *Object) (*Object, error) {
 		to.Duration = from.Duration
 	}
 	to.Source = replaceIfSource(to.Source, from.Source)
+	to.Extensions = replaceIfExtensions(to.Extensions, from.Extensions)
 	return to, nil
 }
 
@@ -198,6 +199,13 @@ func UpdatePersonProperties(to, from *Actor) (*Actor, error) {
 	to.Followers = replaceIfItem(to.Followers, from.Followers)
 	to.Liked = replaceIfItem(to.Liked, from.Liked)
 	to.PreferredUsername = replaceIfNaturalLanguageValues(to.PreferredUsername, from.PreferredUsername)
+	to.ManuallyApprovesFollowers = from.ManuallyApprovesFollowers
+	to.Discoverable = from.Discoverable
+	to.Indexable = from.Indexable
+	to.Featured = replaceIfItem(to.Featured, from.Featured)
+	to.FeaturedTags = replaceIfItem(to.FeaturedTags, from.FeaturedTags)
+	to.AlsoKnownAs = replaceIfItemCollection(to.AlsoKnownAs, from.AlsoKnownAs)
+	to.MovedTo = replaceIfItem(to.MovedTo, from.MovedTo)
 	oldOb, _ := ToObject(to)
 	newOb, _ := ToObject(from)
 	_, err := CopyObjectProperties(oldOb, newOb)
@@ -225,6 +233,13 @@ func replaceIfNaturalLanguageValues(old, new NaturalLanguageValues) NaturalLangu
 	return new
 }
 
+func replaceIfExtensions(to, from Extensions) Extensions {
+	for n, raw := range from {
+		to.Set(n, raw)
+	}
+	return to
+}
+
 func replaceIfSource(to, from Source) Source {
 	if from.MediaType != to.MediaType {
 		return from
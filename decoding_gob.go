@@ -83,6 +83,41 @@ func unmapActorProperties(mm map[string][]byte, a *Actor) error {
 			return err
 		}
 	}
+	if raw, ok := mm["manuallyApprovesFollowers"]; ok {
+		if err = gobDecodeBool(&a.ManuallyApprovesFollowers, raw); err != nil {
+			return err
+		}
+	}
+	if raw, ok := mm["discoverable"]; ok {
+		if err = gobDecodeBool(&a.Discoverable, raw); err != nil {
+			return err
+		}
+	}
+	if raw, ok := mm["indexable"]; ok {
+		if err = gobDecodeBool(&a.Indexable, raw); err != nil {
+			return err
+		}
+	}
+	if raw, ok := mm["featured"]; ok {
+		if a.Featured, err = gobDecodeItem(raw); err != nil {
+			return err
+		}
+	}
+	if raw, ok := mm["featuredTags"]; ok {
+		if a.FeaturedTags, err = gobDecodeItem(raw); err != nil {
+			return err
+		}
+	}
+	if raw, ok := mm["alsoKnownAs"]; ok {
+		if a.AlsoKnownAs, err = gobDecodeItems(raw); err != nil {
+			return err
+		}
+	}
+	if raw, ok := mm["movedTo"]; ok {
+		if a.MovedTo, err = gobDecodeItem(raw); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -182,6 +217,11 @@ func unmapLinkProperties(mm map[string][]byte, l *Link) error {
 			return err
 		}
 	}
+	if raw, ok := mm["extensions"]; ok {
+		if err := l.Extensions.GobDecode(raw); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -342,6 +382,11 @@ func unmapObjectProperties(mm map[string][]byte, o *Object) error {
 			return err
 		}
 	}
+	if raw, ok := mm["extensions"]; ok {
+		if err := o.Extensions.GobDecode(raw); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -417,11 +462,11 @@ func gobDecodeItem(data []byte) (Item, error) {
 		}
 		switch it.GetType() {
 		case IRIType:
-		case "", ObjectType, ArticleType, AudioType, DocumentType, EventType, ImageType, NoteType, PageType, VideoType:
+		case "", ObjectType, ArticleType, AudioType, DocumentType, EventType, ImageType, NoteType, PageType, VideoType, EmojiType:
 			err = OnObject(it, func(ob *Object) error {
 				return unmapObjectProperties(mm, ob)
 			})
-		case LinkType, MentionType:
+		case LinkType, MentionType, HashtagType:
 			err = OnLink(it, func(l *Link) error {
 				return unmapLinkProperties(mm, l)
 			})
@@ -467,6 +512,14 @@ func gobDecodeItem(data []byte) (Item, error) {
 			err = OnRelationship(it, func(r *Relationship) error {
 				return unmapRelationshipProperties(mm, r)
 			})
+		case PropertyValueType:
+			err = OnPropertyValue(it, func(p *PropertyValue) error {
+				return unmapPropertyValueProperties(mm, p)
+			})
+		case IdentityProofType:
+			err = OnIdentityProof(it, func(p *IdentityProof) error {
+				return unmapIdentityProofProperties(mm, p)
+			})
 		case TombstoneType:
 			err = OnTombstone(it, func(t *Tombstone) error {
 				return unmapTombstoneProperties(mm, t)
@@ -651,6 +704,37 @@ func unmapProfileProperties(mm map[string][]byte, p *Profile) error {
 	return nil
 }
 
+func unmapPropertyValueProperties(mm map[string][]byte, p *PropertyValue) error {
+	err := OnObject(p, func(ob *Object) error {
+		return unmapObjectProperties(mm, ob)
+	})
+	if err != nil {
+		return err
+	}
+	if raw, ok := mm["value"]; ok {
+		if p.Value, err = gobDecodeNaturalLanguageValues(raw); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func unmapIdentityProofProperties(mm map[string][]byte, p *IdentityProof) error {
+	err := OnObject(p, func(ob *Object) error {
+		return unmapObjectProperties(mm, ob)
+	})
+	if err != nil {
+		return err
+	}
+	if raw, ok := mm["signatureAlgorithm"]; ok {
+		p.SignatureAlgorithm = string(raw)
+	}
+	if raw, ok := mm["signatureValue"]; ok {
+		p.SignatureValue = string(raw)
+	}
+	return nil
+}
+
 func unmapRelationshipProperties(mm map[string][]byte, r *Relationship) error {
 	err := OnObject(r, func(ob *Object) error {
 		return unmapObjectProperties(mm, ob)
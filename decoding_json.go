@@ -195,11 +195,11 @@ func JSONLoadItem(val *fastjson.Value) (Item, error) {
 	case "":
 		// NOTE(marius): this handles Tags which usually don't have types
 		fallthrough
-	case ObjectType, ArticleType, AudioType, DocumentType, EventType, ImageType, NoteType, PageType, VideoType:
+	case ObjectType, ArticleType, AudioType, DocumentType, EventType, ImageType, NoteType, PageType, VideoType, EmojiType:
 		err = OnObject(i, func(ob *Object) error {
 			return JSONLoadObject(val, ob)
 		})
-	case LinkType, MentionType:
+	case LinkType, MentionType, HashtagType:
 		err = OnLink(i, func(l *Link) error {
 			return JSONLoadLink(val, l)
 		})
@@ -245,6 +245,14 @@ func JSONLoadItem(val *fastjson.Value) (Item, error) {
 		err = OnRelationship(i, func(r *Relationship) error {
 			return JSONLoadRelationship(val, r)
 		})
+	case PropertyValueType:
+		err = OnPropertyValue(i, func(p *PropertyValue) error {
+			return JSONLoadPropertyValue(val, p)
+		})
+	case IdentityProofType:
+		err = OnIdentityProof(i, func(p *IdentityProof) error {
+			return JSONLoadIdentityProof(val, p)
+		})
 	case TombstoneType:
 		err = OnTombstone(i, func(t *Tombstone) error {
 			return JSONLoadTombstone(val, t)
@@ -408,11 +416,22 @@ func UnmarshalJSON(data []byte) (Item, error) {
 	return JSONUnmarshalToItem(val), nil
 }
 
+// UnmarshalJSONWithContext works like UnmarshalJSON, but it additionally returns the JSON-LD context
+// declared by the top level object of the document.
+func UnmarshalJSONWithContext(data []byte) (Item, LDContext, error) {
+	p := fastjson.Parser{}
+	val, err := p.ParseBytes(data)
+	if err != nil {
+		return nil, LDContext{}, err
+	}
+	return JSONUnmarshalToItem(val), JSONGetContext(val), nil
+}
+
 func GetItemByType(typ ActivityVocabularyType) (Item, error) {
 	switch typ {
-	case ObjectType, ArticleType, AudioType, DocumentType, EventType, ImageType, NoteType, PageType, VideoType:
+	case ObjectType, ArticleType, AudioType, DocumentType, EventType, ImageType, NoteType, PageType, VideoType, EmojiType:
 		return ObjectNew(typ), nil
-	case LinkType, MentionType:
+	case LinkType, MentionType, HashtagType:
 		return &Link{Type: typ}, nil
 	case ActivityType, AcceptType, AddType, AnnounceType, BlockType, CreateType, DeleteType, DislikeType,
 		FlagType, FollowType, IgnoreType, InviteType, JoinType, LeaveType, LikeType, ListenType, MoveType, OfferType,
@@ -436,6 +455,10 @@ func GetItemByType(typ ActivityVocabularyType) (Item, error) {
 		return &Profile{Type: typ}, nil
 	case RelationshipType:
 		return &Relationship{Type: typ}, nil
+	case PropertyValueType:
+		return &PropertyValue{Type: typ}, nil
+	case IdentityProofType:
+		return &IdentityProof{Type: typ}, nil
 	case TombstoneType:
 		return &Tombstone{Type: typ}, nil
 	case QuestionType:
@@ -500,6 +523,7 @@ func JSONLoadObject(val *fastjson.Value, o *Object) error {
 	o.Likes = JSONGetItem(val, "likes")
 	o.Shares = JSONGetItem(val, "shares")
 	o.Source = GetAPSource(val)
+	o.Extensions = JSONGetExtensions(val)
 	return nil
 }
 
@@ -540,6 +564,13 @@ func JSONLoadActor(val *fastjson.Value, a *Actor) error {
 	a.Endpoints = JSONGetActorEndpoints(val, "endpoints")
 	a.Streams = JSONGetItems(val, "streams")
 	a.PublicKey = JSONGetPublicKey(val, "publicKey")
+	a.ManuallyApprovesFollowers = JSONGetBoolean(val, "manuallyApprovesFollowers")
+	a.Discoverable = JSONGetBoolean(val, "discoverable")
+	a.Indexable = JSONGetBoolean(val, "indexable")
+	a.Featured = JSONGetItem(val, "featured")
+	a.FeaturedTags = JSONGetItem(val, "featuredTags")
+	a.AlsoKnownAs = JSONGetItems(val, "alsoKnownAs")
+	a.MovedTo = JSONGetItem(val, "movedTo")
 	return OnObject(a, func(o *Object) error {
 		return JSONLoadObject(val, o)
 	})
@@ -614,6 +645,21 @@ func JSONLoadRelationship(val *fastjson.Value, r *Relationship) error {
 	})
 }
 
+func JSONLoadPropertyValue(val *fastjson.Value, p *PropertyValue) error {
+	p.Value = JSONGetNaturalLanguageField(val, "value")
+	return OnObject(p, func(o *Object) error {
+		return JSONLoadObject(val, o)
+	})
+}
+
+func JSONLoadIdentityProof(val *fastjson.Value, p *IdentityProof) error {
+	p.SignatureAlgorithm = JSONGetString(val, "signatureAlgorithm")
+	p.SignatureValue = JSONGetString(val, "signatureValue")
+	return OnObject(p, func(o *Object) error {
+		return JSONLoadObject(val, o)
+	})
+}
+
 func JSONLoadTombstone(val *fastjson.Value, t *Tombstone) error {
 	t.FormerType = ActivityVocabularyType(JSONGetString(val, "formerType"))
 	t.Deleted = JSONGetTime(val, "deleted")
@@ -649,6 +695,7 @@ func JSONLoadLink(val *fastjson.Value, l *Link) error {
 			l.Rel = rr
 		}
 	}
+	l.Extensions = JSONGetExtensions(val)
 	return nil
 }
 
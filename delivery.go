@@ -0,0 +1,154 @@
+package activitypub
+
+import "fmt"
+
+// LoadFn dereferences an IRI, returning the object, actor or collection it identifies.
+// It's used for expanding the actors and collections addressed by an activity.
+type LoadFn func(IRI) (Item, error)
+
+// DeliveryInboxes returns the inboxes the "it" activity has to be delivered to.
+//
+// https://www.w3.org/TR/activitypub/#delivery
+//
+// The recipients in the To, Bto, CC, BCC and Audience properties of the activity are dereferenced
+// using the load function, and the collections among them are expanded into their members, following
+// their pages if needed. The actor of the activity, the object of a Block activity and the Public
+// collection are never delivered to.
+//
+// The resulting list contains each inbox only once, and actors that share a sharedInbox endpoint
+// are delivered to through it instead of their own inboxes.
+// Recipients which can't be loaded, or which are not actors or collections, are skipped.
+func DeliveryInboxes(it Item, load LoadFn) (IRIs, error) {
+	if IsNil(it) {
+		return nil, nil
+	}
+	if !ActivityTypes.Contains(it.GetType()) && !IntransitiveActivityTypes.Contains(it.GetType()) {
+		return nil, fmt.Errorf("%T[%s] is not an activity", it, it.GetType())
+	}
+
+	d := deliveryResolver{load: load, seen: make(map[IRI]struct{})}
+	var recipients ItemCollection
+	err := OnIntransitiveActivity(it, func(act *IntransitiveActivity) error {
+		if !IsNil(act.Actor) {
+			d.exclude = append(d.exclude, act.Actor.GetLink())
+		}
+		for _, aud := range []ItemCollection{act.To, act.Bto, act.CC, act.BCC, act.Audience} {
+			recipients = append(recipients, aud...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if it.GetType() == BlockType {
+		_ = OnActivity(it, func(act *Activity) error {
+			if !IsNil(act.Object) {
+				d.exclude = append(d.exclude, act.Object.GetLink())
+			}
+			return nil
+		})
+	}
+
+	for _, rec := range recipients {
+		d.resolve(rec)
+	}
+	return d.inboxes, nil
+}
+
+type deliveryResolver struct {
+	load    LoadFn
+	exclude IRIs
+	seen    map[IRI]struct{}
+	inboxes IRIs
+}
+
+// isPublicCollection returns true if iri is one of the representations of the Public collection
+func isPublicCollection(iri IRI) bool {
+	return iri == PublicNS || iri == "as:Public" || iri == "Public"
+}
+
+func (d *deliveryResolver) resolve(it Item) {
+	if IsNil(it) {
+		return
+	}
+	iri := it.GetLink()
+	if len(iri) > 0 {
+		if isPublicCollection(iri) || d.exclude.Contains(iri) {
+			return
+		}
+		if _, ok := d.seen[iri]; ok {
+			return
+		}
+		d.seen[iri] = struct{}{}
+	}
+
+	if it.IsLink() || (ActorTypes.Contains(it.GetType()) && len(actorInbox(it)) == 0) {
+		if d.load == nil || len(iri) == 0 {
+			return
+		}
+		loaded, err := d.load(iri)
+		if err != nil || IsNil(loaded) {
+			return
+		}
+		it = loaded
+	}
+
+	switch {
+	case it.IsCollection():
+		d.expand(it)
+	case ActorTypes.Contains(it.GetType()):
+		if inbox := actorInbox(it); len(inbox) > 0 && !d.inboxes.Contains(inbox) {
+			d.inboxes = append(d.inboxes, inbox)
+		}
+	}
+}
+
+// expand resolves the members of the col collection, and then the ones of the next page, if any
+func (d *deliveryResolver) expand(col Item) {
+	_ = OnCollectionIntf(col, func(c CollectionInterface) error {
+		for _, member := range c.Collection() {
+			d.resolve(member)
+		}
+		return nil
+	})
+
+	var next Item
+	switch col.GetType() {
+	case CollectionType:
+		_ = OnCollection(col, func(c *Collection) error {
+			next = c.First
+			return nil
+		})
+	case OrderedCollectionType:
+		_ = OnOrderedCollection(col, func(c *OrderedCollection) error {
+			next = c.First
+			return nil
+		})
+	case CollectionPageType:
+		_ = OnCollectionPage(col, func(p *CollectionPage) error {
+			next = p.Next
+			return nil
+		})
+	case OrderedCollectionPageType:
+		_ = OnOrderedCollectionPage(col, func(p *OrderedCollectionPage) error {
+			next = p.Next
+			return nil
+		})
+	}
+	d.resolve(next)
+}
+
+// actorInbox returns the sharedInbox endpoint of the actor if it has one, and its inbox otherwise
+func actorInbox(it Item) IRI {
+	var inbox IRI
+	_ = OnActor(it, func(a *Actor) error {
+		if a.Endpoints != nil && !IsNil(a.Endpoints.SharedInbox) {
+			inbox = a.Endpoints.SharedInbox.GetLink()
+		}
+		if len(inbox) == 0 && !IsNil(a.Inbox) {
+			inbox = a.Inbox.GetLink()
+		}
+		return nil
+	})
+	return inbox
+}
@@ -0,0 +1,139 @@
+package activitypub
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func mockDeliveryActor(id IRI, shared IRI) *Actor {
+	a := PersonNew(id)
+	a.Inbox = id.AddPath("inbox")
+	if len(shared) > 0 {
+		a.Endpoints = &Endpoints{SharedInbox: shared}
+	}
+	return a
+}
+
+func mockLoadFn(items ...Item) LoadFn {
+	return func(iri IRI) (Item, error) {
+		for _, it := range items {
+			if it.GetLink() == iri {
+				return it, nil
+			}
+		}
+		return nil, fmt.Errorf("%s not found", iri)
+	}
+}
+
+func TestDeliveryInboxes(t *testing.T) {
+	alice := mockDeliveryActor("https://example.com/alice", "https://example.com/inbox")
+	bob := mockDeliveryActor("https://example.com/bob", "https://example.com/inbox")
+	jane := mockDeliveryActor("https://example.org/jane", "")
+	sender := mockDeliveryActor("https://example.net/sender", "")
+
+	followers := OrderedCollectionNew("https://example.net/sender/followers")
+	followers.First = IRI("https://example.net/sender/followers?page=1")
+	page1 := OrderedCollectionPageNew(followers)
+	page1.ID = "https://example.net/sender/followers?page=1"
+	page1.OrderedItems = ItemCollection{alice.GetLink(), sender.GetLink()}
+	page1.Next = IRI("https://example.net/sender/followers?page=2")
+	page2 := OrderedCollectionPageNew(followers)
+	page2.ID = "https://example.net/sender/followers?page=2"
+	page2.OrderedItems = ItemCollection{jane.GetLink()}
+	// a misbehaving server linking back to the first page
+	page2.Next = page1.GetLink()
+
+	load := mockLoadFn(alice, bob, jane, sender, followers, page1, page2)
+
+	tests := []struct {
+		name    string
+		it      Item
+		load    LoadFn
+		want    IRIs
+		wantErr bool
+	}{
+		{
+			name: "nil",
+			it:   nil,
+		},
+		{
+			name:    "not an activity",
+			it:      ObjectNew(NoteType),
+			wantErr: true,
+		},
+		{
+			name: "actors with and without shared inbox",
+			it: &Activity{
+				Type:  CreateType,
+				Actor: sender.GetLink(),
+				To:    ItemCollection{alice.GetLink(), jane.GetLink()},
+				CC:    ItemCollection{bob.GetLink()},
+			},
+			load: load,
+			want: IRIs{"https://example.com/inbox", "https://example.org/jane/inbox"},
+		},
+		{
+			name: "public aliases are dropped",
+			it: &Activity{
+				Type:  CreateType,
+				Actor: sender.GetLink(),
+				To:    ItemCollection{PublicNS, IRI("as:Public"), IRI("Public"), jane.GetLink()},
+			},
+			load: load,
+			want: IRIs{"https://example.org/jane/inbox"},
+		},
+		{
+			name: "paged followers collection",
+			it: &Activity{
+				Type:  AnnounceType,
+				Actor: sender.GetLink(),
+				To:    ItemCollection{PublicNS},
+				CC:    ItemCollection{followers.GetLink()},
+			},
+			load: load,
+			want: IRIs{"https://example.com/inbox", "https://example.org/jane/inbox"},
+		},
+		{
+			name: "block object is dropped",
+			it: &Activity{
+				Type:   BlockType,
+				Actor:  sender.GetLink(),
+				Object: jane.GetLink(),
+				To:     ItemCollection{jane.GetLink(), bob.GetLink()},
+			},
+			load: load,
+			want: IRIs{"https://example.com/inbox"},
+		},
+		{
+			name: "inline actors don't need loading",
+			it: &IntransitiveActivity{
+				Type:  ArriveType,
+				Actor: sender,
+				Bto:   ItemCollection{jane, sender},
+			},
+			want: IRIs{"https://example.org/jane/inbox"},
+		},
+		{
+			name: "unknown recipients are skipped",
+			it: &Activity{
+				Type:  LikeType,
+				Actor: sender.GetLink(),
+				To:    ItemCollection{IRI("https://example.com/unknown"), bob.GetLink()},
+			},
+			load: load,
+			want: IRIs{"https://example.com/inbox"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DeliveryInboxes(tt.it, tt.load)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DeliveryInboxes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DeliveryInboxes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package activitypub
+
+import (
+	"reflect"
+
+	"github.com/go-ap/errors"
+)
+
+// Dereferencer fetches the object, actor, activity or collection an IRI identifies.
+//
+// A Store, or a LoadFn function, can be used as a Dereferencer.
+type Dereferencer interface {
+	Load(iri IRI) (Item, error)
+}
+
+// Load calls the l function, which allows it to be used as a Dereferencer
+func (l LoadFn) Load(iri IRI) (Item, error) {
+	return l(iri)
+}
+
+// DefaultDereferenceProperties are the properties Dereference expands when none are specified
+var DefaultDereferenceProperties = []string{
+	"actor", "object", "target", "result", "origin", "instrument", "attributedTo", "inReplyTo",
+}
+
+// Dereference replaces the IRIs in the props properties of the "it" item with the items they identify,
+// as fetched by the d Dereferencer. It does the opposite of FlattenProperties.
+// The props are the JSON-LD names of the properties, when missing DefaultDereferenceProperties are used.
+//
+// The fetched items get their props properties dereferenced in turn, up to the depth level,
+// with a depth of 1 only expanding the properties of the "it" item.
+// IRIs that would form a cycle, by pointing back to an item which is being expanded, and the
+// IRIs of the Public collection are left in place.
+//
+// The "it" item is modified in place, similarly to FlattenProperties, but the fetched items are
+// copied before being modified, so the ones a Store returns remain untouched.
+// IRIs which fail to dereference are left in place, and the first such error is returned along
+// with the item.
+func Dereference(it Item, d Dereferencer, depth int, props ...string) (Item, error) {
+	if IsNil(it) {
+		return it, nil
+	}
+	if d == nil {
+		return it, errors.Newf("nil dereferencer")
+	}
+	if len(props) == 0 {
+		props = DefaultDereferenceProperties
+	}
+	for _, prop := range props {
+		if !dereferenceableProperties[prop] {
+			return it, errors.NotSupportedf("unable to dereference %q property", prop)
+		}
+	}
+	r := dereferencer{
+		d:        d,
+		props:    props,
+		visiting: make(map[IRI]struct{}),
+		loaded:   make(map[IRI]Item),
+	}
+	r.expand(it, depth)
+	return it, r.err
+}
+
+type dereferencer struct {
+	d        Dereferencer
+	props    []string
+	visiting map[IRI]struct{}
+	loaded   map[IRI]Item
+	err      error
+}
+
+// expand dereferences the properties of the "it" item, which must not be shared with the Dereferencer
+func (r *dereferencer) expand(it Item, depth int) {
+	if depth <= 0 || IsNil(it) || it.IsLink() {
+		return
+	}
+	if iri := it.GetLink(); len(iri) > 0 {
+		r.visiting[iri] = struct{}{}
+		defer delete(r.visiting, iri)
+	}
+	for _, prop := range r.props {
+		if p := propertyOf(it, prop); p != nil && !IsNil(p.get()) {
+			p.set(r.resolve(p.get(), depth))
+		}
+	}
+}
+
+// resolve returns the item the "it" IRI identifies, with its own properties dereferenced,
+// or "it" unchanged if it can't be dereferenced.
+func (r *dereferencer) resolve(it Item, depth int) Item {
+	if IsNil(it) {
+		return it
+	}
+	if IsItemCollection(it) {
+		_ = OnItemCollection(it, func(items *ItemCollection) error {
+			col := make(ItemCollection, len(*items))
+			for i, val := range *items {
+				col[i] = r.resolve(val, depth)
+			}
+			it = col
+			return nil
+		})
+		return it
+	}
+	if !it.IsLink() {
+		// embedded items can be shared with the items the Dereferencer returned
+		ob := shallowCopy(it)
+		r.expand(ob, depth-1)
+		return ob
+	}
+	iri := it.GetLink()
+	if _, ok := r.visiting[iri]; ok || len(iri) == 0 || isPublicCollection(iri) {
+		return it
+	}
+	loaded, ok := r.loaded[iri]
+	if !ok {
+		var err error
+		if loaded, err = r.d.Load(iri); err != nil || IsNil(loaded) {
+			if err != nil && r.err == nil {
+				r.err = errors.Annotatef(err, "unable to dereference %s", iri)
+			}
+			return it
+		}
+		r.loaded[iri] = loaded
+	}
+	if loaded.IsLink() {
+		return loaded
+	}
+	ob := shallowCopy(loaded)
+	r.expand(ob, depth-1)
+	return ob
+}
+
+// shallowCopy returns a copy of the struct "it" points to, so its properties can be replaced
+// without modifying the original.
+func shallowCopy(it Item) Item {
+	v := reflect.ValueOf(it)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return it
+	}
+	c := reflect.New(v.Elem().Type())
+	c.Elem().Set(v.Elem())
+	if cp, ok := c.Interface().(Item); ok {
+		return cp
+	}
+	return it
+}
+
+// property gives access to one of the properties of an item
+type property struct {
+	get func() Item
+	set func(Item)
+}
+
+func itemProperty(p *Item) *property {
+	return &property{get: func() Item { return *p }, set: func(it Item) { *p = it }}
+}
+
+func collectionProperty(p *ItemCollection) *property {
+	return &property{
+		get: func() Item { return *p },
+		set: func(it Item) {
+			if col, ok := it.(ItemCollection); ok {
+				*p = col
+			}
+		},
+	}
+}
+
+var dereferenceableProperties = map[string]bool{
+	"actor": true, "object": true, "target": true, "result": true, "origin": true, "instrument": true,
+	"attributedTo": true, "inReplyTo": true, "context": true, "generator": true, "icon": true,
+	"image": true, "preview": true, "location": true, "attachment": true, "tag": true,
+	"audience": true, "to": true, "bto": true, "cc": true, "bcc": true,
+	"replies": true, "likes": true, "shares": true,
+	"inbox": true, "outbox": true, "following": true, "followers": true, "liked": true,
+	"featured": true, "featuredTags": true, "movedTo": true, "alsoKnownAs": true,
+	"first": true, "last": true, "current": true, "next": true, "prev": true, "partOf": true,
+	"items": true, "orderedItems": true,
+}
+
+// propertyOf returns the prop property of the "it" item, or nil if "it" doesn't have such a property
+// or if its value is not an item or a collection of items
+func propertyOf(it Item, prop string) *property {
+	switch p := propertiesOf(it)[prop].(type) {
+	case *Item:
+		return itemProperty(p)
+	case *CanReceiveActivities:
+		return &property{get: func() Item { return *p }, set: func(it Item) { *p = it }}
+	case *ItemCollection:
+		return collectionProperty(p)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package activitypub
+
+import (
+	"testing"
+)
+
+func TestDereference(t *testing.T) {
+	alice := PersonNew("https://example.com/~alice")
+	bob := PersonNew("https://example.com/~bob")
+	parent := &Object{ID: "https://example.com/notes/1", Type: NoteType, AttributedTo: alice.GetLink()}
+	reply := &Object{ID: "https://example.com/notes/2", Type: NoteType, AttributedTo: bob.GetLink(), InReplyTo: parent.GetLink()}
+	// a cycle, the parent is a reply to its own reply
+	looped := &Object{ID: "https://example.com/notes/3", Type: NoteType, InReplyTo: IRI("https://example.com/notes/4")}
+	loopedReply := &Object{ID: "https://example.com/notes/4", Type: NoteType, InReplyTo: looped.GetLink()}
+
+	s := MemoryStoreNew(alice, bob, parent, reply, looped, loopedReply)
+
+	tests := []struct {
+		name    string
+		it      Item
+		depth   int
+		props   []string
+		wantErr bool
+		check   func(*testing.T, Item)
+	}{
+		{
+			name: "nil",
+		},
+		{
+			name:    "unknown property",
+			it:      &Object{Type: NoteType},
+			depth:   1,
+			props:   []string{"unknown"},
+			wantErr: true,
+		},
+		{
+			name:  "activity properties",
+			it:    &Activity{Type: LikeType, Actor: bob.GetLink(), Object: reply.GetLink(), To: ItemCollection{PublicNS}},
+			depth: 1,
+			check: func(t *testing.T, it Item) {
+				_ = OnActivity(it, func(act *Activity) error {
+					if act.Actor.IsLink() || act.Actor.GetLink() != bob.ID {
+						t.Errorf("Dereference() actor = %v, want %v", act.Actor, bob)
+					}
+					if act.Object.IsLink() || act.Object.GetLink() != reply.ID {
+						t.Errorf("Dereference() object = %v, want %s", act.Object, reply.ID)
+					}
+					return OnObject(act.Object, func(ob *Object) error {
+						if !ob.InReplyTo.IsLink() {
+							t.Errorf("Dereference() depth 1 should not expand the object properties, got %v", ob.InReplyTo)
+						}
+						return nil
+					})
+				})
+			},
+		},
+		{
+			name:  "depth limited",
+			it:    &Activity{Type: LikeType, Object: reply.GetLink()},
+			depth: 2,
+			check: func(t *testing.T, it Item) {
+				_ = OnActivity(it, func(act *Activity) error {
+					return OnObject(act.Object, func(ob *Object) error {
+						if ob.InReplyTo.IsLink() || ob.AttributedTo.IsLink() {
+							t.Errorf("Dereference() depth 2 should expand the object properties")
+						}
+						return OnObject(ob.InReplyTo, func(parent *Object) error {
+							if !parent.AttributedTo.IsLink() {
+								t.Errorf("Dereference() depth 2 should not expand the properties of the parent")
+							}
+							return nil
+						})
+					})
+				})
+			},
+		},
+		{
+			name:  "cycle",
+			it:    &Object{ID: looped.ID, Type: NoteType, InReplyTo: loopedReply.GetLink()},
+			depth: 10,
+			props: []string{"inReplyTo"},
+			check: func(t *testing.T, it Item) {
+				_ = OnObject(it, func(ob *Object) error {
+					return OnObject(ob.InReplyTo, func(r *Object) error {
+						if r.InReplyTo != looped.GetLink() {
+							t.Errorf("Dereference() inReplyTo = %v, want the %s IRI", r.InReplyTo, looped.ID)
+						}
+						return nil
+					})
+				})
+			},
+		},
+		{
+			name:    "missing items are left in place",
+			it:      &Object{Type: NoteType, InReplyTo: IRI("https://example.com/notes/missing"), AttributedTo: alice.GetLink()},
+			depth:   1,
+			wantErr: true,
+			check: func(t *testing.T, it Item) {
+				_ = OnObject(it, func(ob *Object) error {
+					if ob.InReplyTo != IRI("https://example.com/notes/missing") || ob.AttributedTo.IsLink() {
+						t.Errorf("Dereference() = %v, %v", ob.InReplyTo, ob.AttributedTo)
+					}
+					return nil
+				})
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Dereference(tt.it, s, tt.depth, tt.props...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Dereference() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.check != nil {
+				tt.check(t, got)
+			}
+		})
+	}
+
+	if !reply.InReplyTo.IsLink() {
+		t.Errorf("Dereference() must not modify the stored items")
+	}
+}
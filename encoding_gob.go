@@ -110,19 +110,26 @@ func gobEncodeItem(it Item) ([]byte, error) {
 			return err
 		})
 	}
+	if IsLink(it) {
+		err = OnLink(it, func(l *Link) error {
+			bytes, err := l.GobEncode()
+			b.Write(bytes)
+			return err
+		})
+	}
 	if IsObject(it) {
 		switch it.GetType() {
 		case IRIType:
 			var bytes []byte
 			bytes, err = it.(IRI).GobEncode()
 			b.Write(bytes)
-		case "", ObjectType, ArticleType, AudioType, DocumentType, EventType, ImageType, NoteType, PageType, VideoType:
+		case "", ObjectType, ArticleType, AudioType, DocumentType, EventType, ImageType, NoteType, PageType, VideoType, EmojiType:
 			err = OnObject(it, func(ob *Object) error {
 				bytes, err := ob.GobEncode()
 				b.Write(bytes)
 				return err
 			})
-		case LinkType, MentionType:
+		case LinkType, MentionType, HashtagType:
 			// TODO(marius): this shouldn't work, as Link does not implement Item? (or rather, should not)
 			err = OnLink(it, func(l *Link) error {
 				bytes, err := l.GobEncode()
@@ -191,6 +198,18 @@ func gobEncodeItem(it Item) ([]byte, error) {
 				b.Write(bytes)
 				return err
 			})
+		case PropertyValueType:
+			err = OnPropertyValue(it, func(p *PropertyValue) error {
+				bytes, err := p.GobEncode()
+				b.Write(bytes)
+				return err
+			})
+		case IdentityProofType:
+			err = OnIdentityProof(it, func(p *IdentityProof) error {
+				bytes, err := p.GobEncode()
+				b.Write(bytes)
+				return err
+			})
 		case TombstoneType:
 			err = OnTombstone(it, func(t *Tombstone) error {
 				bytes, err := t.GobEncode()
@@ -419,6 +438,12 @@ func mapObjectProperties(mm map[string][]byte, o *Object) (hasData bool, err err
 		}
 		hasData = true
 	}
+	if len(o.Extensions) > 0 {
+		if mm["extensions"], err = o.Extensions.GobEncode(); err != nil {
+			return hasData, err
+		}
+		hasData = true
+	}
 
 	return hasData, nil
 }
@@ -488,6 +513,48 @@ func mapActorProperties(mm map[string][]byte, a *Actor) (hasData bool, err error
 		}
 		hasData = true
 	}
+	if a.ManuallyApprovesFollowers {
+		if mm["manuallyApprovesFollowers"], err = gobEncodeBool(a.ManuallyApprovesFollowers); err != nil {
+			return hasData, err
+		}
+		hasData = true
+	}
+	if a.Discoverable {
+		if mm["discoverable"], err = gobEncodeBool(a.Discoverable); err != nil {
+			return hasData, err
+		}
+		hasData = true
+	}
+	if a.Indexable {
+		if mm["indexable"], err = gobEncodeBool(a.Indexable); err != nil {
+			return hasData, err
+		}
+		hasData = true
+	}
+	if a.Featured != nil {
+		if mm["featured"], err = gobEncodeItem(a.Featured); err != nil {
+			return hasData, err
+		}
+		hasData = true
+	}
+	if a.FeaturedTags != nil {
+		if mm["featuredTags"], err = gobEncodeItem(a.FeaturedTags); err != nil {
+			return hasData, err
+		}
+		hasData = true
+	}
+	if len(a.AlsoKnownAs) > 0 {
+		if mm["alsoKnownAs"], err = gobEncodeItems(a.AlsoKnownAs); err != nil {
+			return hasData, err
+		}
+		hasData = true
+	}
+	if a.MovedTo != nil {
+		if mm["movedTo"], err = gobEncodeItem(a.MovedTo); err != nil {
+			return hasData, err
+		}
+		hasData = true
+	}
 	return hasData, err
 }
 
@@ -658,6 +725,12 @@ func mapLinkProperties(mm map[string][]byte, l Link) (hasData bool, err error) {
 		}
 		hasData = true
 	}
+	if len(l.Extensions) > 0 {
+		if mm["extensions"], err = l.Extensions.GobEncode(); err != nil {
+			return
+		}
+		hasData = true
+	}
 	return
 }
 
@@ -717,6 +790,36 @@ func mapProfileProperties(mm map[string][]byte, p Profile) (hasData bool, err er
 	return
 }
 
+func mapPropertyValueProperties(mm map[string][]byte, p PropertyValue) (hasData bool, err error) {
+	err = OnObject(p, func(o *Object) error {
+		hasData, err = mapObjectProperties(mm, o)
+		return err
+	})
+	if len(p.Value) > 0 {
+		if mm["value"], err = p.Value.GobEncode(); err != nil {
+			return
+		}
+		hasData = true
+	}
+	return
+}
+
+func mapIdentityProofProperties(mm map[string][]byte, p IdentityProof) (hasData bool, err error) {
+	err = OnObject(p, func(o *Object) error {
+		hasData, err = mapObjectProperties(mm, o)
+		return err
+	})
+	if len(p.SignatureAlgorithm) > 0 {
+		mm["signatureAlgorithm"] = []byte(p.SignatureAlgorithm)
+		hasData = true
+	}
+	if len(p.SignatureValue) > 0 {
+		mm["signatureValue"] = []byte(p.SignatureValue)
+		hasData = true
+	}
+	return
+}
+
 func mapRelationshipProperties(mm map[string][]byte, r Relationship) (hasData bool, err error) {
 	err = OnObject(r, func(o *Object) error {
 		hasData, err = mapObjectProperties(mm, o)
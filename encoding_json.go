@@ -69,7 +69,7 @@ func JSONWriteStringProp(b *[]byte, n string, s string) (notEmpty bool) {
 }
 
 func JSONWriteBoolProp(b *[]byte, n string, t bool) (notEmpty bool) {
-	return JSONWriteProp(b, n, []byte(fmt.Sprintf(`"%t"`, t)))
+	return JSONWriteProp(b, n, []byte(fmt.Sprintf("%t", t)))
 }
 
 func JSONWriteIntProp(b *[]byte, n string, d int64) (notEmpty bool) {
@@ -286,6 +286,9 @@ func JSONWriteObjectValue(b *[]byte, o Object) (notEmpty bool) {
 	if v, err := o.Source.MarshalJSON(); err == nil && len(v) > 0 {
 		notEmpty = JSONWriteProp(b, "source", v) || notEmpty
 	}
+	if len(o.Extensions) > 0 {
+		notEmpty = JSONWriteExtensions(b, o.Type, o.Extensions) || notEmpty
+	}
 	return notEmpty
 }
 
@@ -378,10 +381,19 @@ func JSONWriteLinkValue(b *[]byte, l Link) (notEmpty bool) {
 	if len(l.HrefLang) > 0 {
 		notEmpty = JSONWriteStringProp(b, "hrefLang", string(l.HrefLang)) || notEmpty
 	}
+	if len(l.Extensions) > 0 {
+		notEmpty = JSONWriteExtensions(b, l.Type, l.Extensions) || notEmpty
+	}
 	return notEmpty
 }
 
 // MarshalJSON represents just a wrapper for the jsonld.Marshal function
+// It adds to the top level object the "@context" property returned by ItemContextFn,
+// the nested objects are left unchanged.
 func MarshalJSON(it Item) ([]byte, error) {
-	return jsonld.Marshal(it)
+	b, err := jsonld.Marshal(it)
+	if err != nil || ItemContextFn == nil || IsNil(it) {
+		return b, err
+	}
+	return jsonWriteContext(b, ItemContextFn(it)), nil
 }
@@ -53,11 +53,28 @@ func Test_JSONWriteBoolProp(t *testing.T) {
 		name         string
 		args         args
 		wantNotEmpty bool
+		want         string
 	}{
-		// TODO: Add test cases.
+		{
+			name:         "true",
+			args:         args{b: &[]byte{'{'}, n: "closed", t: true},
+			wantNotEmpty: true,
+			want:         `{"closed":true`,
+		},
+		{
+			name:         "false",
+			args:         args{b: &[]byte{'{'}, n: "closed", t: false},
+			wantNotEmpty: true,
+			want:         `{"closed":false`,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if string(*tt.args.b) != tt.want {
+					t.Errorf("JSONWriteBoolProp() wrote %s, want %s", *tt.args.b, tt.want)
+				}
+			}()
 			if gotNotEmpty := JSONWriteBoolProp(tt.args.b, tt.args.n, tt.args.t); gotNotEmpty != tt.wantNotEmpty {
 				t.Errorf("JSONWriteBoolProp() = %v, want %v", gotNotEmpty, tt.wantNotEmpty)
 			}
@@ -449,5 +466,75 @@ func Test_JSONWriteValue(t *testing.T) {
 }
 
 func TestMarshalJSON(t *testing.T) {
-	t.Skip("TODO")
+	tests := []struct {
+		name    string
+		it      Item
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "nil",
+			it:   nil,
+			want: "null",
+		},
+		{
+			name: "IRI",
+			it:   IRI("https://example.com"),
+			want: `"https://example.com"`,
+		},
+		{
+			name: "Note",
+			it:   &Object{ID: "https://example.com/1", Type: NoteType},
+			want: `{"@context":"https://www.w3.org/ns/activitystreams","id":"https://example.com/1","type":"Note"}`,
+		},
+		{
+			name: "Create with nested Note",
+			it: &Activity{
+				ID:     "https://example.com/1",
+				Type:   CreateType,
+				Object: &Object{ID: "https://example.com/2", Type: NoteType},
+			},
+			want: `{"@context":"https://www.w3.org/ns/activitystreams","id":"https://example.com/1","type":"Create","object":{"id":"https://example.com/2","type":"Note"}}`,
+		},
+		{
+			name: "Person with public key",
+			it: &Actor{
+				ID:        "https://example.com/~jdoe",
+				Type:      PersonType,
+				PublicKey: PublicKey{ID: "https://example.com/~jdoe#main-key", Owner: "https://example.com/~jdoe"},
+			},
+			want: `{"@context":["https://www.w3.org/ns/activitystreams","https://w3id.org/security/v1"],"id":"https://example.com/~jdoe","type":"Person","publicKey":{"id":"https://example.com/~jdoe#main-key","owner":"https://example.com/~jdoe"}}`,
+		},
+		{
+			name: "Person with extension properties",
+			it: &Actor{
+				ID:                        "https://example.com/~jdoe",
+				Type:                      PersonType,
+				ManuallyApprovesFollowers: true,
+				Discoverable:              true,
+				Featured:                  IRI("https://example.com/~jdoe/featured"),
+				FeaturedTags:              IRI("https://example.com/~jdoe/tags"),
+				AlsoKnownAs:               ItemCollection{IRI("https://example.org/~jdoe")},
+				MovedTo:                   IRI("https://example.org/~jdoe"),
+			},
+			want: `{"@context":["https://www.w3.org/ns/activitystreams",{"alsoKnownAs":{"@id":"as:alsoKnownAs","@type":"@id"},"discoverable":"toot:discoverable","featured":{"@id":"toot:featured","@type":"@id"},"featuredTags":{"@id":"toot:featuredTags","@type":"@id"},"manuallyApprovesFollowers":"as:manuallyApprovesFollowers","movedTo":{"@id":"as:movedTo","@type":"@id"},"toot":"http://joinmastodon.org/ns#"}],"id":"https://example.com/~jdoe","type":"Person","manuallyApprovesFollowers":true,"discoverable":true,"featured":"https://example.com/~jdoe/featured","featuredTags":"https://example.com/~jdoe/tags","alsoKnownAs":["https://example.org/~jdoe"],"movedTo":"https://example.org/~jdoe"}`,
+		},
+		{
+			name: "ItemCollection",
+			it:   ItemCollection{IRI("https://example.com/1"), IRI("https://example.com/2")},
+			want: `["https://example.com/1","https://example.com/2"]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MarshalJSON(tt.it)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("MarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if string(got) != tt.want {
+				t.Errorf("MarshalJSON() got = %s, want %s", got, tt.want)
+			}
+		})
+	}
 }
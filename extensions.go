@@ -0,0 +1,149 @@
+package activitypub
+
+import (
+	"bytes"
+	"encoding/gob"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/valyala/fastjson"
+)
+
+// Extensions holds the raw JSON values of the properties of an object that are not part
+// of the ActivityStreams vocabulary, indexed by the property name.
+//
+// They are usually properties defined by other vocabularies, like Mastodon's "sensitive",
+// "featured" or Misskey's "_misskey_content", and are written back out verbatim when encoding.
+type Extensions map[string][]byte
+
+// vocabularyProperties caches the names of the properties loaded into the fields of each of the types
+// of this package, indexed by their reflect.Type
+var vocabularyProperties sync.Map
+
+// typeProperties returns the names of the properties which are loaded into the fields of the struct
+// corresponding to the typ type, built from their "jsonld" tags. Every other property ends up in the
+// Extensions of the object.
+func typeProperties(typ ActivityVocabularyType) map[string]struct{} {
+	it, err := GetItemByType(typ)
+	if err != nil || IsNil(it) {
+		it = &Object{}
+	}
+	t := reflect.TypeOf(it)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if props, ok := vocabularyProperties.Load(t); ok {
+		return props.(map[string]struct{})
+	}
+
+	props := map[string]struct{}{"@context": {}}
+	for i := 0; i < t.NumField(); i++ {
+		tag := strings.Split(t.Field(i).Tag.Get("jsonld"), ",")
+		if len(tag[0]) == 0 || tag[0] == "-" {
+			continue
+		}
+		props[tag[0]] = struct{}{}
+		for _, opt := range tag[1:] {
+			if opt == "collapsible" {
+				props[tag[0]+"Map"] = struct{}{}
+			}
+		}
+	}
+	vocabularyProperties.Store(t, props)
+	return props
+}
+
+// IsVocabularyProperty returns true if the property with the received name gets loaded
+// into a field of the struct corresponding to the typ type.
+func IsVocabularyProperty(typ ActivityVocabularyType, name string) bool {
+	_, ok := typeProperties(typ)[name]
+	return ok
+}
+
+// Get returns the raw JSON value of the property with the received name
+func (e Extensions) Get(name string) []byte {
+	return e[name]
+}
+
+// Set stores the raw JSON value for the property with the received name.
+// It returns false for the JSON-LD keywords and the "id" and "type" properties, which every type loads.
+func (e *Extensions) Set(name string, raw []byte) bool {
+	if len(name) == 0 || name[0] == '@' || name == "id" || name == "type" {
+		return false
+	}
+	if *e == nil {
+		*e = make(Extensions)
+	}
+	(*e)[name] = raw
+	return true
+}
+
+// Names returns the names of the extension properties, in alphabetical order
+func (e Extensions) Names() []string {
+	names := make([]string, 0, len(e))
+	for n := range e {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GobEncode
+func (e Extensions) GobEncode() ([]byte, error) {
+	if len(e) == 0 {
+		return []byte{}, nil
+	}
+	b := bytes.Buffer{}
+	if err := gob.NewEncoder(&b).Encode(map[string][]byte(e)); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// GobDecode
+func (e *Extensions) GobDecode(data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+	mm, err := gobDecodeObjectAsMap(data)
+	if err != nil {
+		return err
+	}
+	*e = mm
+	return nil
+}
+
+// JSONGetExtensions loads the raw values of the properties of val which are not loaded into the fields
+// of the struct corresponding to its type
+func JSONGetExtensions(val *fastjson.Value) Extensions {
+	if val == nil {
+		return nil
+	}
+	ob, err := val.Object()
+	if err != nil {
+		return nil
+	}
+	typ := JSONGetType(val)
+	var e Extensions
+	ob.Visit(func(key []byte, v *fastjson.Value) {
+		if IsVocabularyProperty(typ, string(key)) {
+			return
+		}
+		e.Set(string(key), v.MarshalTo(nil))
+	})
+	return e
+}
+
+// JSONWriteExtensions writes the properties in e, in alphabetical order of their names, skipping the ones
+// which are written from the fields of the struct corresponding to the typ type
+func JSONWriteExtensions(b *[]byte, typ ActivityVocabularyType, e Extensions) (notEmpty bool) {
+	for _, n := range e.Names() {
+		if IsVocabularyProperty(typ, n) {
+			continue
+		}
+		notEmpty = JSONWriteProp(b, n, e[n]) || notEmpty
+	}
+	return notEmpty
+}
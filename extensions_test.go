@@ -0,0 +1,184 @@
+package activitypub
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/valyala/fastjson"
+)
+
+func TestJSONGetExtensions(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want Extensions
+	}{
+		{
+			name: "empty",
+			data: `{}`,
+			want: nil,
+		},
+		{
+			name: "only vocabulary",
+			data: `{"@context":"https://www.w3.org/ns/activitystreams","id":"https://example.com/1","type":"Note","content":"test"}`,
+			want: nil,
+		},
+		{
+			name: "mastodon and misskey",
+			data: `{"id":"https://example.com/1","type":"Note","sensitive":false,"atomUri":"https://example.com/1","_misskey_content":"**test**","conversation":{"id":"tag:example.com,2024:1"}}`,
+			want: Extensions{
+				"sensitive":        []byte(`false`),
+				"atomUri":          []byte(`"https://example.com/1"`),
+				"_misskey_content": []byte(`"**test**"`),
+				"conversation":     []byte(`{"id":"tag:example.com,2024:1"}`),
+			},
+		},
+		{
+			name: "properties of other types",
+			data: `{"id":"https://example.com/1","type":"Note","value":"test","href":"https://example.com","inbox":"https://example.com/inbox"}`,
+			want: Extensions{
+				"value": []byte(`"test"`),
+				"href":  []byte(`"https://example.com"`),
+				"inbox": []byte(`"https://example.com/inbox"`),
+			},
+		},
+		{
+			name: "PropertyValue",
+			data: `{"type":"PropertyValue","name":"Website","value":"https://example.com","valueMap":{"en":"https://example.com"}}`,
+			want: nil,
+		},
+		{
+			name: "Person",
+			data: `{"id":"https://example.com/~jdoe","type":"Person","inbox":"https://example.com/~jdoe/inbox","nameMap":{"en":"John"},"href":"https://example.com"}`,
+			want: Extensions{"href": []byte(`"https://example.com"`)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			val, err := fastjson.Parse(tt.data)
+			if err != nil {
+				t.Fatalf("invalid test data %s", err)
+			}
+			if got := JSONGetExtensions(val); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("JSONGetExtensions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtensions_Set(t *testing.T) {
+	var e Extensions
+	if e.Set("id", []byte(`"https://example.com"`)) {
+		t.Errorf("Set() should not accept property %q", "id")
+	}
+	if !e.Set("sensitive", []byte(`true`)) {
+		t.Errorf("Set() should accept extension property %q", "sensitive")
+	}
+	if got := e.Get("sensitive"); string(got) != "true" {
+		t.Errorf("Get() = %s, want %s", got, "true")
+	}
+}
+
+func TestIsVocabularyProperty(t *testing.T) {
+	tests := []struct {
+		typ  ActivityVocabularyType
+		name string
+		want bool
+	}{
+		{typ: NoteType, name: "content", want: true},
+		{typ: NoteType, name: "contentMap", want: true},
+		{typ: NoteType, name: "@context", want: true},
+		{typ: NoteType, name: "value", want: false},
+		{typ: NoteType, name: "href", want: false},
+		{typ: NoteType, name: "inbox", want: false},
+		{typ: PersonType, name: "inbox", want: true},
+		{typ: PropertyValueType, name: "value", want: true},
+		{typ: MentionType, name: "href", want: true},
+		{typ: MentionType, name: "content", want: false},
+		{typ: "", name: "content", want: true},
+		{typ: "", name: "sensitive", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.typ)+"/"+tt.name, func(t *testing.T) {
+			if got := IsVocabularyProperty(tt.typ, tt.name); got != tt.want {
+				t.Errorf("IsVocabularyProperty() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtensions_JSONRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{
+			name: "Note",
+			data: `{"id":"https://example.com/1","type":"Note","content":"test","_misskey_content":"test","sensitive":true}`,
+		},
+		{
+			name: "Person",
+			data: `{"id":"https://example.com/~jdoe","type":"Person","inbox":"https://example.com/~jdoe/inbox","_misskey_summary":"test","vcard:bday":"1970-01-01"}`,
+		},
+		{
+			name: "Create with nested Note",
+			data: `{"id":"https://example.com/2","type":"Create","object":{"id":"https://example.com/1","type":"Note","sensitive":true},"signature":{"type":"RsaSignature2017"}}`,
+		},
+		{
+			name: "Mention",
+			data: `{"type":"Mention","href":"https://example.com/~jdoe","_misskey_reaction":":heart:"}`,
+		},
+		{
+			name: "Note with properties of other types",
+			data: `{"id":"https://example.com/1","type":"Note","value":"test","href":"https://example.com"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			it, err := UnmarshalJSON([]byte(tt.data))
+			if err != nil {
+				t.Fatalf("UnmarshalJSON() error = %v", err)
+			}
+			got, err := it.(interface{ MarshalJSON() ([]byte, error) }).MarshalJSON()
+			if err != nil {
+				t.Fatalf("MarshalJSON() error = %v", err)
+			}
+			wantVal, _ := fastjson.Parse(tt.data)
+			gotVal, err := fastjson.Parse(string(got))
+			if err != nil {
+				t.Fatalf("MarshalJSON() produced invalid JSON %s: %s", got, err)
+			}
+			for _, n := range JSONGetExtensions(wantVal).Names() {
+				if w, g := wantVal.Get(n).String(), gotVal.Get(n).String(); w != g {
+					t.Errorf("MarshalJSON() property %q = %s, want %s", n, g, w)
+				}
+			}
+		})
+	}
+}
+
+func TestExtensions_GobRoundTrip(t *testing.T) {
+	ob := &Object{
+		ID:   "https://example.com/1",
+		Type: NoteType,
+		Extensions: Extensions{
+			"sensitive":        []byte(`true`),
+			"_misskey_content": []byte(`"test"`),
+		},
+	}
+	data, err := GobEncode(ob)
+	if err != nil {
+		t.Fatalf("GobEncode() error = %v", err)
+	}
+	it, err := GobDecode(data)
+	if err != nil {
+		t.Fatalf("GobDecode() error = %v", err)
+	}
+	got, err := ToObject(it)
+	if err != nil {
+		t.Fatalf("GobDecode() returned %T, expected an Object", it)
+	}
+	if !reflect.DeepEqual(got.Extensions, ob.Extensions) {
+		t.Errorf("GobDecode() extensions = %v, want %v", got.Extensions, ob.Extensions)
+	}
+}
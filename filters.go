@@ -0,0 +1,429 @@
+package activitypub
+
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-ap/errors"
+)
+
+// Filter selects the items matching some criteria
+//
+// The filters created by ByType, ByActor, Since, Before, HasTag, InReplyTo, And, Or and Not
+// can be converted to URL query parameters with FilterQuery, and back with FilterFromQuery.
+type Filter interface {
+	Match(it Item) bool
+}
+
+// The query parameters of the filters
+const (
+	typeFilterParam      = "type"
+	actorFilterParam     = "actor"
+	sinceFilterParam     = "since"
+	beforeFilterParam    = "before"
+	tagFilterParam       = "tag"
+	inReplyToFilterParam = "inReplyTo"
+)
+
+// negatedFilterPrefix marks the values of a Not filter in the URL query parameters
+const negatedFilterPrefix = "!"
+
+type (
+	typeFilter      ActivityVocabularyTypes
+	actorFilter     IRIs
+	sinceFilter     time.Time
+	beforeFilter    time.Time
+	tagFilter       []string
+	inReplyToFilter IRIs
+	andFilter       []Filter
+	orFilter        []Filter
+	notFilter       struct{ f Filter }
+)
+
+// ByType matches the items having one of the types
+func ByType(types ...ActivityVocabularyType) Filter {
+	return typeFilter(types)
+}
+
+// ByActor matches the activities having one of the iris as actor, and the objects attributed to one of them
+func ByActor(iris ...IRI) Filter {
+	return actorFilter(iris)
+}
+
+// Since matches the items published, or updated, at or after t
+func Since(t time.Time) Filter {
+	return sinceFilter(t)
+}
+
+// Before matches the items published, or updated, before t
+func Before(t time.Time) Filter {
+	return beforeFilter(t)
+}
+
+// HasTag matches the items having one of the tags, which can be given by name, with or
+// without the leading "#" or "@", or by IRI
+func HasTag(tags ...string) Filter {
+	return tagFilter(tags)
+}
+
+// InReplyTo matches the items which are replies to one of the iris
+func InReplyTo(iris ...IRI) Filter {
+	return inReplyToFilter(iris)
+}
+
+// And matches the items matching all the filters, with no filters it matches everything
+func And(filters ...Filter) Filter {
+	return andFilter(filters)
+}
+
+// Or matches the items matching at least one of the filters
+func Or(filters ...Filter) Filter {
+	return orFilter(filters)
+}
+
+// Not matches the items which don't match the f filter
+func Not(f Filter) Filter {
+	return notFilter{f: f}
+}
+
+// Filter returns the items of the collection which match the f filter
+func (i ItemCollection) Filter(f Filter) ItemCollection {
+	result := make(ItemCollection, 0)
+	for _, it := range i {
+		if f == nil || f.Match(it) {
+			result = append(result, it)
+		}
+	}
+	return result
+}
+
+// FilterItems returns the items of the col collection which match the f filter
+func FilterItems(col CollectionInterface, f Filter) ItemCollection {
+	if IsNil(col) {
+		return nil
+	}
+	return col.Collection().Filter(f)
+}
+
+func (t typeFilter) Match(it Item) bool {
+	return !IsNil(it) && ActivityVocabularyTypes(t).Contains(it.GetType())
+}
+
+func (a actorFilter) Match(it Item) bool {
+	if IsNil(it) {
+		return false
+	}
+	var actors ItemCollection
+	typ := it.GetType()
+	if ActivityTypes.Contains(typ) || IntransitiveActivityTypes.Contains(typ) {
+		_ = OnIntransitiveActivity(it, func(act *IntransitiveActivity) error {
+			actors = append(actors, objectsOf(act.Actor)...)
+			return nil
+		})
+	}
+	_ = OnObject(it, func(o *Object) error {
+		actors = append(actors, objectsOf(o.AttributedTo)...)
+		return nil
+	})
+	return matchesAny(actors, IRIs(a))
+}
+
+// timestampOf returns the time the "it" item was last updated or published, in the same way as ItemOrderTimestamp
+func timestampOf(it Item) time.Time {
+	var t time.Time
+	_ = OnObject(it, func(o *Object) error {
+		t = o.Published
+		if !o.Updated.IsZero() {
+			t = o.Updated
+		}
+		return nil
+	})
+	return t
+}
+
+func (s sinceFilter) Match(it Item) bool {
+	t := timestampOf(it)
+	return !t.IsZero() && !t.Before(time.Time(s))
+}
+
+func (b beforeFilter) Match(it Item) bool {
+	t := timestampOf(it)
+	return !t.IsZero() && t.Before(time.Time(b))
+}
+
+// normalizeTag returns the tag name without its leading "#" or "@", in lower case
+func normalizeTag(name string) string {
+	return strings.ToLower(strings.TrimLeft(name, "#@"))
+}
+
+func (t tagFilter) Match(it Item) bool {
+	if IsNil(it) {
+		return false
+	}
+	var tags ItemCollection
+	_ = OnObject(it, func(o *Object) error {
+		tags = o.Tag
+		return nil
+	})
+	for _, tag := range tags {
+		if IsNil(tag) {
+			continue
+		}
+		var name NaturalLanguageValues
+		if LinkTypes.Contains(tag.GetType()) {
+			_ = OnLink(tag, func(l *Link) error {
+				name = l.Name
+				return nil
+			})
+		} else if !tag.IsLink() {
+			_ = OnObject(tag, func(o *Object) error {
+				name = o.Name
+				return nil
+			})
+		}
+		for _, want := range t {
+			if referencedIRI(tag).Equals(IRI(want), true) || tag.GetLink().Equals(IRI(want), true) {
+				return true
+			}
+			for _, n := range name {
+				if normalizeTag(n.Value.String()) == normalizeTag(want) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func (r inReplyToFilter) Match(it Item) bool {
+	var parents ItemCollection
+	_ = OnObject(it, func(o *Object) error {
+		parents = objectsOf(o.InReplyTo)
+		return nil
+	})
+	return matchesAny(parents, IRIs(r))
+}
+
+// matchesAny returns true if one of the items is identified by one of the iris
+func matchesAny(items ItemCollection, iris IRIs) bool {
+	for _, it := range items {
+		if IsNil(it) {
+			continue
+		}
+		for _, iri := range iris {
+			if it.GetLink().Equals(iri, true) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (a andFilter) Match(it Item) bool {
+	for _, f := range a {
+		if f != nil && !f.Match(it) {
+			return false
+		}
+	}
+	return true
+}
+
+func (o orFilter) Match(it Item) bool {
+	for _, f := range o {
+		if f != nil && f.Match(it) {
+			return true
+		}
+	}
+	return false
+}
+
+func (n notFilter) Match(it Item) bool {
+	return n.f == nil || !n.f.Match(it)
+}
+
+// FilterQuery returns the URL query parameters representing the f filter.
+//
+// Each filter is represented by a parameter named after the property it checks: "type", "actor",
+// "since", "before", "tag" and "inReplyTo", whose values are ORed, and the values negated by Not
+// are prefixed with "!". The different parameters are ANDed.
+// The filters which can't be represented this way, like an Or of different kinds of filters, or
+// an And of filters of the same kind, result in a NotSupported error.
+func FilterQuery(f Filter) (url.Values, error) {
+	q := make(url.Values)
+	if err := appendFilterQuery(q, f, false); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// simpleFilterValues returns the parameter name and the values of the f filter, if it's not a composed one
+func simpleFilterValues(f Filter) (string, []string, bool) {
+	var values []string
+	switch ff := f.(type) {
+	case typeFilter:
+		for _, t := range ff {
+			values = append(values, string(t))
+		}
+		return typeFilterParam, values, true
+	case actorFilter:
+		for _, iri := range ff {
+			values = append(values, iri.String())
+		}
+		return actorFilterParam, values, true
+	case sinceFilter:
+		return sinceFilterParam, []string{time.Time(ff).UTC().Format(time.RFC3339)}, true
+	case beforeFilter:
+		return beforeFilterParam, []string{time.Time(ff).UTC().Format(time.RFC3339)}, true
+	case tagFilter:
+		return tagFilterParam, ff, true
+	case inReplyToFilter:
+		for _, iri := range ff {
+			values = append(values, iri.String())
+		}
+		return inReplyToFilterParam, values, true
+	}
+	return "", nil, false
+}
+
+func appendFilterQuery(q url.Values, f Filter, negated bool) error {
+	if name, values, ok := simpleFilterValues(f); ok {
+		if len(values) == 0 {
+			return errors.NotSupportedf("unable to represent an empty %q filter", name)
+		}
+		prefix := ""
+		if negated {
+			prefix = negatedFilterPrefix
+		}
+		for _, v := range q[name] {
+			if strings.HasPrefix(v, negatedFilterPrefix) == negated {
+				// the values of the same parameter are ORed
+				return errors.NotSupportedf("unable to represent multiple %q filters", name)
+			}
+		}
+		for _, v := range values {
+			q.Add(name, prefix+v)
+		}
+		return nil
+	}
+
+	switch ff := f.(type) {
+	case nil:
+		return nil
+	case andFilter:
+		if negated {
+			return errors.NotSupportedf("unable to represent a negated And filter")
+		}
+		for _, sub := range ff {
+			if err := appendFilterQuery(q, sub, false); err != nil {
+				return err
+			}
+		}
+		return nil
+	case orFilter:
+		if negated {
+			return errors.NotSupportedf("unable to represent a negated Or filter")
+		}
+		var name string
+		var values []string
+		for _, sub := range ff {
+			n, v, ok := simpleFilterValues(sub)
+			if !ok || (len(name) > 0 && n != name) || n == sinceFilterParam || n == beforeFilterParam {
+				return errors.NotSupportedf("unable to represent an Or of different filters")
+			}
+			name = n
+			values = append(values, v...)
+		}
+		if len(name) == 0 {
+			return errors.NotSupportedf("unable to represent an empty Or filter")
+		}
+		for _, v := range q[name] {
+			if !strings.HasPrefix(v, negatedFilterPrefix) {
+				return errors.NotSupportedf("unable to represent multiple %q filters", name)
+			}
+		}
+		for _, v := range values {
+			q.Add(name, v)
+		}
+		return nil
+	case notFilter:
+		return appendFilterQuery(q, ff.f, !negated)
+	}
+	return errors.NotSupportedf("unable to represent %T filter", f)
+}
+
+// FilterFromQuery returns the filter represented by the q URL query parameters, as generated by FilterQuery.
+// The parameters which don't represent filters, like the ones of a page Cursor, are ignored.
+func FilterFromQuery(q url.Values) (Filter, error) {
+	filters := make(andFilter, 0)
+	for _, name := range []string{typeFilterParam, actorFilterParam, sinceFilterParam, beforeFilterParam, tagFilterParam, inReplyToFilterParam} {
+		var positive, negative []string
+		for _, v := range q[name] {
+			if strings.HasPrefix(v, negatedFilterPrefix) {
+				negative = append(negative, strings.TrimPrefix(v, negatedFilterPrefix))
+			} else {
+				positive = append(positive, v)
+			}
+		}
+		for i, values := range [][]string{positive, negative} {
+			if len(values) == 0 {
+				continue
+			}
+			f, err := filterFromValues(name, values)
+			if err != nil {
+				return nil, err
+			}
+			if i == 1 {
+				f = Not(f)
+			}
+			filters = append(filters, f)
+		}
+	}
+	if len(filters) == 1 {
+		return filters[0], nil
+	}
+	return filters, nil
+}
+
+// filterFromValues returns the filter represented by the values of the name query parameter
+func filterFromValues(name string, values []string) (Filter, error) {
+	toIRIs := func() IRIs {
+		iris := make(IRIs, 0, len(values))
+		for _, v := range values {
+			iris = append(iris, IRI(v))
+		}
+		return iris
+	}
+	toTime := func() (time.Time, error) {
+		if len(values) > 1 {
+			return time.Time{}, errors.NotValidf("multiple %s values", name)
+		}
+		t, err := time.Parse(time.RFC3339, values[0])
+		if err != nil {
+			return t, errors.NewNotValid(err, "invalid %s value %q", name, values[0])
+		}
+		return t, nil
+	}
+
+	switch name {
+	case typeFilterParam:
+		types := make(ActivityVocabularyTypes, 0, len(values))
+		for _, v := range values {
+			types = append(types, ActivityVocabularyType(v))
+		}
+		return ByType(types...), nil
+	case actorFilterParam:
+		return ByActor(toIRIs()...), nil
+	case inReplyToFilterParam:
+		return InReplyTo(toIRIs()...), nil
+	case sinceFilterParam:
+		t, err := toTime()
+		return Since(t), err
+	case beforeFilterParam:
+		t, err := toTime()
+		return Before(t), err
+	case tagFilterParam:
+		return HasTag(values...), nil
+	}
+	return nil, errors.NotSupportedf("unknown filter %q", name)
+}
@@ -0,0 +1,127 @@
+package activitypub
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-ap/errors"
+)
+
+func TestFilters(t *testing.T) {
+	alice := IRI("https://example.com/~alice")
+	bob := IRI("https://example.com/~bob")
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+
+	note := &Object{
+		ID:           "https://example.com/notes/1",
+		Type:         NoteType,
+		AttributedTo: alice,
+		Published:    day(1),
+		Tag:          ItemCollection{&Link{Type: HashtagType, Name: DefaultNaturalLanguageValue("#GoLang"), Href: "https://example.com/tags/golang"}},
+	}
+	reply := &Object{ID: "https://example.com/notes/2", Type: NoteType, AttributedTo: bob, Published: day(2), InReplyTo: note.ID}
+	like := &Activity{ID: "https://example.com/activities/1", Type: LikeType, Actor: bob, Object: note.ID, Published: day(3)}
+	article := &Object{ID: "https://example.com/articles/1", Type: ArticleType, AttributedTo: alice, Updated: day(4), Published: day(1)}
+	col := ItemCollection{note, reply, like, article, IRI("https://example.com/notes/3")}
+
+	tests := []struct {
+		name string
+		f    Filter
+		want IRIs
+	}{
+		{name: "nil", f: nil, want: IRIs{note.ID, reply.ID, like.ID, article.ID, "https://example.com/notes/3"}},
+		{name: "by type", f: ByType(NoteType, ArticleType), want: IRIs{note.ID, reply.ID, article.ID}},
+		{name: "by actor", f: ByActor(bob), want: IRIs{reply.ID, like.ID}},
+		{name: "since", f: Since(day(2)), want: IRIs{reply.ID, like.ID, article.ID}},
+		{name: "before", f: Before(day(2)), want: IRIs{note.ID}},
+		{name: "has tag by name", f: HasTag("golang"), want: IRIs{note.ID}},
+		{name: "has tag by IRI", f: HasTag("https://example.com/tags/golang"), want: IRIs{note.ID}},
+		{name: "in reply to", f: InReplyTo(note.GetLink()), want: IRIs{reply.ID}},
+		{name: "and", f: And(ByActor(alice), Since(day(2))), want: IRIs{article.ID}},
+		{name: "or", f: Or(ByType(LikeType), HasTag("#golang")), want: IRIs{note.ID, like.ID}},
+		{name: "not", f: And(ByType(NoteType), Not(ByActor(alice))), want: IRIs{reply.ID}},
+		{name: "empty and", f: And(), want: IRIs{note.ID, reply.ID, like.ID, article.ID, "https://example.com/notes/3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got IRIs
+			for _, it := range col.Filter(tt.f) {
+				got = append(got, it.GetLink())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Filter() = %v, want %v", got, tt.want)
+			}
+			viaCol := FilterItems(&OrderedCollection{OrderedItems: col}, tt.f)
+			if len(viaCol) != len(tt.want) {
+				t.Errorf("FilterItems() = %d items, want %d", len(viaCol), len(tt.want))
+			}
+		})
+	}
+}
+
+func TestFilterQuery(t *testing.T) {
+	alice := IRI("https://example.com/~alice")
+	since := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		f       Filter
+		want    string
+		wantErr bool
+	}{
+		{name: "by type", f: ByType(NoteType, ArticleType), want: "type=Note&type=Article"},
+		{name: "and", f: And(ByActor(alice), Since(since), HasTag("go")), want: "actor=https%3A%2F%2Fexample.com%2F~alice&since=2024-01-02T03%3A04%3A05Z&tag=go"},
+		{name: "not", f: And(ByType(NoteType), Not(ByType(ArticleType, PageType))), want: "type=Note&type=%21Article&type=%21Page"},
+		{name: "or of the same kind", f: Or(InReplyTo("https://example.com/notes/1"), InReplyTo("https://example.com/notes/2")), want: "inReplyTo=https%3A%2F%2Fexample.com%2Fnotes%2F1&inReplyTo=https%3A%2F%2Fexample.com%2Fnotes%2F2"},
+		{name: "before", f: Before(since), want: "before=2024-01-02T03%3A04%3A05Z"},
+		{name: "or of different kinds", f: Or(ByType(NoteType), ByActor(alice)), wantErr: true},
+		{name: "and of the same kind", f: And(ByType(NoteType), ByType(ArticleType)), wantErr: true},
+		{name: "negated and", f: Not(And(ByType(NoteType), ByActor(alice))), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := FilterQuery(tt.f)
+			if tt.wantErr {
+				if !errors.IsNotSupported(err) {
+					t.Errorf("FilterQuery() error = %v, want NotSupported", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FilterQuery() error = %v", err)
+			}
+			if got := q.Encode(); got != tt.want {
+				want, _ := url.ParseQuery(tt.want)
+				if !reflect.DeepEqual(q, want) {
+					t.Errorf("FilterQuery() = %s, want %s", got, tt.want)
+				}
+			}
+
+			parsed, err := FilterFromQuery(q)
+			if err != nil {
+				t.Fatalf("FilterFromQuery() error = %v", err)
+			}
+			again, err := FilterQuery(parsed)
+			if err != nil {
+				t.Fatalf("FilterQuery() of the parsed filter error = %v", err)
+			}
+			if !reflect.DeepEqual(again, q) {
+				t.Errorf("FilterFromQuery() round trip = %v, want %v", again, q)
+			}
+		})
+	}
+
+	q, _ := url.ParseQuery("type=Note&offset=10&count=5")
+	f, err := FilterFromQuery(q)
+	if err != nil {
+		t.Fatalf("FilterFromQuery() error = %v", err)
+	}
+	if !reflect.DeepEqual(f, ByType(NoteType)) {
+		t.Errorf("FilterFromQuery() = %#v, want the pagination parameters ignored", f)
+	}
+	if _, err = FilterFromQuery(url.Values{"since": {"yesterday"}}); !errors.IsNotValid(err) {
+		t.Errorf("FilterFromQuery() of an invalid time error = %v, want NotValid", err)
+	}
+}
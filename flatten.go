@@ -73,6 +73,10 @@ func FlattenActorProperties(a *Actor) *Actor {
 		FlattenObjectProperties(o)
 		return nil
 	})
+	a.Featured = FlattenToIRI(a.Featured)
+	a.FeaturedTags = FlattenToIRI(a.FeaturedTags)
+	a.AlsoKnownAs = FlattenItemCollection(a.AlsoKnownAs)
+	a.MovedTo = FlattenToIRI(a.MovedTo)
 	return a
 }
 
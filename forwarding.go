@@ -0,0 +1,165 @@
+package activitypub
+
+import "github.com/go-ap/errors"
+
+// DefaultForwardingDepth is the depth of the search for local objects used when Forwarder.MaxDepth is not set
+const DefaultForwardingDepth = 3
+
+// Forwarder decides if an activity received in an inbox needs to be forwarded by the server,
+// to avoid the "ghost replies" problem.
+//
+// https://www.w3.org/TR/activitypub/#inbox-forwarding
+//
+// An activity is forwarded if all the following are true:
+//
+// - this is the first time the server has seen it, ie. it's not in the Store,
+// - its to, bto, cc, bcc or audience properties contain a collection owned by the server,
+// - its inReplyTo, object, target, context or tag properties reference objects owned by the server.
+// The objects referenced by these properties are searched in turn, up to MaxDepth levels,
+// with the search using only the items available in the Store.
+type Forwarder struct {
+	Store Store
+	// IsLocal returns true if the iri identifies an object or collection owned by the server
+	IsLocal func(iri IRI) bool
+	// MaxDepth limits the search for local objects, when zero DefaultForwardingDepth is used
+	MaxDepth int
+}
+
+// ForwarderNew initializes a Forwarder which considers local the IRIs for which the isLocal function returns true
+func ForwarderNew(s Store, isLocal func(IRI) bool) *Forwarder {
+	return &Forwarder{Store: s, IsLocal: isLocal}
+}
+
+// Targets returns the local collections the "it" activity needs to be forwarded to, or nil if it
+// must not be forwarded.
+//
+// It needs to be called before the activity gets processed, and saved to the Store.
+func (f Forwarder) Targets(it Item) (IRIs, error) {
+	if f.Store == nil || f.IsLocal == nil {
+		return nil, errors.Newf("invalid forwarder, it needs a store and a locality check")
+	}
+	if IsNil(it) {
+		return nil, errors.NotValidf("nil activity")
+	}
+	typ := it.GetType()
+	if !ActivityTypes.Contains(typ) && !IntransitiveActivityTypes.Contains(typ) {
+		return nil, errors.NotValidf("%T[%s] is not an activity", it, typ)
+	}
+	if id := it.GetLink(); len(id) > 0 {
+		if _, err := f.Store.Load(id); err == nil {
+			// we've seen this activity before
+			return nil, nil
+		}
+	}
+
+	var targets IRIs
+	_ = OnObject(it, func(o *Object) error {
+		for _, aud := range []ItemCollection{o.To, o.Bto, o.CC, o.BCC, o.Audience} {
+			for _, rec := range aud {
+				if IsNil(rec) {
+					continue
+				}
+				if iri := rec.GetLink(); f.isLocalCollection(iri) && !targets.Contains(iri) {
+					targets = append(targets, iri)
+				}
+			}
+		}
+		return nil
+	})
+	if len(targets) == 0 {
+		return nil, nil
+	}
+
+	depth := f.MaxDepth
+	if depth <= 0 {
+		depth = DefaultForwardingDepth
+	}
+	if !f.referencesLocal(it, depth, make(map[IRI]struct{})) {
+		return nil, nil
+	}
+	return targets, nil
+}
+
+// isLocalCollection returns true if the iri identifies a collection owned by the server
+func (f Forwarder) isLocalCollection(iri IRI) bool {
+	if isPublicCollection(iri) || !f.IsLocal(iri) {
+		return false
+	}
+	if col, err := f.Store.Load(iri); err == nil {
+		return col.IsCollection()
+	}
+	_, path := Split(iri)
+	return path != Unknown
+}
+
+// referencesLocal returns true if the inReplyTo, object, target, context or tag properties of the "it"
+// item reference a local object, searching the referenced items up to depth levels.
+func (f Forwarder) referencesLocal(it Item, depth int, seen map[IRI]struct{}) bool {
+	if depth <= 0 || IsNil(it) || it.IsLink() {
+		return false
+	}
+	if iri := it.GetLink(); len(iri) > 0 {
+		if _, ok := seen[iri]; ok {
+			return false
+		}
+		seen[iri] = struct{}{}
+	}
+
+	var refs ItemCollection
+	_ = OnObject(it, func(o *Object) error {
+		refs = append(refs, objectsOf(o.InReplyTo)...)
+		refs = append(refs, objectsOf(o.Context)...)
+		refs = append(refs, o.Tag...)
+		return nil
+	})
+	typ := it.GetType()
+	if ActivityTypes.Contains(typ) || IntransitiveActivityTypes.Contains(typ) {
+		_ = OnIntransitiveActivity(it, func(act *IntransitiveActivity) error {
+			refs = append(refs, objectsOf(act.Target)...)
+			return nil
+		})
+	}
+	if ActivityTypes.Contains(typ) {
+		_ = OnActivity(it, func(act *Activity) error {
+			refs = append(refs, objectsOf(act.Object)...)
+			return nil
+		})
+	}
+
+	for _, ref := range refs {
+		if IsNil(ref) {
+			continue
+		}
+		if iri := referencedIRI(ref); len(iri) > 0 && !isPublicCollection(iri) && f.IsLocal(iri) {
+			return true
+		}
+	}
+	for _, ref := range refs {
+		if IsNil(ref) {
+			continue
+		}
+		if ref.IsLink() {
+			loaded, err := f.Store.Load(referencedIRI(ref))
+			if err != nil {
+				continue
+			}
+			ref = loaded
+		}
+		if f.referencesLocal(ref, depth-1, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// referencedIRI returns the IRI "it" references, which is the href of Links, like Mentions
+func referencedIRI(it Item) IRI {
+	iri := it.GetLink()
+	if LinkTypes.Contains(it.GetType()) {
+		_ = OnLink(it, func(l *Link) error {
+			iri = l.Href
+			return nil
+		})
+	}
+	return iri
+}
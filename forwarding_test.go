@@ -0,0 +1,121 @@
+package activitypub
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestForwarder_Targets(t *testing.T) {
+	isLocal := func(iri IRI) bool {
+		return strings.HasPrefix(iri.String(), "https://local.example/")
+	}
+	alice := PersonNew("https://local.example/~alice")
+	followers := Followers.IRI(alice)
+	localNote := &Object{ID: "https://local.example/~alice/notes/1", Type: NoteType, AttributedTo: alice.GetLink()}
+	// a chain of remote replies to the local note, known from previous deliveries
+	remote1 := &Object{ID: "https://remote.example/notes/1", Type: NoteType, InReplyTo: localNote.GetLink()}
+	remote2 := &Object{ID: "https://remote.example/notes/2", Type: NoteType, InReplyTo: remote1.GetLink()}
+	remote3 := &Object{ID: "https://remote.example/notes/3", Type: NoteType, InReplyTo: remote2.GetLink()}
+	// a cycle of remote replies
+	loop1 := &Object{ID: "https://remote.example/notes/loop1", Type: NoteType, InReplyTo: IRI("https://remote.example/notes/loop2")}
+	loop2 := &Object{ID: "https://remote.example/notes/loop2", Type: NoteType, InReplyTo: loop1.GetLink()}
+	seen := &Activity{ID: "https://remote.example/activities/seen", Type: CreateType}
+
+	s := MemoryStoreNew(alice, localNote, remote1, remote2, remote3, loop1, loop2, seen)
+
+	reply := func(id IRI, inReplyTo Item, to ...Item) *Activity {
+		return &Activity{
+			ID:     id,
+			Type:   CreateType,
+			Actor:  IRI("https://remote.example/~bob"),
+			To:     to,
+			Object: &Object{ID: id.AddPath("object"), Type: NoteType, InReplyTo: inReplyTo},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		maxDepth int
+		it       Item
+		want     IRIs
+		wantErr  bool
+	}{
+		{
+			name:    "not an activity",
+			it:      localNote,
+			wantErr: true,
+		},
+		{
+			name: "reply to local note addressed to local followers",
+			it:   reply("https://remote.example/activities/1", localNote.GetLink(), PublicNS, followers),
+			want: IRIs{followers},
+		},
+		{
+			name: "reply with the ID of a stored object and a fragment",
+			it:   reply("https://remote.example/notes/1#create", localNote.GetLink(), followers),
+			want: IRIs{followers},
+		},
+		{
+			name: "reply to local note not addressed to a local collection",
+			it:   reply("https://remote.example/activities/2", localNote.GetLink(), PublicNS, alice.GetLink()),
+		},
+		{
+			name: "reply to remote note addressed to local followers",
+			it:   reply("https://remote.example/activities/3", IRI("https://remote.example/notes/unknown"), followers),
+		},
+		{
+			name: "already seen",
+			it: &Activity{
+				ID:     seen.ID,
+				Type:   CreateType,
+				To:     ItemCollection{followers},
+				Object: &Object{Type: NoteType, InReplyTo: localNote.GetLink()},
+			},
+		},
+		{
+			name: "local mention",
+			it: &Activity{
+				ID:     "https://remote.example/activities/4",
+				Type:   CreateType,
+				CC:     ItemCollection{followers},
+				Object: &Object{Type: NoteType, Tag: ItemCollection{&Mention{Type: MentionType, Href: alice.GetLink()}}},
+			},
+			want: IRIs{followers},
+		},
+		{
+			name: "reply chain within the depth limit",
+			it:   reply("https://remote.example/activities/5", remote1.GetLink(), followers),
+			want: IRIs{followers},
+		},
+		{
+			name:     "reply chain over the depth limit",
+			maxDepth: 3,
+			it:       reply("https://remote.example/activities/6", remote3.GetLink(), followers),
+		},
+		{
+			name:     "reply chain with larger depth limit",
+			maxDepth: 5,
+			it:       reply("https://remote.example/activities/7", remote3.GetLink(), followers),
+			want:     IRIs{followers},
+		},
+		{
+			name:     "reply cycle",
+			maxDepth: 100,
+			it:       reply("https://remote.example/activities/8", loop1.GetLink(), followers),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := ForwarderNew(s, isLocal)
+			f.MaxDepth = tt.maxDepth
+			got, err := f.Targets(tt.it)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Targets() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Targets() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
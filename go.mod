@@ -1,6 +1,6 @@
 module github.com/snoymy/activitypub
 
-go 1.18
+go 1.23
 
 require (
 	git.sr.ht/~mariusor/go-xsd-duration v0.0.0-20220703122237-02e73435a078
@@ -488,7 +488,14 @@ func notEmptyActor(a *Actor) bool {
 		a.PreferredUsername != nil ||
 		a.Endpoints != nil ||
 		a.Streams != nil ||
-		len(a.PublicKey.ID)+len(a.PublicKey.Owner)+len(a.PublicKey.PublicKeyPem) > 0
+		len(a.PublicKey.ID)+len(a.PublicKey.Owner)+len(a.PublicKey.PublicKeyPem) > 0 ||
+		a.ManuallyApprovesFollowers ||
+		a.Discoverable ||
+		a.Indexable ||
+		a.Featured != nil ||
+		a.FeaturedTags != nil ||
+		a.AlsoKnownAs != nil ||
+		a.MovedTo != nil
 }
 
 // NotEmpty tells us if a Item interface value has a non nil value for various types
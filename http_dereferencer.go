@@ -0,0 +1,112 @@
+package activitypub
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/go-ap/errors"
+)
+
+const (
+	// ActivityJSONType is the media type of ActivityStreams documents
+	ActivityJSONType = "application/activity+json"
+	// LDJSONType is the alternative media type of ActivityStreams documents
+	LDJSONType = `application/ld+json; profile="https://www.w3.org/ns/activitystreams"`
+)
+
+// maxDocumentSize is the size limit for the documents the HTTPDereferencer fetches
+const maxDocumentSize = 10 << 20
+
+// HTTPClient is the interface of the HTTP client used by the HTTPDereferencer, it is satisfied by *http.Client
+type HTTPClient interface {
+	Do(*http.Request) (*http.Response, error)
+}
+
+// HTTPDereferencer is a Dereferencer which fetches items by making GET requests to their IRIs.
+//
+// https://www.w3.org/TR/activitypub/#retrieving-objects
+type HTTPDereferencer struct {
+	// Client is used for the requests, if nil http.DefaultClient is used
+	Client HTTPClient
+}
+
+var _ Dereferencer = HTTPDereferencer{}
+
+// HTTPDereferencerNew initializes an HTTPDereferencer which uses the received HTTP client
+func HTTPDereferencerNew(c HTTPClient) *HTTPDereferencer {
+	return &HTTPDereferencer{Client: c}
+}
+
+// Load fetches the item identified by the iri
+func (h HTTPDereferencer) Load(iri IRI) (Item, error) {
+	return h.LoadContext(context.Background(), iri)
+}
+
+// LoadContext fetches the item identified by the iri, using ctx for the request
+func (h HTTPDereferencer) LoadContext(ctx context.Context, iri IRI) (Item, error) {
+	it, _, _, err := h.fetch(ctx, iri, validators{})
+	return it, err
+}
+
+// validators hold the ETag and Last-Modified headers of a fetched document, which allow
+// later requests for it to be made conditional.
+type validators struct {
+	etag         string
+	lastModified string
+}
+
+// fetch makes a GET request for the iri, which is conditional if the v validators are not empty.
+// If the server responds that the document has not been modified since, the returned item is nil,
+// and modified is false.
+//
+// Documents larger than maxDocumentSize, or with an ID on a different host than the iri, are rejected.
+func (h HTTPDereferencer) fetch(ctx context.Context, iri IRI, v validators) (it Item, nv validators, modified bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, iri.String(), nil)
+	if err != nil {
+		return nil, v, false, errors.Annotatef(err, "unable to build request for %s", iri)
+	}
+	req.Header.Set("Accept", LDJSONType+", "+ActivityJSONType)
+	if len(v.etag) > 0 {
+		req.Header.Set("If-None-Match", v.etag)
+	}
+	if len(v.lastModified) > 0 {
+		req.Header.Set("If-Modified-Since", v.lastModified)
+	}
+
+	cl := h.Client
+	if cl == nil {
+		cl = http.DefaultClient
+	}
+	res, err := cl.Do(req)
+	if err != nil {
+		return nil, v, false, errors.Annotatef(err, "unable to fetch %s", iri)
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotModified:
+		return nil, v, false, nil
+	default:
+		return nil, v, false, errors.NewFromStatus(res.StatusCode, "unable to fetch %s", iri)
+	}
+	data, err := io.ReadAll(io.LimitReader(res.Body, maxDocumentSize+1))
+	if err != nil {
+		return nil, v, false, errors.Annotatef(err, "unable to read %s", iri)
+	}
+	if len(data) > maxDocumentSize {
+		return nil, v, false, errors.NotValidf("document at %s is too large, the limit is %d bytes", iri, maxDocumentSize)
+	}
+	if it, err = UnmarshalJSON(data); err != nil {
+		return nil, v, false, errors.NewNotValid(err, "invalid document at %s", iri)
+	}
+	if IsNil(it) {
+		return nil, v, false, errors.NotValidf("empty document at %s", iri)
+	}
+	if !sameHost(it.GetLink(), iri) {
+		return nil, v, false, errors.NotValidf("document at %s has the ID %q of another host", iri, it.GetLink())
+	}
+	nv = validators{etag: res.Header.Get("ETag"), lastModified: res.Header.Get("Last-Modified")}
+	return it, nv, true, nil
+}
@@ -0,0 +1,70 @@
+package activitypub
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-ap/errors"
+)
+
+func TestHTTPDereferencer_Load(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Accept"), ActivityJSONType) {
+			w.WriteHeader(http.StatusNotAcceptable)
+			return
+		}
+		switch r.URL.Path {
+		case "/notes/1":
+			w.Header().Set("Content-Type", ActivityJSONType)
+			_, _ = w.Write([]byte(`{"id":"` + "http://" + r.Host + `/notes/1","type":"Note","content":"hello"}`))
+		case "/invalid":
+			_, _ = w.Write([]byte(`{"type":`))
+		case "/impostor":
+			_, _ = w.Write([]byte(`{"id":"https://example.com/~jdoe","type":"Person"}`))
+		case "/anonymous":
+			_, _ = w.Write([]byte(`{"type":"Note","content":"hello"}`))
+		case "/large":
+			_, _ = w.Write([]byte(`{"id":"` + "http://" + r.Host + `/large","type":"Note","content":"`))
+			_, _ = w.Write([]byte(strings.Repeat("a", maxDocumentSize)))
+			_, _ = w.Write([]byte(`"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	h := HTTPDereferencerNew(srv.Client())
+
+	it, err := h.Load(IRI(srv.URL + "/notes/1"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if it.GetType() != NoteType || it.GetLink() != IRI(srv.URL+"/notes/1") {
+		t.Errorf("Load() = %s[%s], want the Note", it.GetType(), it.GetLink())
+	}
+	if _, err = h.Load(IRI(srv.URL + "/notes/2")); !errors.IsNotFound(err) {
+		t.Errorf("Load() error = %v, want a not found error", err)
+	}
+	if _, err = h.Load(IRI(srv.URL + "/invalid")); err == nil {
+		t.Errorf("Load() of an invalid document should fail")
+	}
+	if _, err = h.Load(IRI(srv.URL + "/impostor")); !errors.IsNotValid(err) {
+		t.Errorf("Load() error = %v, want a not valid error for a document with an ID on another host", err)
+	}
+	if _, err = h.Load(IRI(srv.URL + "/anonymous")); !errors.IsNotValid(err) {
+		t.Errorf("Load() error = %v, want a not valid error for a document without an ID", err)
+	}
+	if _, err = h.Load(IRI(srv.URL + "/large")); !errors.IsNotValid(err) || !strings.Contains(err.Error(), "too large") {
+		t.Errorf("Load() error = %v, want a too large error", err)
+	}
+
+	ob := &Object{Type: NoteType, InReplyTo: IRI(srv.URL + "/notes/1")}
+	if _, err = Dereference(ob, h, 1, "inReplyTo"); err != nil {
+		t.Fatalf("Dereference() error = %v", err)
+	}
+	if ob.InReplyTo.IsLink() {
+		t.Errorf("Dereference() inReplyTo = %v, want the fetched Note", ob.InReplyTo)
+	}
+}
@@ -0,0 +1,318 @@
+package httpsig
+
+import (
+	"crypto"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-ap/errors"
+	vocab "github.com/snoymy/activitypub"
+)
+
+const (
+	// RequestTarget is the pseudo-header covering the method and the path of the request
+	RequestTarget = "(request-target)"
+	// Created is the pseudo-header covering the "created" parameter of the signature
+	Created = "(created)"
+	// Expires is the pseudo-header covering the "expires" parameter of the signature
+	Expires = "(expires)"
+)
+
+// DefaultHeaders are the headers covered by a Signer when none are specified.
+// The "digest" header is skipped for requests without a body.
+var DefaultHeaders = []string{RequestTarget, "host", "date", "digest"}
+
+// RequiredHeaders are the headers a signature must cover for Verify to accept it, so that the
+// method, the target and the freshness of the request are authenticated.
+// The "digest" header is also required for requests with a body.
+var RequiredHeaders = []string{RequestTarget, "host", "date"}
+
+// Signer signs HTTP requests using the draft-cavage HTTP Signatures scheme
+type Signer struct {
+	// KeyID is the IRI of the actor's public key, usually the ID of its PublicKey
+	KeyID vocab.IRI
+	// Key is the private key, it must be an *rsa.PrivateKey or an ed25519.PrivateKey
+	Key crypto.PrivateKey
+	// Algorithm is the name of the algorithm sent in the Signature header,
+	// if empty it defaults to rsa-sha256 for RSA keys and hs2019 for Ed25519 keys.
+	Algorithm string
+	// Headers is the list of headers covered by the signature, if empty DefaultHeaders is used
+	Headers []string
+}
+
+// NewSigner initializes a Signer for the key identified by keyID, covering the received headers.
+func NewSigner(keyID vocab.IRI, key crypto.PrivateKey, headers ...string) (*Signer, error) {
+	alg, err := algorithmForKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(headers) == 0 {
+		headers = DefaultHeaders
+	}
+	return &Signer{KeyID: keyID, Key: key, Algorithm: alg, Headers: headers}, nil
+}
+
+// Sign adds the Signature header to the request.
+// It sets the Date and the Digest headers on the request if they are covered and missing.
+func (s Signer) Sign(r *http.Request) error {
+	alg := s.Algorithm
+	if len(alg) == 0 {
+		var err error
+		if alg, err = algorithmForKey(s.Key); err != nil {
+			return err
+		}
+	}
+	headers := s.Headers
+	if len(headers) == 0 {
+		headers = DefaultHeaders
+	}
+
+	body, err := readBody(r)
+	if err != nil {
+		return err
+	}
+
+	sig := Signature{KeyID: s.KeyID, Algorithm: alg}
+	for _, h := range headers {
+		h = strings.ToLower(h)
+		switch h {
+		case "date":
+			if len(r.Header.Get("Date")) == 0 {
+				r.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+			}
+		case "digest":
+			if body == nil {
+				// there's no point in covering the digest of an empty body
+				continue
+			}
+			if len(r.Header.Get("Digest")) == 0 {
+				r.Header.Set("Digest", Digest(body))
+			}
+		case Created:
+			sig.Created = time.Now().UTC().Unix()
+		}
+		sig.Headers = append(sig.Headers, h)
+	}
+
+	toSign, err := sig.SigningString(r)
+	if err != nil {
+		return err
+	}
+	if sig.Signature, err = sign(s.Key, alg, []byte(toSign)); err != nil {
+		return err
+	}
+	r.Header.Set("Signature", sig.String())
+	return nil
+}
+
+// Signature represents the parameters of a draft-cavage Signature header
+type Signature struct {
+	KeyID     vocab.IRI
+	Algorithm string
+	Headers   []string
+	Created   int64
+	Expires   int64
+	Signature []byte
+}
+
+// ParseSignature parses the value of a Signature header, or the parameters of
+// an Authorization header using the "Signature" scheme.
+func ParseSignature(header string) (*Signature, error) {
+	header = strings.TrimSpace(header)
+	if scheme, params, ok := strings.Cut(header, " "); ok && strings.EqualFold(scheme, "Signature") {
+		header = params
+	}
+
+	sig := Signature{}
+	for len(header) > 0 {
+		var name, value string
+		name, header, _ = strings.Cut(header, "=")
+		name = strings.ToLower(strings.Trim(name, " ,"))
+		if strings.HasPrefix(header, `"`) {
+			end := strings.Index(header[1:], `"`)
+			if end < 0 {
+				return nil, errors.NotValidf("unterminated value for Signature parameter %q", name)
+			}
+			value = header[1 : end+1]
+			header = header[end+2:]
+		} else {
+			value, header, _ = strings.Cut(header, ",")
+		}
+		header = strings.TrimLeft(header, " ,")
+
+		var err error
+		switch name {
+		case "keyid":
+			sig.KeyID = vocab.IRI(value)
+		case "algorithm":
+			sig.Algorithm = strings.ToLower(value)
+		case "headers":
+			sig.Headers = strings.Fields(strings.ToLower(value))
+		case "created":
+			sig.Created, err = strconv.ParseInt(value, 10, 64)
+		case "expires":
+			sig.Expires, err = strconv.ParseInt(value, 10, 64)
+		case "signature":
+			sig.Signature, err = base64.StdEncoding.DecodeString(value)
+		}
+		if err != nil {
+			return nil, errors.NewNotValid(err, "invalid value for Signature parameter %q", name)
+		}
+	}
+	if len(sig.KeyID) == 0 {
+		return nil, errors.NotValidf("missing keyId in Signature header")
+	}
+	if len(sig.Signature) == 0 {
+		return nil, errors.NotValidf("missing signature in Signature header")
+	}
+	if len(sig.Headers) == 0 {
+		// the spec says that when the "headers" parameter is missing
+		// only the Date header is covered
+		sig.Headers = []string{"date"}
+	}
+	return &sig, nil
+}
+
+// SignatureFromRequest loads the Signature of the request from its Signature or Authorization headers
+func SignatureFromRequest(r *http.Request) (*Signature, error) {
+	if header := r.Header.Get("Signature"); len(header) > 0 {
+		return ParseSignature(header)
+	}
+	if header := r.Header.Get("Authorization"); len(header) > 0 {
+		if scheme, _, _ := strings.Cut(header, " "); strings.EqualFold(scheme, "Signature") {
+			return ParseSignature(header)
+		}
+	}
+	return nil, errors.Unauthorizedf("missing Signature header")
+}
+
+// String returns the value of the Signature header
+func (s Signature) String() string {
+	b := strings.Builder{}
+	fmt.Fprintf(&b, `keyId="%s",algorithm="%s"`, s.KeyID, s.Algorithm)
+	if s.Created > 0 {
+		fmt.Fprintf(&b, `,created=%d`, s.Created)
+	}
+	if s.Expires > 0 {
+		fmt.Fprintf(&b, `,expires=%d`, s.Expires)
+	}
+	fmt.Fprintf(&b, `,headers="%s"`, strings.Join(s.Headers, " "))
+	fmt.Fprintf(&b, `,signature="%s"`, base64.StdEncoding.EncodeToString(s.Signature))
+	return b.String()
+}
+
+// SigningString builds the string that gets signed from the headers of the request covered by s
+func (s Signature) SigningString(r *http.Request) (string, error) {
+	lines := make([]string, 0, len(s.Headers))
+	for _, h := range s.Headers {
+		var val string
+		switch h {
+		case RequestTarget:
+			val = strings.ToLower(r.Method) + " " + r.URL.RequestURI()
+		case Created:
+			if s.Created == 0 {
+				return "", errors.NotValidf("missing created parameter covered by the signature")
+			}
+			val = strconv.FormatInt(s.Created, 10)
+		case Expires:
+			if s.Expires == 0 {
+				return "", errors.NotValidf("missing expires parameter covered by the signature")
+			}
+			val = strconv.FormatInt(s.Expires, 10)
+		case "host":
+			val = requestHost(r)
+		default:
+			values := r.Header.Values(h)
+			if len(values) == 0 {
+				return "", errors.NotValidf("missing header %q covered by the signature", h)
+			}
+			val = strings.Join(values, ", ")
+		}
+		lines = append(lines, h+": "+strings.TrimSpace(val))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func (s Signature) covers(header string) bool {
+	for _, h := range s.Headers {
+		if h == header {
+			return true
+		}
+	}
+	return false
+}
+
+// Verify checks the Signature header of the request against the actor's public key.
+// The signature must cover the RequiredHeaders, and the Digest header for requests with a body,
+// which is checked against the body. The Date header must be within MaxClockSkew of the current time.
+func Verify(r *http.Request, key vocab.PublicKey) error {
+	sig, err := SignatureFromRequest(r)
+	if err != nil {
+		return err
+	}
+	return sig.Verify(r, key)
+}
+
+// Verify checks the signature against the request and the actor's public key.
+func (s Signature) Verify(r *http.Request, key vocab.PublicKey) error {
+	if len(key.ID) > 0 && s.KeyID != key.ID {
+		return errors.Unauthorizedf("signature key %s does not match public key %s", s.KeyID, key.ID)
+	}
+	pub, err := PublicKeyFrom(key)
+	if err != nil {
+		return err
+	}
+	if s.Expires > 0 && time.Now().UTC().Unix() > s.Expires {
+		return errors.Unauthorizedf("signature has expired")
+	}
+	for _, h := range RequiredHeaders {
+		if !s.covers(h) {
+			return errors.Unauthorizedf("signature does not cover the required %q header", h)
+		}
+	}
+	body, err := readBody(r)
+	if err != nil {
+		return err
+	}
+	if len(body) > 0 && !s.covers("digest") {
+		return errors.Unauthorizedf("signature does not cover the digest of the request body")
+	}
+	if s.covers("date") {
+		date, err := http.ParseTime(r.Header.Get("Date"))
+		if err != nil {
+			return errors.NewUnauthorized(err, "invalid Date header %q", r.Header.Get("Date"))
+		}
+		if err = checkClockSkew(date, "request date"); err != nil {
+			return err
+		}
+	}
+	if s.Created > 0 {
+		if err = checkClockSkew(time.Unix(s.Created, 0), "signature creation time"); err != nil {
+			return err
+		}
+	}
+	if s.covers("digest") {
+		if err = VerifyDigest(r); err != nil {
+			return err
+		}
+	}
+	toVerify, err := s.SigningString(r)
+	if err != nil {
+		return err
+	}
+	return verify(pub, s.Algorithm, []byte(toVerify), s.Signature)
+}
+
+func requestHost(r *http.Request) string {
+	if len(r.Host) > 0 {
+		return r.Host
+	}
+	if h := r.Header.Get("Host"); len(h) > 0 {
+		return h
+	}
+	return r.URL.Host
+}
@@ -0,0 +1,303 @@
+package httpsig
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	vocab "github.com/snoymy/activitypub"
+)
+
+func publicKeyPEM(t *testing.T, pub crypto.PublicKey) string {
+	t.Helper()
+	raw, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatalf("unable to marshal public key: %s", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: raw}))
+}
+
+type testKey struct {
+	name string
+	priv crypto.PrivateKey
+	pub  vocab.PublicKey
+}
+
+func testKeys(t *testing.T) []testKey {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("unable to generate RSA key: %s", err)
+	}
+	edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate Ed25519 key: %s", err)
+	}
+	return []testKey{
+		{
+			name: "rsa",
+			priv: rsaKey,
+			pub: vocab.PublicKey{
+				ID:           "https://example.com/~jdoe#main-key",
+				Owner:        "https://example.com/~jdoe",
+				PublicKeyPem: publicKeyPEM(t, rsaKey.Public()),
+			},
+		},
+		{
+			name: "ed25519",
+			priv: edKey,
+			pub: vocab.PublicKey{
+				ID:           "https://example.com/~jdoe#ed25519-key",
+				Owner:        "https://example.com/~jdoe",
+				PublicKeyPem: publicKeyPEM(t, edPub),
+			},
+		},
+	}
+}
+
+func TestSigner_Sign(t *testing.T) {
+	for _, key := range testKeys(t) {
+		t.Run(key.name, func(t *testing.T) {
+			var verifyErr error
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				verifyErr = Verify(r, key.pub)
+			}))
+			defer srv.Close()
+
+			s, err := NewSigner(vocab.IRI(key.pub.ID), key.priv)
+			if err != nil {
+				t.Fatalf("NewSigner() error = %s", err)
+			}
+
+			body := []byte(`{"type":"Follow","actor":"https://example.com/~jdoe","object":"https://example.org/~alice"}`)
+			req, _ := http.NewRequest(http.MethodPost, srv.URL+"/inbox?page=1", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/activity+json")
+			if err = s.Sign(req); err != nil {
+				t.Fatalf("Sign() error = %s", err)
+			}
+			if got := req.Header.Get("Digest"); got != Digest(body) {
+				t.Errorf("Sign() digest = %s, want %s", got, Digest(body))
+			}
+			if _, err = srv.Client().Do(req); err != nil {
+				t.Fatalf("unable to send request: %s", err)
+			}
+			if verifyErr != nil {
+				t.Errorf("Verify() error = %s", verifyErr)
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	keys := testKeys(t)
+	rsaKey, edKey := keys[0], keys[1]
+
+	signed := func(key testKey, method, body string) *http.Request {
+		var req *http.Request
+		if len(body) > 0 {
+			req = httptest.NewRequest(method, "https://example.org/inbox", strings.NewReader(body))
+		} else {
+			req = httptest.NewRequest(method, "https://example.org/~alice", nil)
+		}
+		s, _ := NewSigner(vocab.IRI(key.pub.ID), key.priv)
+		if err := s.Sign(req); err != nil {
+			t.Fatalf("Sign() error = %s", err)
+		}
+		return req
+	}
+	signedWith := func(key testKey, body string, date time.Time, headers ...string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "https://example.org/inbox", strings.NewReader(body))
+		req.Header.Set("Date", date.UTC().Format(http.TimeFormat))
+		s, _ := NewSigner(vocab.IRI(key.pub.ID), key.priv, headers...)
+		if err := s.Sign(req); err != nil {
+			t.Fatalf("Sign() error = %s", err)
+		}
+		return req
+	}
+
+	tests := []struct {
+		name    string
+		req     func() *http.Request
+		key     vocab.PublicKey
+		wantErr bool
+	}{
+		{
+			name: "rsa GET",
+			req:  func() *http.Request { return signed(rsaKey, http.MethodGet, "") },
+			key:  rsaKey.pub,
+		},
+		{
+			name: "ed25519 POST",
+			req:  func() *http.Request { return signed(edKey, http.MethodPost, `{"type":"Note"}`) },
+			key:  edKey.pub,
+		},
+		{
+			name:    "missing signature",
+			req:     func() *http.Request { return httptest.NewRequest(http.MethodGet, "https://example.org/~alice", nil) },
+			key:     rsaKey.pub,
+			wantErr: true,
+		},
+		{
+			name:    "wrong key",
+			req:     func() *http.Request { return signed(rsaKey, http.MethodGet, "") },
+			key:     vocab.PublicKey{ID: rsaKey.pub.ID, PublicKeyPem: edKey.pub.PublicKeyPem},
+			wantErr: true,
+		},
+		{
+			name:    "key ID mismatch",
+			req:     func() *http.Request { return signed(rsaKey, http.MethodGet, "") },
+			key:     vocab.PublicKey{ID: "https://example.com/~jdoe#other-key", PublicKeyPem: rsaKey.pub.PublicKeyPem},
+			wantErr: true,
+		},
+		{
+			name: "tampered path",
+			req: func() *http.Request {
+				req := signed(rsaKey, http.MethodGet, "")
+				req.URL.Path = "/~bob"
+				return req
+			},
+			key:     rsaKey.pub,
+			wantErr: true,
+		},
+		{
+			name: "tampered body",
+			req: func() *http.Request {
+				req := signed(edKey, http.MethodPost, `{"type":"Note"}`)
+				req.Body = io.NopCloser(strings.NewReader(`{"type":"Article"}`))
+				return req
+			},
+			key:     edKey.pub,
+			wantErr: true,
+		},
+		{
+			name: "date only signature",
+			req: func() *http.Request {
+				return signedWith(rsaKey, `{"type":"Note"}`, time.Now(), "date")
+			},
+			key:     rsaKey.pub,
+			wantErr: true,
+		},
+		{
+			name: "missing headers parameter",
+			req: func() *http.Request {
+				req := signedWith(rsaKey, "", time.Now(), "date")
+				req.Header.Set("Signature", strings.Replace(req.Header.Get("Signature"), `,headers="date"`, "", 1))
+				return req
+			},
+			key:     rsaKey.pub,
+			wantErr: true,
+		},
+		{
+			name: "body without digest",
+			req: func() *http.Request {
+				return signedWith(rsaKey, `{"type":"Note"}`, time.Now(), RequestTarget, "host", "date")
+			},
+			key:     rsaKey.pub,
+			wantErr: true,
+		},
+		{
+			name: "stale date",
+			req: func() *http.Request {
+				return signedWith(edKey, `{"type":"Note"}`, time.Now().Add(-MaxClockSkew-time.Hour))
+			},
+			key:     edKey.pub,
+			wantErr: true,
+		},
+		{
+			name: "date in the future",
+			req: func() *http.Request {
+				return signedWith(edKey, `{"type":"Note"}`, time.Now().Add(MaxClockSkew+time.Hour))
+			},
+			key:     edKey.pub,
+			wantErr: true,
+		},
+		{
+			name: "date within the clock skew",
+			req: func() *http.Request {
+				return signedWith(edKey, `{"type":"Note"}`, time.Now().Add(-time.Hour))
+			},
+			key: edKey.pub,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Verify(tt.req(), tt.key); (err != nil) != tt.wantErr {
+				t.Errorf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseSignature(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		want    Signature
+		wantErr bool
+	}{
+		{
+			name:   "mastodon",
+			header: `keyId="https://example.com/users/jdoe#main-key",algorithm="rsa-sha256",headers="(request-target) host date digest content-type",signature="dGVzdA=="`,
+			want: Signature{
+				KeyID:     "https://example.com/users/jdoe#main-key",
+				Algorithm: RSASHA256,
+				Headers:   []string{RequestTarget, "host", "date", "digest", "content-type"},
+				Signature: []byte("test"),
+			},
+		},
+		{
+			name:   "authorization header with created",
+			header: `Signature keyId="https://example.com/~jdoe#key", algorithm="hs2019", created=1402170695, headers="(request-target) (created)", signature="dGVzdA=="`,
+			want: Signature{
+				KeyID:     "https://example.com/~jdoe#key",
+				Algorithm: HS2019,
+				Headers:   []string{RequestTarget, Created},
+				Created:   1402170695,
+				Signature: []byte("test"),
+			},
+		},
+		{
+			name:   "no headers",
+			header: `keyId="https://example.com/~jdoe#key",signature="dGVzdA=="`,
+			want: Signature{
+				KeyID:     "https://example.com/~jdoe#key",
+				Headers:   []string{"date"},
+				Signature: []byte("test"),
+			},
+		},
+		{
+			name:    "missing keyId",
+			header:  `algorithm="rsa-sha256",signature="dGVzdA=="`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid signature",
+			header:  `keyId="https://example.com/~jdoe#key",signature="!!!"`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSignature(tt.header)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSignature() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.String() != tt.want.String() {
+				t.Errorf("ParseSignature() got = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
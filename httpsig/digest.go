@@ -0,0 +1,133 @@
+package httpsig
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-ap/errors"
+)
+
+// Digest returns the value of the Digest header for body, using the SHA-256 algorithm
+//
+// https://datatracker.ietf.org/doc/html/rfc3230
+func Digest(body []byte) string {
+	h := sha256.Sum256(body)
+	return "SHA-256=" + base64.StdEncoding.EncodeToString(h[:])
+}
+
+// VerifyDigest checks that the Digest header of the request matches its body.
+// The SHA-256 and SHA-512 algorithms are supported, any other values are ignored.
+func VerifyDigest(r *http.Request) error {
+	header := r.Header.Get("Digest")
+	if len(header) == 0 {
+		return errors.BadRequestf("missing Digest header")
+	}
+	body, err := readBody(r)
+	if err != nil {
+		return err
+	}
+	supported := false
+	for _, d := range strings.Split(header, ",") {
+		alg, val, ok := strings.Cut(strings.TrimSpace(d), "=")
+		if !ok {
+			continue
+		}
+		var sum []byte
+		switch strings.ToUpper(alg) {
+		case "SHA-256":
+			h := sha256.Sum256(body)
+			sum = h[:]
+		case "SHA-512":
+			h := sha512.Sum512(body)
+			sum = h[:]
+		default:
+			continue
+		}
+		supported = true
+		expected, err := base64.StdEncoding.DecodeString(val)
+		if err != nil {
+			return errors.NewBadRequest(err, "invalid %s Digest value", alg)
+		}
+		if subtle.ConstantTimeCompare(expected, sum) != 1 {
+			return errors.BadRequestf("%s Digest does not match the request body", alg)
+		}
+	}
+	if !supported {
+		return errors.NotSupportedf("no supported algorithm in Digest header %q", header)
+	}
+	return nil
+}
+
+// ContentDigest returns the value of the Content-Digest header for body, using the SHA-256 algorithm
+//
+// https://www.rfc-editor.org/rfc/rfc9530
+func ContentDigest(body []byte) string {
+	h := sha256.Sum256(body)
+	return "sha-256=:" + base64.StdEncoding.EncodeToString(h[:]) + ":"
+}
+
+// VerifyContentDigest checks that the Content-Digest header of the request matches its body.
+// The SHA-256 and SHA-512 algorithms are supported, any other values are ignored.
+func VerifyContentDigest(r *http.Request) error {
+	header := strings.Join(r.Header.Values("Content-Digest"), ",")
+	if len(header) == 0 {
+		return errors.BadRequestf("missing Content-Digest header")
+	}
+	body, err := readBody(r)
+	if err != nil {
+		return err
+	}
+	supported := false
+	for _, d := range parseDictionary(header) {
+		var sum []byte
+		switch strings.ToLower(d.key) {
+		case "sha-256":
+			h := sha256.Sum256(body)
+			sum = h[:]
+		case "sha-512":
+			h := sha512.Sum512(body)
+			sum = h[:]
+		default:
+			continue
+		}
+		supported = true
+		if len(d.value) < 2 || d.value[0] != ':' || d.value[len(d.value)-1] != ':' {
+			return errors.BadRequestf("invalid %s Content-Digest value", d.key)
+		}
+		expected, err := base64.StdEncoding.DecodeString(d.value[1 : len(d.value)-1])
+		if err != nil {
+			return errors.NewBadRequest(err, "invalid %s Content-Digest value", d.key)
+		}
+		if subtle.ConstantTimeCompare(expected, sum) != 1 {
+			return errors.BadRequestf("%s Content-Digest does not match the request body", d.key)
+		}
+	}
+	if !supported {
+		return errors.NotSupportedf("no supported algorithm in Content-Digest header %q", header)
+	}
+	return nil
+}
+
+// readBody returns the contents of the request body, and replaces it with a new reader
+// so it can be consumed again by the request handlers.
+func readBody(r *http.Request) ([]byte, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, errors.Annotatef(err, "unable to read request body")
+	}
+	_ = r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	r.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	return body, nil
+}
@@ -0,0 +1,56 @@
+package httpsig
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestVerifyDigest(t *testing.T) {
+	const body = `{"type":"Note","content":"test"}`
+	tests := []struct {
+		name    string
+		digest  string
+		wantErr bool
+	}{
+		{
+			name:   "SHA-256",
+			digest: Digest([]byte(body)),
+		},
+		{
+			name:   "SHA-512 and unknown",
+			digest: "MD5=bG9s,SHA-512=cwW2UjVMIANMIukzCULrnH5cSAP1Hku0DgKSPNhiAsWkobu7poSTwOXJcMYbR+KUeSGDArIjt7StojhB0hisQA==",
+		},
+		{
+			name:    "missing",
+			wantErr: true,
+		},
+		{
+			name:    "mismatch",
+			digest:  Digest([]byte(`{"type":"Article"}`)),
+			wantErr: true,
+		},
+		{
+			name:    "unsupported",
+			digest:  "MD5=bG9s",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "https://example.com/inbox", strings.NewReader(body))
+			if len(tt.digest) > 0 {
+				req.Header.Set("Digest", tt.digest)
+			}
+			err := VerifyDigest(req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("VerifyDigest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if b, _ := io.ReadAll(req.Body); string(b) != body {
+				t.Errorf("VerifyDigest() did not preserve the request body, got %s", b)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+// Package httpsig implements signing and verification of HTTP requests exchanged between
+// ActivityPub servers, using the keys published in the "publicKey" property of actors.
+//
+// It supports the draft-cavage-http-signatures scheme that is used by most of the current
+// fediverse software, with the "rsa-sha256", "hs2019" and "ed25519" algorithms.
+//
+// https://datatracker.ietf.org/doc/html/draft-cavage-http-signatures-12
+//
+// It also supports the RFC 9421 HTTP Message Signatures scheme, with the "rsa-v1_5-sha256",
+// "rsa-pss-sha512" and "ed25519" algorithms. Servers which don't know yet which of the two
+// schemes the other side supports can use VerifyRequest and the DoubleKnock transport.
+//
+// https://www.rfc-editor.org/rfc/rfc9421
+package httpsig
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"strings"
+	"time"
+
+	"github.com/go-ap/errors"
+	vocab "github.com/snoymy/activitypub"
+)
+
+// MaxClockSkew is the maximum difference between the time a request was signed, as given by its
+// Date header or by the "created" parameter of its signature, and the time it gets verified.
+// Signatures outside this window are rejected, which bounds the time a captured request can be replayed.
+var MaxClockSkew = 12 * time.Hour
+
+const (
+	// RSASHA256 is the algorithm name for RSASSA-PKCS1-v1_5 signatures using SHA-256
+	RSASHA256 = "rsa-sha256"
+	// Ed25519 is the algorithm name for EdDSA signatures using the Ed25519 curve
+	Ed25519 = "ed25519"
+	// HS2019 is the algorithm name which doesn't disclose the algorithm used,
+	// the verifier derives it from the type of the key.
+	HS2019 = "hs2019"
+)
+
+// ParsePublicKeyPEM decodes the PEM encoded public key in the format used by the
+// "publicKeyPem" property of an actor's PublicKey.
+// The resulting key is either an *rsa.PublicKey or an ed25519.PublicKey.
+func ParsePublicKeyPEM(data string) (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(strings.TrimSpace(data)))
+	if block == nil {
+		return nil, errors.NotValidf("unable to decode PEM public key")
+	}
+	var (
+		pub crypto.PublicKey
+		err error
+	)
+	switch block.Type {
+	case "RSA PUBLIC KEY":
+		pub, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		pub, err = x509.ParsePKIXPublicKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, errors.NewNotValid(err, "unable to parse public key")
+	}
+	switch pub.(type) {
+	case *rsa.PublicKey, ed25519.PublicKey:
+		return pub, nil
+	}
+	return nil, errors.NotSupportedf("unsupported public key type %T", pub)
+}
+
+// PublicKeyFrom returns the crypto.PublicKey corresponding to the key published by an actor
+func PublicKeyFrom(key vocab.PublicKey) (crypto.PublicKey, error) {
+	if len(key.PublicKeyPem) == 0 {
+		return nil, errors.NotFoundf("missing public key PEM for %s", key.ID)
+	}
+	return ParsePublicKeyPEM(key.PublicKeyPem)
+}
+
+// algorithmForKey returns the default algorithm name to be used with the private key
+func algorithmForKey(key crypto.PrivateKey) (string, error) {
+	switch key.(type) {
+	case *rsa.PrivateKey:
+		return RSASHA256, nil
+	case ed25519.PrivateKey:
+		return HS2019, nil
+	}
+	return "", errors.NotSupportedf("unsupported private key type %T", key)
+}
+
+func sign(key crypto.PrivateKey, alg string, data []byte) ([]byte, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		if alg != RSASHA256 && alg != HS2019 {
+			return nil, errors.NotSupportedf("algorithm %q can not be used with RSA keys", alg)
+		}
+		h := sha256.Sum256(data)
+		return rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, h[:])
+	case ed25519.PrivateKey:
+		if alg != Ed25519 && alg != HS2019 {
+			return nil, errors.NotSupportedf("algorithm %q can not be used with Ed25519 keys", alg)
+		}
+		return ed25519.Sign(k, data), nil
+	}
+	return nil, errors.NotSupportedf("unsupported private key type %T", key)
+}
+
+func verify(key crypto.PublicKey, alg string, data, sig []byte) error {
+	alg = strings.ToLower(alg)
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		if alg != "" && alg != RSASHA256 && alg != HS2019 {
+			return errors.NotSupportedf("algorithm %q can not be used with RSA keys", alg)
+		}
+		h := sha256.Sum256(data)
+		if err := rsa.VerifyPKCS1v15(k, crypto.SHA256, h[:], sig); err != nil {
+			return errors.NewUnauthorized(err, "invalid signature")
+		}
+		return nil
+	case ed25519.PublicKey:
+		if alg != "" && alg != Ed25519 && alg != HS2019 {
+			return errors.NotSupportedf("algorithm %q can not be used with Ed25519 keys", alg)
+		}
+		if !ed25519.Verify(k, data, sig) {
+			return errors.Unauthorizedf("invalid signature")
+		}
+		return nil
+	}
+	return errors.NotSupportedf("unsupported public key type %T", key)
+}
+
+// checkClockSkew returns an error if the t signing time is further than MaxClockSkew from the current time
+func checkClockSkew(t time.Time, what string) error {
+	if d := time.Since(t); d > MaxClockSkew || d < -MaxClockSkew {
+		return errors.Unauthorizedf("%s %s is outside the accepted clock skew of %s", what, t.UTC().Format(time.RFC3339), MaxClockSkew)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package httpsig
+
+import (
+	"bytes"
+	"crypto"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/go-ap/errors"
+	vocab "github.com/snoymy/activitypub"
+)
+
+// VerifyRequest checks the signature of the request against the actor's public key, using
+// the RFC 9421 scheme when the request has a Signature-Input header and the draft-cavage one otherwise.
+func VerifyRequest(r *http.Request, key vocab.PublicKey) error {
+	if len(r.Header.Values("Signature-Input")) > 0 {
+		return VerifyMessage(r, key)
+	}
+	return Verify(r, key)
+}
+
+// DoubleKnock is an http.RoundTripper which signs outgoing requests using the RFC 9421 scheme,
+// and when the remote server rejects them, retries once using the draft-cavage scheme.
+//
+// The hosts which accepted only the draft-cavage signatures are remembered, and further requests
+// to them are signed directly with the legacy scheme.
+type DoubleKnock struct {
+	// Transport is the RoundTripper used to send the requests, if nil http.DefaultTransport is used
+	Transport http.RoundTripper
+	// Message signs the requests using the RFC 9421 scheme
+	Message *MessageSigner
+	// Legacy signs the requests using the draft-cavage scheme
+	Legacy *Signer
+
+	mu     sync.RWMutex
+	legacy map[string]struct{}
+}
+
+// NewDoubleKnock initializes a DoubleKnock transport which signs requests with the private key
+// identified by keyID, using the default covered components and headers for each scheme.
+func NewDoubleKnock(keyID vocab.IRI, key crypto.PrivateKey, tr http.RoundTripper) (*DoubleKnock, error) {
+	m, err := NewMessageSigner(keyID, key)
+	if err != nil {
+		return nil, err
+	}
+	s, err := NewSigner(keyID, key)
+	if err != nil {
+		return nil, err
+	}
+	return &DoubleKnock{Transport: tr, Message: m, Legacy: s}, nil
+}
+
+// rejected returns true if the status code is one that servers use for refusing a signature.
+// A 400 is not one of them, as resending a malformed request with another signature doesn't fix it.
+func rejected(status int) bool {
+	return status == http.StatusUnauthorized || status == http.StatusForbidden
+}
+
+// RoundTrip signs and sends the request, without modifying the original.
+func (d *DoubleKnock) RoundTrip(r *http.Request) (*http.Response, error) {
+	if d.Message == nil || d.Legacy == nil {
+		return nil, errors.Newf("DoubleKnock transport needs both an RFC 9421 and a draft-cavage signer")
+	}
+	var body []byte
+	if r.Body != nil && r.Body != http.NoBody {
+		var err error
+		if body, err = io.ReadAll(r.Body); err != nil {
+			return nil, errors.Annotatef(err, "unable to read request body")
+		}
+		_ = r.Body.Close()
+	}
+
+	host := requestHost(r)
+	if d.isLegacy(host) {
+		return d.send(r, body, d.Legacy.Sign)
+	}
+
+	res, err := d.send(r, body, d.Message.Sign)
+	if err != nil || !rejected(res.StatusCode) {
+		return res, err
+	}
+	// the body of the rejected response is discarded so the connection can be reused
+	_, _ = io.Copy(io.Discard, res.Body)
+	_ = res.Body.Close()
+
+	res, err = d.send(r, body, d.Legacy.Sign)
+	if err == nil && !rejected(res.StatusCode) {
+		d.mu.Lock()
+		if d.legacy == nil {
+			d.legacy = make(map[string]struct{})
+		}
+		d.legacy[host] = struct{}{}
+		d.mu.Unlock()
+	}
+	return res, err
+}
+
+func (d *DoubleKnock) isLegacy(host string) bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	_, ok := d.legacy[host]
+	return ok
+}
+
+func (d *DoubleKnock) send(r *http.Request, body []byte, signFn func(*http.Request) error) (*http.Response, error) {
+	req := r.Clone(r.Context())
+	if body != nil {
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+		req.ContentLength = int64(len(body))
+	}
+	if err := signFn(req); err != nil {
+		return nil, err
+	}
+	tr := d.Transport
+	if tr == nil {
+		tr = http.DefaultTransport
+	}
+	return tr.RoundTrip(req)
+}
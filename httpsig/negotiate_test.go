@@ -0,0 +1,121 @@
+package httpsig
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	vocab "github.com/snoymy/activitypub"
+)
+
+func TestDoubleKnock_RoundTrip(t *testing.T) {
+	key := testKeys(t)[0]
+
+	tests := []struct {
+		name string
+		// verifyFn is the signature verification done by the remote server
+		verifyFn func(*http.Request, vocab.PublicKey) error
+		// failStatus is the status the remote server responds with when verifyFn fails, 401 if empty
+		failStatus int
+		// wantSchemes is the list of schemes the server receives on two consecutive requests
+		wantSchemes []string
+		wantStatus  int
+	}{
+		{
+			name:        "RFC 9421 server",
+			verifyFn:    VerifyRequest,
+			wantSchemes: []string{"rfc9421", "rfc9421"},
+			wantStatus:  http.StatusAccepted,
+		},
+		{
+			name:        "draft-cavage server",
+			verifyFn:    Verify,
+			wantSchemes: []string{"rfc9421", "cavage", "cavage"},
+			wantStatus:  http.StatusAccepted,
+		},
+		{
+			name: "server rejecting both",
+			verifyFn: func(*http.Request, vocab.PublicKey) error {
+				return http.ErrNotSupported
+			},
+			wantSchemes: []string{"rfc9421", "cavage", "rfc9421", "cavage"},
+			wantStatus:  http.StatusUnauthorized,
+		},
+		{
+			name: "server rejecting a malformed request",
+			verifyFn: func(*http.Request, vocab.PublicKey) error {
+				return http.ErrNotSupported
+			},
+			failStatus:  http.StatusBadRequest,
+			wantSchemes: []string{"rfc9421", "rfc9421"},
+			wantStatus:  http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schemes := make([]string, 0)
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if len(r.Header.Values("Signature-Input")) > 0 {
+					schemes = append(schemes, "rfc9421")
+				} else {
+					schemes = append(schemes, "cavage")
+				}
+				if err := tt.verifyFn(r, key.pub); err != nil {
+					status := tt.failStatus
+					if status == 0 {
+						status = http.StatusUnauthorized
+					}
+					w.WriteHeader(status)
+					return
+				}
+				w.WriteHeader(http.StatusAccepted)
+			}))
+			defer srv.Close()
+
+			tr, err := NewDoubleKnock(vocab.IRI(key.pub.ID), key.priv, srv.Client().Transport)
+			if err != nil {
+				t.Fatalf("NewDoubleKnock() error = %s", err)
+			}
+			cl := http.Client{Transport: tr}
+			for i := 0; i < 2; i++ {
+				res, err := cl.Post(srv.URL+"/inbox", "application/activity+json", strings.NewReader(`{"type":"Note"}`))
+				if err != nil {
+					t.Fatalf("unable to send request: %s", err)
+				}
+				_ = res.Body.Close()
+				if res.StatusCode != tt.wantStatus {
+					t.Errorf("RoundTrip() status = %d, want %d", res.StatusCode, tt.wantStatus)
+				}
+			}
+			if strings.Join(schemes, ",") != strings.Join(tt.wantSchemes, ",") {
+				t.Errorf("RoundTrip() signature schemes = %v, want %v", schemes, tt.wantSchemes)
+			}
+		})
+	}
+}
+
+func TestVerifyRequest(t *testing.T) {
+	key := testKeys(t)[1]
+	rfc, _ := NewMessageSigner(vocab.IRI(key.pub.ID), key.priv)
+	cavage, _ := NewSigner(vocab.IRI(key.pub.ID), key.priv)
+
+	tests := []struct {
+		name   string
+		signFn func(*http.Request) error
+	}{
+		{name: "rfc9421", signFn: rfc.Sign},
+		{name: "cavage", signFn: cavage.Sign},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "https://example.org/inbox", strings.NewReader(`{"type":"Note"}`))
+			if err := tt.signFn(req); err != nil {
+				t.Fatalf("Sign() error = %s", err)
+			}
+			if err := VerifyRequest(req, key.pub); err != nil {
+				t.Errorf("VerifyRequest() error = %s", err)
+			}
+		})
+	}
+}
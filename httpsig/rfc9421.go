@@ -0,0 +1,456 @@
+package httpsig
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha512"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-ap/errors"
+	vocab "github.com/snoymy/activitypub"
+)
+
+const (
+	// RSAv15SHA256 is the RFC 9421 name for RSASSA-PKCS1-v1_5 signatures using SHA-256
+	RSAv15SHA256 = "rsa-v1_5-sha256"
+	// RSAPSSSHA512 is the RFC 9421 name for RSASSA-PSS signatures using SHA-512
+	RSAPSSSHA512 = "rsa-pss-sha512"
+
+	// DefaultLabel is the label used for the signatures created by a MessageSigner
+	DefaultLabel = "sig1"
+)
+
+// DefaultComponents are the components covered by a MessageSigner when none are specified.
+// The "content-digest" field is skipped for requests without a body.
+var DefaultComponents = []string{"@method", "@target-uri", "date", "content-digest"}
+
+// MessageSigner signs HTTP requests using the RFC 9421 HTTP Message Signatures scheme
+//
+// https://www.rfc-editor.org/rfc/rfc9421
+type MessageSigner struct {
+	// KeyID is the IRI of the actor's public key, usually the ID of its PublicKey
+	KeyID vocab.IRI
+	// Key is the private key, it must be an *rsa.PrivateKey or an ed25519.PrivateKey
+	Key crypto.PrivateKey
+	// Label is the name of the signature in the Signature-Input and Signature dictionaries,
+	// if empty DefaultLabel is used
+	Label string
+	// Components is the list of components covered by the signature, if empty DefaultComponents is used
+	Components []string
+}
+
+// NewMessageSigner initializes a MessageSigner for the key identified by keyID, covering the received components.
+func NewMessageSigner(keyID vocab.IRI, key crypto.PrivateKey, components ...string) (*MessageSigner, error) {
+	if _, err := messageAlgorithmForKey(key); err != nil {
+		return nil, err
+	}
+	if len(components) == 0 {
+		components = DefaultComponents
+	}
+	return &MessageSigner{KeyID: keyID, Key: key, Label: DefaultLabel, Components: components}, nil
+}
+
+// Sign adds the Signature-Input and Signature headers to the request.
+// It sets the Date and the Content-Digest headers on the request if they are covered and missing.
+func (s MessageSigner) Sign(r *http.Request) error {
+	alg, err := messageAlgorithmForKey(s.Key)
+	if err != nil {
+		return err
+	}
+	label := s.Label
+	if len(label) == 0 {
+		label = DefaultLabel
+	}
+	components := s.Components
+	if len(components) == 0 {
+		components = DefaultComponents
+	}
+
+	body, err := readBody(r)
+	if err != nil {
+		return err
+	}
+
+	sig := MessageSignature{
+		Label:     label,
+		KeyID:     s.KeyID,
+		Algorithm: alg,
+		Created:   time.Now().UTC().Unix(),
+	}
+	for _, c := range components {
+		c = strings.ToLower(c)
+		switch c {
+		case "date":
+			if len(r.Header.Get("Date")) == 0 {
+				r.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+			}
+		case "content-digest":
+			if body == nil {
+				continue
+			}
+			if len(r.Header.Get("Content-Digest")) == 0 {
+				r.Header.Set("Content-Digest", ContentDigest(body))
+			}
+		}
+		sig.Components = append(sig.Components, c)
+	}
+	sig.params = sig.serializeParams()
+
+	base, err := sig.SignatureBase(r)
+	if err != nil {
+		return err
+	}
+	if sig.Signature, err = signMessage(s.Key, alg, []byte(base)); err != nil {
+		return err
+	}
+	r.Header.Add("Signature-Input", label+"="+sig.params)
+	r.Header.Add("Signature", label+"=:"+base64.StdEncoding.EncodeToString(sig.Signature)+":")
+	return nil
+}
+
+// MessageSignature represents one of the signatures of an RFC 9421 signed message,
+// composed of the matching members of the Signature-Input and Signature headers.
+type MessageSignature struct {
+	Label      string
+	Components []string
+	KeyID      vocab.IRI
+	Algorithm  string
+	Created    int64
+	Expires    int64
+	Nonce      string
+	Tag        string
+	Signature  []byte
+
+	// params is the serialized value of the Signature-Input member, as received
+	params string
+}
+
+// MessageSignaturesFromRequest loads all the signatures of the request from its Signature-Input and Signature headers
+func MessageSignaturesFromRequest(r *http.Request) ([]MessageSignature, error) {
+	inputs := parseDictionary(strings.Join(r.Header.Values("Signature-Input"), ","))
+	if len(inputs) == 0 {
+		return nil, errors.Unauthorizedf("missing Signature-Input header")
+	}
+	values := parseDictionary(strings.Join(r.Header.Values("Signature"), ","))
+
+	sigs := make([]MessageSignature, 0, len(inputs))
+	for _, in := range inputs {
+		sig, err := parseSignatureInput(in.key, in.value)
+		if err != nil {
+			return nil, err
+		}
+		raw := ""
+		for _, v := range values {
+			if v.key == in.key {
+				raw = v.value
+				break
+			}
+		}
+		if len(raw) < 2 || raw[0] != ':' || raw[len(raw)-1] != ':' {
+			return nil, errors.NotValidf("missing or invalid Signature value for %q", in.key)
+		}
+		if sig.Signature, err = base64.StdEncoding.DecodeString(raw[1 : len(raw)-1]); err != nil {
+			return nil, errors.NewNotValid(err, "invalid Signature value for %q", in.key)
+		}
+		sigs = append(sigs, *sig)
+	}
+	return sigs, nil
+}
+
+func parseSignatureInput(label, value string) (*MessageSignature, error) {
+	end := strings.Index(value, ")")
+	if !strings.HasPrefix(value, "(") || end < 0 {
+		return nil, errors.NotValidf("invalid Signature-Input value for %q", label)
+	}
+	sig := MessageSignature{Label: label, params: value}
+	for _, c := range strings.Fields(value[1:end]) {
+		if !strings.HasPrefix(c, `"`) || !strings.HasSuffix(c, `"`) {
+			return nil, errors.NotSupportedf("unsupported Signature-Input component %s for %q", c, label)
+		}
+		sig.Components = append(sig.Components, strings.Trim(c, `"`))
+	}
+	for _, p := range splitOutsideQuotes(value[end+1:], ';') {
+		name, val, _ := strings.Cut(strings.TrimSpace(p), "=")
+		val = strings.Trim(val, `"`)
+		var err error
+		switch name {
+		case "keyid":
+			sig.KeyID = vocab.IRI(val)
+		case "alg":
+			sig.Algorithm = val
+		case "created":
+			sig.Created, err = strconv.ParseInt(val, 10, 64)
+		case "expires":
+			sig.Expires, err = strconv.ParseInt(val, 10, 64)
+		case "nonce":
+			sig.Nonce = val
+		case "tag":
+			sig.Tag = val
+		}
+		if err != nil {
+			return nil, errors.NewNotValid(err, "invalid Signature-Input parameter %q for %q", name, label)
+		}
+	}
+	return &sig, nil
+}
+
+func (s MessageSignature) serializeParams() string {
+	b := strings.Builder{}
+	b.WriteByte('(')
+	for i, c := range s.Components {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(strconv.Quote(c))
+	}
+	b.WriteByte(')')
+	if s.Created > 0 {
+		b.WriteString(";created=" + strconv.FormatInt(s.Created, 10))
+	}
+	if s.Expires > 0 {
+		b.WriteString(";expires=" + strconv.FormatInt(s.Expires, 10))
+	}
+	if len(s.Nonce) > 0 {
+		b.WriteString(";nonce=" + strconv.Quote(s.Nonce))
+	}
+	if len(s.KeyID) > 0 {
+		b.WriteString(";keyid=" + strconv.Quote(s.KeyID.String()))
+	}
+	if len(s.Algorithm) > 0 {
+		b.WriteString(";alg=" + strconv.Quote(s.Algorithm))
+	}
+	if len(s.Tag) > 0 {
+		b.WriteString(";tag=" + strconv.Quote(s.Tag))
+	}
+	return b.String()
+}
+
+// SignatureBase builds the signature base from the components of the request covered by s
+//
+// https://www.rfc-editor.org/rfc/rfc9421#name-creating-the-signature-base
+func (s MessageSignature) SignatureBase(r *http.Request) (string, error) {
+	params := s.params
+	if len(params) == 0 {
+		params = s.serializeParams()
+	}
+	b := strings.Builder{}
+	for _, c := range s.Components {
+		val, err := componentValue(r, c)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(strconv.Quote(c) + ": " + val + "\n")
+	}
+	b.WriteString(`"@signature-params": ` + params)
+	return b.String(), nil
+}
+
+func componentValue(r *http.Request, c string) (string, error) {
+	switch c {
+	case "@method":
+		return r.Method, nil
+	case "@target-uri":
+		return requestScheme(r) + "://" + requestHost(r) + r.URL.RequestURI(), nil
+	case "@authority":
+		return strings.ToLower(requestHost(r)), nil
+	case "@scheme":
+		return requestScheme(r), nil
+	case "@request-target":
+		return r.URL.RequestURI(), nil
+	case "@path":
+		if p := r.URL.EscapedPath(); len(p) > 0 {
+			return p, nil
+		}
+		return "/", nil
+	case "@query":
+		return "?" + r.URL.RawQuery, nil
+	}
+	if strings.HasPrefix(c, "@") {
+		return "", errors.NotSupportedf("unsupported derived component %q", c)
+	}
+	if c == "host" {
+		return requestHost(r), nil
+	}
+	values := r.Header.Values(c)
+	if len(values) == 0 {
+		return "", errors.NotValidf("missing header %q covered by the signature", c)
+	}
+	// Values returns the request's own storage, which we must not modify
+	trimmed := make([]string, len(values))
+	for i, v := range values {
+		trimmed[i] = strings.TrimSpace(v)
+	}
+	return strings.Join(trimmed, ", "), nil
+}
+
+// VerifyMessage checks the RFC 9421 signature of the request made with the actor's public key.
+// The signature must cover the method and the target of the request, and the Content-Digest header
+// for requests with a body, which is checked against the body. Its "created" parameter must be
+// within MaxClockSkew of the current time.
+func VerifyMessage(r *http.Request, key vocab.PublicKey) error {
+	sigs, err := MessageSignaturesFromRequest(r)
+	if err != nil {
+		return err
+	}
+	for _, sig := range sigs {
+		if len(key.ID) == 0 || sig.KeyID == key.ID {
+			return sig.Verify(r, key)
+		}
+	}
+	return errors.Unauthorizedf("no signature made with public key %s", key.ID)
+}
+
+// Verify checks the signature against the request and the actor's public key.
+func (s MessageSignature) Verify(r *http.Request, key vocab.PublicKey) error {
+	if s.Expires > 0 && time.Now().UTC().Unix() > s.Expires {
+		return errors.Unauthorizedf("signature has expired")
+	}
+	if s.Created == 0 {
+		return errors.Unauthorizedf("signature is missing the created parameter")
+	}
+	if err := checkClockSkew(time.Unix(s.Created, 0), "signature creation time"); err != nil {
+		return err
+	}
+	if !s.covers("@method") || !(s.covers("@target-uri") || (s.covers("@authority") && s.covers("@path"))) {
+		return errors.Unauthorizedf("signature does not cover the method and the target of the request")
+	}
+	body, err := readBody(r)
+	if err != nil {
+		return err
+	}
+	if len(body) > 0 && !s.covers("content-digest") {
+		return errors.Unauthorizedf("signature does not cover the digest of the request body")
+	}
+	if s.covers("content-digest") {
+		if err = VerifyContentDigest(r); err != nil {
+			return err
+		}
+	}
+	return s.verifySignature(r, key)
+}
+
+// verifySignature checks only the cryptographic signature of the covered components, without
+// the checks on their coverage and freshness which Verify does
+func (s MessageSignature) verifySignature(r *http.Request, key vocab.PublicKey) error {
+	pub, err := PublicKeyFrom(key)
+	if err != nil {
+		return err
+	}
+	base, err := s.SignatureBase(r)
+	if err != nil {
+		return err
+	}
+	return verifyMessage(pub, s.Algorithm, []byte(base), s.Signature)
+}
+
+func (s MessageSignature) covers(component string) bool {
+	for _, c := range s.Components {
+		if c == component {
+			return true
+		}
+	}
+	return false
+}
+
+func messageAlgorithmForKey(key crypto.PrivateKey) (string, error) {
+	switch key.(type) {
+	case *rsa.PrivateKey:
+		return RSAv15SHA256, nil
+	case ed25519.PrivateKey:
+		return Ed25519, nil
+	}
+	return "", errors.NotSupportedf("unsupported private key type %T", key)
+}
+
+func signMessage(key crypto.PrivateKey, alg string, data []byte) ([]byte, error) {
+	switch alg {
+	case RSAv15SHA256:
+		return sign(key, RSASHA256, data)
+	case Ed25519:
+		return sign(key, Ed25519, data)
+	}
+	return nil, errors.NotSupportedf("unsupported algorithm %q", alg)
+}
+
+func verifyMessage(key crypto.PublicKey, alg string, data, sig []byte) error {
+	switch alg {
+	case "":
+		return verify(key, "", data, sig)
+	case RSAv15SHA256:
+		return verify(key, RSASHA256, data, sig)
+	case Ed25519:
+		return verify(key, Ed25519, data, sig)
+	case RSAPSSSHA512:
+		k, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return errors.NotSupportedf("algorithm %q can not be used with %T keys", alg, key)
+		}
+		h := sha512.Sum512(data)
+		opts := rsa.PSSOptions{SaltLength: 64, Hash: crypto.SHA512}
+		if err := rsa.VerifyPSS(k, crypto.SHA512, h[:], sig, &opts); err != nil {
+			return errors.NewUnauthorized(err, "invalid signature")
+		}
+		return nil
+	}
+	return errors.NotSupportedf("unsupported algorithm %q", alg)
+}
+
+func requestScheme(r *http.Request) string {
+	if len(r.URL.Scheme) > 0 {
+		return strings.ToLower(r.URL.Scheme)
+	}
+	if r.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
+
+type dictionaryMember struct {
+	key   string
+	value string
+}
+
+// parseDictionary splits a structured field dictionary into its members, keeping the order
+//
+// https://www.rfc-editor.org/rfc/rfc8941#name-dictionaries
+func parseDictionary(header string) []dictionaryMember {
+	members := make([]dictionaryMember, 0)
+	for _, m := range splitOutsideQuotes(header, ',') {
+		key, value, ok := strings.Cut(strings.TrimSpace(m), "=")
+		if !ok || len(key) == 0 {
+			continue
+		}
+		members = append(members, dictionaryMember{key: key, value: strings.TrimSpace(value)})
+	}
+	return members
+}
+
+func splitOutsideQuotes(s string, sep byte) []string {
+	parts := make([]string, 0)
+	quoted := false
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if quoted {
+				i++
+			}
+		case '"':
+			quoted = !quoted
+		case sep:
+			if !quoted {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if start < len(s) {
+		parts = append(parts, s[start:])
+	}
+	return parts
+}
@@ -0,0 +1,338 @@
+package httpsig
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	vocab "github.com/snoymy/activitypub"
+)
+
+// rfcRequest returns the request used by the examples in the RFC 9421 appendix
+//
+// https://www.rfc-editor.org/rfc/rfc9421#name-example-http-messages
+func rfcRequest() *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/foo?param=Value&Pet=dog", strings.NewReader(`{"hello": "world"}`))
+	req.Host = "example.com"
+	req.Header.Set("Date", "Tue, 20 Apr 2021 02:07:55 GMT")
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Length", "18")
+	return req
+}
+
+// rfcEd25519Key is the "test-key-ed25519" public key from the RFC 9421 appendix
+var rfcEd25519Key = vocab.PublicKey{
+	ID:           "test-key-ed25519",
+	PublicKeyPem: "-----BEGIN PUBLIC KEY-----\nMCowBQYDK2VwAyEAJrQLj5P/89iXES9+vFgrIy29clF9CC/oPPsw3c5D0bs=\n-----END PUBLIC KEY-----\n",
+}
+
+func TestMessageSigner_Sign(t *testing.T) {
+	for _, key := range testKeys(t) {
+		t.Run(key.name, func(t *testing.T) {
+			var verifyErr error
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				verifyErr = VerifyMessage(r, key.pub)
+			}))
+			defer srv.Close()
+
+			s, err := NewMessageSigner(vocab.IRI(key.pub.ID), key.priv)
+			if err != nil {
+				t.Fatalf("NewMessageSigner() error = %s", err)
+			}
+
+			body := []byte(`{"type":"Follow","actor":"https://example.com/~jdoe","object":"https://example.org/~alice"}`)
+			req, _ := http.NewRequest(http.MethodPost, srv.URL+"/inbox?page=1", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/activity+json")
+			if err = s.Sign(req); err != nil {
+				t.Fatalf("Sign() error = %s", err)
+			}
+			if got := req.Header.Get("Content-Digest"); got != ContentDigest(body) {
+				t.Errorf("Sign() content digest = %s, want %s", got, ContentDigest(body))
+			}
+			if got := req.Header.Get("Signature-Input"); !strings.HasPrefix(got, DefaultLabel+`=("@method" "@target-uri" "date" "content-digest");created=`) {
+				t.Errorf("Sign() Signature-Input = %s", got)
+			}
+			if _, err = srv.Client().Do(req); err != nil {
+				t.Fatalf("unable to send request: %s", err)
+			}
+			if verifyErr != nil {
+				t.Errorf("VerifyMessage() error = %s", verifyErr)
+			}
+		})
+	}
+}
+
+func TestVerifyMessage(t *testing.T) {
+	keys := testKeys(t)
+	rsaKey, edKey := keys[0], keys[1]
+
+	signed := func(key testKey, method, body string) *http.Request {
+		var req *http.Request
+		if len(body) > 0 {
+			req = httptest.NewRequest(method, "https://example.org/inbox", strings.NewReader(body))
+		} else {
+			req = httptest.NewRequest(method, "https://example.org/~alice", nil)
+		}
+		s, _ := NewMessageSigner(vocab.IRI(key.pub.ID), key.priv)
+		if err := s.Sign(req); err != nil {
+			t.Fatalf("Sign() error = %s", err)
+		}
+		return req
+	}
+	// signedAt signs a POST request with a body by hand, as MessageSigner always sets the created parameter to the current time
+	signedAt := func(key testKey, created int64, components ...string) *http.Request {
+		body := `{"type":"Note"}`
+		req := httptest.NewRequest(http.MethodPost, "https://example.org/inbox", strings.NewReader(body))
+		req.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+		req.Header.Set("Content-Digest", ContentDigest([]byte(body)))
+		alg, _ := messageAlgorithmForKey(key.priv)
+		sig := MessageSignature{Label: DefaultLabel, KeyID: vocab.IRI(key.pub.ID), Algorithm: alg, Created: created, Components: components}
+		sig.params = sig.serializeParams()
+		base, err := sig.SignatureBase(req)
+		if err != nil {
+			t.Fatalf("SignatureBase() error = %s", err)
+		}
+		if sig.Signature, err = signMessage(key.priv, alg, []byte(base)); err != nil {
+			t.Fatalf("signMessage() error = %s", err)
+		}
+		req.Header.Set("Signature-Input", DefaultLabel+"="+sig.params)
+		req.Header.Set("Signature", DefaultLabel+"=:"+base64.StdEncoding.EncodeToString(sig.Signature)+":")
+		return req
+	}
+	now := time.Now().Unix()
+	rfcSigned := func() *http.Request {
+		req := rfcRequest()
+		req.Header.Set("Signature-Input", `sig-b26=("date" "@method" "@path" "@authority" "content-type" "content-length");created=1618884473;keyid="test-key-ed25519"`)
+		req.Header.Set("Signature", `sig-b26=:wqcAqbmYJ2ji2glfAMaRy4gruYYnx2nEFN2HN6jrnDnQCK1u02Gb04v9EDgwUPiu4A0w6vuQv5lIp5WPpBKRCw==:`)
+		return req
+	}
+
+	tests := []struct {
+		name    string
+		req     func() *http.Request
+		key     vocab.PublicKey
+		wantErr bool
+	}{
+		{
+			name: "rsa GET",
+			req:  func() *http.Request { return signed(rsaKey, http.MethodGet, "") },
+			key:  rsaKey.pub,
+		},
+		{
+			name: "ed25519 POST",
+			req:  func() *http.Request { return signed(edKey, http.MethodPost, `{"type":"Note"}`) },
+			key:  edKey.pub,
+		},
+		{
+			// the example is too old, and doesn't cover the Content-Digest of its body
+			name:    "RFC 9421 B.2.6 example",
+			req:     rfcSigned,
+			key:     rfcEd25519Key,
+			wantErr: true,
+		},
+		{
+			name: "authority and path",
+			req:  func() *http.Request { return signedAt(edKey, now, "@method", "@authority", "@path", "content-digest") },
+			key:  edKey.pub,
+		},
+		{
+			name:    "method only",
+			req:     func() *http.Request { return signedAt(edKey, now, "@method", "content-digest") },
+			key:     edKey.pub,
+			wantErr: true,
+		},
+		{
+			name:    "body without content digest",
+			req:     func() *http.Request { return signedAt(edKey, now, "@method", "@target-uri", "date") },
+			key:     edKey.pub,
+			wantErr: true,
+		},
+		{
+			name:    "missing created",
+			req:     func() *http.Request { return signedAt(edKey, 0, "@method", "@target-uri", "content-digest") },
+			key:     edKey.pub,
+			wantErr: true,
+		},
+		{
+			name: "stale created",
+			req: func() *http.Request {
+				return signedAt(edKey, now-int64((MaxClockSkew+time.Hour)/time.Second), "@method", "@target-uri", "content-digest")
+			},
+			key:     edKey.pub,
+			wantErr: true,
+		},
+		{
+			name: "multiple signatures",
+			req: func() *http.Request {
+				req := signed(rsaKey, http.MethodGet, "")
+				s, _ := NewMessageSigner(vocab.IRI(edKey.pub.ID), edKey.priv)
+				s.Label = "sig2"
+				if err := s.Sign(req); err != nil {
+					t.Fatalf("Sign() error = %s", err)
+				}
+				return req
+			},
+			key: edKey.pub,
+		},
+		{
+			name:    "missing signature",
+			req:     func() *http.Request { return httptest.NewRequest(http.MethodGet, "https://example.org/~alice", nil) },
+			key:     rsaKey.pub,
+			wantErr: true,
+		},
+		{
+			name:    "wrong key",
+			req:     func() *http.Request { return signed(rsaKey, http.MethodGet, "") },
+			key:     vocab.PublicKey{ID: rsaKey.pub.ID, PublicKeyPem: edKey.pub.PublicKeyPem},
+			wantErr: true,
+		},
+		{
+			name:    "key ID mismatch",
+			req:     func() *http.Request { return signed(rsaKey, http.MethodGet, "") },
+			key:     vocab.PublicKey{ID: "https://example.com/~jdoe#other-key", PublicKeyPem: rsaKey.pub.PublicKeyPem},
+			wantErr: true,
+		},
+		{
+			name: "tampered path",
+			req: func() *http.Request {
+				req := signed(rsaKey, http.MethodGet, "")
+				req.URL.Path = "/~bob"
+				return req
+			},
+			key:     rsaKey.pub,
+			wantErr: true,
+		},
+		{
+			name: "tampered body",
+			req: func() *http.Request {
+				req := signed(edKey, http.MethodPost, `{"type":"Note"}`)
+				req.Body = io.NopCloser(strings.NewReader(`{"type":"Article"}`))
+				return req
+			},
+			key:     edKey.pub,
+			wantErr: true,
+		},
+		{
+			name: "tampered RFC 9421 example",
+			req: func() *http.Request {
+				req := rfcSigned()
+				req.Header.Set("Content-Type", "text/plain")
+				return req
+			},
+			key:     rfcEd25519Key,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := VerifyMessage(tt.req(), tt.key); (err != nil) != tt.wantErr {
+				t.Errorf("VerifyMessage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMessageSignature_verifySignature(t *testing.T) {
+	req := rfcRequest()
+	req.Header.Set("Signature-Input", `sig-b26=("date" "@method" "@path" "@authority" "content-type" "content-length");created=1618884473;keyid="test-key-ed25519"`)
+	req.Header.Set("Signature", `sig-b26=:wqcAqbmYJ2ji2glfAMaRy4gruYYnx2nEFN2HN6jrnDnQCK1u02Gb04v9EDgwUPiu4A0w6vuQv5lIp5WPpBKRCw==:`)
+	sigs, err := MessageSignaturesFromRequest(req)
+	if err != nil || len(sigs) != 1 {
+		t.Fatalf("MessageSignaturesFromRequest() = %v, error = %v", sigs, err)
+	}
+	if err = sigs[0].verifySignature(req, rfcEd25519Key); err != nil {
+		t.Errorf("verifySignature() of the RFC 9421 B.2.6 example error = %s", err)
+	}
+	req.Header.Set("Content-Type", "text/plain")
+	if err = sigs[0].verifySignature(req, rfcEd25519Key); err == nil {
+		t.Errorf("verifySignature() of the tampered RFC 9421 B.2.6 example should fail")
+	}
+}
+
+func TestComponentValue(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "https://example.org/inbox", nil)
+	req.Header.Add("Accept", " application/activity+json ")
+	req.Header.Add("Accept", "application/ld+json")
+	got, err := componentValue(req, "accept")
+	if err != nil {
+		t.Fatalf("componentValue() error = %s", err)
+	}
+	if want := "application/activity+json, application/ld+json"; got != want {
+		t.Errorf("componentValue() = %q, want %q", got, want)
+	}
+	if raw := req.Header.Values("Accept")[0]; raw != " application/activity+json " {
+		t.Errorf("componentValue() modified the request header to %q", raw)
+	}
+}
+
+func TestMessageSignature_SignatureBase(t *testing.T) {
+	sig := MessageSignature{
+		Components: []string{"@method", "@authority", "@path", "@query", "content-digest"},
+		KeyID:      "test-key-rsa",
+		Created:    1618884473,
+	}
+	req := rfcRequest()
+	req.Header.Set("Content-Digest", "sha-512=:WZDPaVn/7XgHaAy8pmojAkGWoRx2UFChF41A2svX+TaPm+AbwAgBWnrIiYllu7BNNyealdVLvRwEmTHWXvJwew==:")
+
+	want := `"@method": POST
+"@authority": example.com
+"@path": /foo
+"@query": ?param=Value&Pet=dog
+"content-digest": sha-512=:WZDPaVn/7XgHaAy8pmojAkGWoRx2UFChF41A2svX+TaPm+AbwAgBWnrIiYllu7BNNyealdVLvRwEmTHWXvJwew==:
+"@signature-params": ("@method" "@authority" "@path" "@query" "content-digest");created=1618884473;keyid="test-key-rsa"`
+	got, err := sig.SignatureBase(req)
+	if err != nil {
+		t.Fatalf("SignatureBase() error = %s", err)
+	}
+	if got != want {
+		t.Errorf("SignatureBase() = \n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestVerifyContentDigest(t *testing.T) {
+	body := `{"hello": "world"}`
+	tests := []struct {
+		name    string
+		digest  string
+		wantErr bool
+	}{
+		{
+			name:   "sha-256",
+			digest: "sha-256=:X48E9qOokqqrvdts8nOJRJN3OWDUoyWxBf7kbu9DBPE=:",
+		},
+		{
+			name:   "sha-512",
+			digest: "sha-512=:WZDPaVn/7XgHaAy8pmojAkGWoRx2UFChF41A2svX+TaPm+AbwAgBWnrIiYllu7BNNyealdVLvRwEmTHWXvJwew==:",
+		},
+		{
+			name:    "missing",
+			wantErr: true,
+		},
+		{
+			name:    "mismatch",
+			digest:  "sha-256=:" + strings.Repeat("A", 43) + "=:",
+			wantErr: true,
+		},
+		{
+			name:    "unsupported algorithm",
+			digest:  "md5=:Sd/dVLAcvNLSq16eXua5uQ==:",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "https://example.org/inbox", strings.NewReader(body))
+			if len(tt.digest) > 0 {
+				req.Header.Set("Content-Digest", tt.digest)
+			}
+			if err := VerifyContentDigest(req); (err != nil) != tt.wantErr {
+				t.Errorf("VerifyContentDigest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
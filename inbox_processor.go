@@ -0,0 +1,468 @@
+package activitypub
+
+import "github.com/go-ap/errors"
+
+// InboxProcessor applies the side effects of the activities other servers deliver to the
+// inboxes of local actors.
+//
+// https://www.w3.org/TR/activitypub/#server-to-server-interactions
+type InboxProcessor struct {
+	Store Store
+	// AcceptFollowFn decides if a Follow activity gets accepted, when nil all Follows are accepted.
+	AcceptFollowFn func(*Follow) bool
+	// Cache, when set, gets the objects of the processed Update and Delete activities invalidated.
+	Cache Invalidator
+	// IsLocal returns true if the iri identifies an actor or object owned by the server.
+	// It's needed for processing Follow, Accept, Add and Remove activities, which only apply to
+	// local actors and collections.
+	IsLocal func(iri IRI) bool
+}
+
+// InboxProcessorNew initializes an InboxProcessor which applies its side effects to the s Store
+func InboxProcessorNew(s Store) *InboxProcessor {
+	return &InboxProcessor{Store: s}
+}
+
+// Process applies the side effects of the "it" activity and stores it.
+//
+// The activity, and the objects it creates, must be on the host of its actor and must not be already
+// stored. The objects it references are always loaded from the Store, as the embedded ones are
+// entirely controlled by the sender.
+//
+// It returns the objects and collections that were modified. For Follow activities it also
+// returns the Accept or Reject response, which needs to be delivered to the actor of the Follow.
+func (p InboxProcessor) Process(it Item) (ItemCollection, error) {
+	if p.Store == nil {
+		return nil, errors.Newf("nil store for the inbox processor")
+	}
+	if IsNil(it) {
+		return nil, errors.NotValidf("nil activity")
+	}
+	typ := it.GetType()
+	if !ActivityTypes.Contains(typ) && !IntransitiveActivityTypes.Contains(typ) {
+		return nil, errors.NotValidf("%T[%s] is not an activity", it, typ)
+	}
+
+	var modified ItemCollection
+	err := OnIntransitiveActivity(it, func(act *IntransitiveActivity) error {
+		if IsNil(act.Actor) {
+			return errors.NotValidf("missing actor for %s activity", act.Type)
+		}
+		if len(act.ID) == 0 {
+			return nil
+		}
+		if !sameHost(act.ID, act.Actor.GetLink()) {
+			return errors.Forbiddenf("%s activity %s is not on the host of its actor %s", act.Type, act.ID, act.Actor.GetLink())
+		}
+		if _, err := p.Store.Load(act.ID); err == nil {
+			return errors.Conflictf("%s already exists", act.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if ActivityTypes.Contains(typ) {
+		err = OnActivity(it, func(act *Activity) error {
+			var err error
+			if modified, err = p.processActivity(act); err != nil {
+				return err
+			}
+			if p.Cache != nil && (act.Type == UpdateType || act.Type == DeleteType) {
+				for _, ob := range objectsOf(act.Object) {
+					p.Cache.Invalidate(ob.GetLink())
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	// we record the activity, so it can be referenced later by an Accept or an Undo
+	if len(it.GetLink()) > 0 {
+		if _, err = p.Store.Save(it); err != nil {
+			return nil, err
+		}
+	}
+	return modified, nil
+}
+
+func (p InboxProcessor) processActivity(act *Activity) (ItemCollection, error) {
+	switch act.Type {
+	case CreateType:
+		return p.create(act)
+	case UpdateType:
+		return p.update(act)
+	case DeleteType:
+		return p.delete(act)
+	case FollowType:
+		return p.follow(act)
+	case AcceptType:
+		return p.accept(act)
+	case AddType:
+		return p.addOrRemove(act, p.Store.AddTo)
+	case RemoveType:
+		return p.addOrRemove(act, p.Store.RemoveFrom)
+	case LikeType:
+		return p.appendTo(Likes, act)
+	case AnnounceType:
+		return p.appendTo(Shares, act)
+	case UndoType:
+		return p.undo(act)
+	}
+	// Reject and the rest of the activities don't have side effects on the receiving server
+	return nil, nil
+}
+
+// objectsOf returns the items of "it" if it's a collection, and it wrapped in a collection otherwise
+func objectsOf(it Item) ItemCollection {
+	if IsNil(it) {
+		return nil
+	}
+	objects := ItemCollection{it}
+	if IsItemCollection(it) {
+		_ = OnItemCollection(it, func(col *ItemCollection) error {
+			objects = *col
+			return nil
+		})
+	}
+	return objects
+}
+
+// load returns the stored version of the "it" item, even if it is embedded
+func (p InboxProcessor) load(it Item) (Item, error) {
+	if IsNil(it) {
+		return nil, errors.NotFoundf("nil item")
+	}
+	return p.Store.Load(it.GetLink())
+}
+
+// checkIsLocal returns an error if the processor can't tell apart the local actors and objects,
+// which the typ activities need
+func (p InboxProcessor) checkIsLocal(typ ActivityVocabularyType) error {
+	if p.IsLocal == nil {
+		return errors.Newf("nil locality check for the inbox processor, unable to process %s activity", typ)
+	}
+	return nil
+}
+
+// loadCollection returns the stored collection, or just its IRI if it can't be loaded
+func (p InboxProcessor) loadCollection(col IRI) Item {
+	if it, err := p.Store.Load(col); err == nil && !IsNil(it) {
+		return it
+	}
+	return col
+}
+
+// owns returns true if the actor is the "it" object, is the actor of the "it" activity, or is
+// one of the object's authors. Objects without attribution are owned by actors on the same host.
+func owns(actor IRI, it Item) bool {
+	if IsNil(it) {
+		return false
+	}
+	if it.GetLink().Equals(actor, false) {
+		return true
+	}
+	owned := false
+	attributed := false
+	if ActivityTypes.Contains(it.GetType()) || IntransitiveActivityTypes.Contains(it.GetType()) {
+		_ = OnIntransitiveActivity(it, func(act *IntransitiveActivity) error {
+			attributed = !IsNil(act.Actor)
+			owned = attributed && act.Actor.GetLink().Equals(actor, false)
+			return nil
+		})
+	}
+	if owned {
+		return true
+	}
+	_ = OnObject(it, func(o *Object) error {
+		for _, author := range objectsOf(o.AttributedTo) {
+			attributed = true
+			if author.GetLink().Equals(actor, false) {
+				owned = true
+			}
+		}
+		return nil
+	})
+	if owned || attributed {
+		return owned
+	}
+	ou, err := it.GetLink().URL()
+	if err != nil {
+		return false
+	}
+	au, err := actor.URL()
+	if err != nil {
+		return false
+	}
+	return ou.Host == au.Host
+}
+
+func (p InboxProcessor) create(act *Activity) (ItemCollection, error) {
+	if IsNil(act.Object) {
+		return nil, errors.NotValidf("missing object for %s activity", act.Type)
+	}
+	var modified ItemCollection
+	for _, ob := range objectsOf(act.Object) {
+		if IsNil(ob) || ob.IsLink() {
+			continue
+		}
+		if !sameHost(ob.GetLink(), act.Actor.GetLink()) {
+			return nil, errors.Forbiddenf("%s can not create %s on another host", act.Actor.GetLink(), ob.GetLink())
+		}
+		if _, err := p.Store.Load(ob.GetLink()); err == nil {
+			return nil, errors.Conflictf("%s already exists", ob.GetLink())
+		}
+		saved, err := p.Store.Save(ob)
+		if err != nil {
+			return nil, err
+		}
+		modified = append(modified, saved)
+	}
+	return modified, nil
+}
+
+func (p InboxProcessor) update(act *Activity) (ItemCollection, error) {
+	if IsNil(act.Object) {
+		return nil, errors.NotValidf("missing object for %s activity", act.Type)
+	}
+	var modified ItemCollection
+	for _, ob := range objectsOf(act.Object) {
+		if IsNil(ob) || ob.IsLink() {
+			return nil, errors.NotValidf("the object of an %s activity must be embedded", act.Type)
+		}
+		old, err := p.Store.Load(ob.GetLink())
+		if err != nil {
+			return nil, err
+		}
+		if !owns(act.Actor.GetLink(), old) {
+			return nil, errors.Unauthorizedf("%s is not allowed to update %s", act.Actor.GetLink(), ob.GetLink())
+		}
+		saved, err := p.Store.Save(ob)
+		if err != nil {
+			return nil, err
+		}
+		modified = append(modified, saved)
+	}
+	return modified, nil
+}
+
+func (p InboxProcessor) delete(act *Activity) (ItemCollection, error) {
+	if IsNil(act.Object) {
+		return nil, errors.NotValidf("missing object for %s activity", act.Type)
+	}
+	var modified ItemCollection
+	for _, ob := range objectsOf(act.Object) {
+		old, err := p.Store.Load(ob.GetLink())
+		if err != nil {
+			return nil, err
+		}
+		if !owns(act.Actor.GetLink(), old) {
+			return nil, errors.Unauthorizedf("%s is not allowed to delete %s", act.Actor.GetLink(), ob.GetLink())
+		}
+		saved, err := p.Store.Delete(old)
+		if err != nil {
+			return nil, err
+		}
+		modified = append(modified, saved)
+	}
+	return modified, nil
+}
+
+func (p InboxProcessor) follow(act *Activity) (ItemCollection, error) {
+	if err := p.checkIsLocal(act.Type); err != nil {
+		return nil, err
+	}
+	followed, err := p.load(act.Object)
+	if err != nil {
+		return nil, err
+	}
+	if !ActorTypes.Contains(followed.GetType()) {
+		return nil, errors.NotValidf("the object of a %s activity must be an actor", act.Type)
+	}
+	if !p.IsLocal(followed.GetLink()) {
+		return nil, errors.Forbiddenf("%s is not a local actor", followed.GetLink())
+	}
+
+	var response *Activity
+	modified := make(ItemCollection, 0)
+	if p.AcceptFollowFn == nil || p.AcceptFollowFn(act) {
+		col := Followers.IRI(followed)
+		if err = p.Store.AddTo(col, act.Actor.GetLink()); err != nil {
+			return nil, err
+		}
+		modified = append(modified, p.loadCollection(col))
+		response = AcceptNew("", act)
+	} else {
+		response = RejectNew("", act)
+	}
+	response.Actor = followed.GetLink()
+	response.To = ItemCollection{act.Actor.GetLink()}
+	return append(modified, response), nil
+}
+
+func (p InboxProcessor) accept(act *Activity) (ItemCollection, error) {
+	if IsNil(act.Object) || (!act.Object.IsLink() && act.Object.GetType() != FollowType) {
+		// we only have side effects for accepted Follow requests
+		return nil, nil
+	}
+	if err := p.checkIsLocal(act.Type); err != nil {
+		return nil, err
+	}
+	// only the stored Follow can be trusted, an embedded one is entirely controlled by the sender
+	ob, err := p.Store.Load(act.Object.GetLink())
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, errors.NotFoundf("unknown activity %s accepted by %s", act.Object.GetLink(), act.Actor.GetLink())
+		}
+		return nil, err
+	}
+	if ob.GetType() != FollowType {
+		return nil, nil
+	}
+	var modified ItemCollection
+	err = OnActivity(ob, func(follow *Activity) error {
+		if IsNil(follow.Actor) || !p.IsLocal(follow.Actor.GetLink()) {
+			return errors.Forbiddenf("%s is not a Follow of a local actor", follow.GetLink())
+		}
+		if IsNil(follow.Object) || !follow.Object.GetLink().Equals(act.Actor.GetLink(), false) {
+			return errors.Unauthorizedf("%s can not accept a Follow of %s", act.Actor.GetLink(), follow.Object.GetLink())
+		}
+		follower, err := p.load(follow.Actor)
+		if err != nil {
+			follower = follow.Actor
+		}
+		col := Following.IRI(follower)
+		if err = p.Store.AddTo(col, act.Actor.GetLink()); err != nil {
+			return err
+		}
+		modified = append(modified, p.loadCollection(col))
+		return nil
+	})
+	return modified, err
+}
+
+func (p InboxProcessor) addOrRemove(act *Activity, fn func(IRI, ...Item) error) (ItemCollection, error) {
+	if IsNil(act.Object) {
+		return nil, errors.NotValidf("missing object for %s activity", act.Type)
+	}
+	if IsNil(act.Target) {
+		return nil, errors.NotValidf("missing target for %s activity", act.Type)
+	}
+	if err := p.checkIsLocal(act.Type); err != nil {
+		return nil, err
+	}
+	col := act.Target.GetLink()
+	if !p.IsLocal(col) {
+		return nil, errors.Forbiddenf("%s is not a local collection", col)
+	}
+	target, err := p.load(act.Target)
+	if err != nil {
+		return nil, err
+	}
+	if !owns(act.Actor.GetLink(), target) {
+		return nil, errors.Forbiddenf("%s is not allowed to modify %s", act.Actor.GetLink(), col)
+	}
+	items := make(ItemCollection, 0)
+	for _, ob := range objectsOf(act.Object) {
+		items = append(items, ob.GetLink())
+	}
+	if err = fn(col, items...); err != nil {
+		return nil, err
+	}
+	return ItemCollection{p.loadCollection(col)}, nil
+}
+
+// appendTo adds the activity to the "likes" or "shares" collection of its stored object, if the object has one
+func (p InboxProcessor) appendTo(path CollectionPath, act *Activity) (ItemCollection, error) {
+	if IsNil(act.Object) {
+		return nil, errors.NotValidf("missing object for %s activity", act.Type)
+	}
+	if len(act.ID) == 0 {
+		return nil, errors.NotValidf("missing ID for %s activity", act.Type)
+	}
+	var modified ItemCollection
+	for _, it := range objectsOf(act.Object) {
+		ob, err := p.load(it)
+		if err != nil || !hasCollection(path, ob) {
+			continue
+		}
+		col := path.Of(ob).GetLink()
+		if err = p.Store.AddTo(col, act.GetLink()); err != nil {
+			return nil, err
+		}
+		modified = append(modified, p.loadCollection(col))
+	}
+	return modified, nil
+}
+
+// hasCollection returns true if the collection corresponding to path is present on the object
+func hasCollection(path CollectionPath, it Item) bool {
+	present := false
+	_ = OnObject(it, func(o *Object) error {
+		switch path {
+		case Likes:
+			present = !IsNil(o.Likes)
+		case Shares:
+			present = !IsNil(o.Shares)
+		case Replies:
+			present = !IsNil(o.Replies)
+		}
+		return nil
+	})
+	return present
+}
+
+func (p InboxProcessor) undo(act *Activity) (ItemCollection, error) {
+	if IsNil(act.Object) {
+		return nil, errors.NotValidf("missing object for %s activity", act.Type)
+	}
+	// only the stored activity can be trusted, an embedded one is entirely controlled by the sender
+	undone, err := p.Store.Load(act.Object.GetLink())
+	if err != nil {
+		return nil, err
+	}
+	if !ActivityTypes.Contains(undone.GetType()) {
+		return nil, errors.NotValidf("the object of an %s activity must be an activity", act.Type)
+	}
+	var modified ItemCollection
+	err = OnActivity(undone, func(u *Activity) error {
+		if IsNil(u.Actor) || !u.Actor.GetLink().Equals(act.Actor.GetLink(), false) {
+			return errors.Unauthorizedf("%s is not allowed to undo %s", act.Actor.GetLink(), u.GetLink())
+		}
+		var (
+			col  Item
+			item Item
+		)
+		switch u.Type {
+		case FollowType:
+			followed, err := p.load(u.Object)
+			if err != nil {
+				followed = u.Object
+			}
+			col, item = Followers.IRI(followed), u.Actor.GetLink()
+		case LikeType, AnnounceType:
+			ob, err := p.load(u.Object)
+			if err != nil {
+				return nil
+			}
+			path := Likes
+			if u.Type == AnnounceType {
+				path = Shares
+			}
+			if !hasCollection(path, ob) {
+				return nil
+			}
+			col, item = path.Of(ob), u.GetLink()
+		default:
+			return nil
+		}
+		if err := p.Store.RemoveFrom(col.GetLink(), item); err != nil {
+			return err
+		}
+		modified = append(modified, p.loadCollection(col.GetLink()))
+		return nil
+	})
+	return modified, err
+}
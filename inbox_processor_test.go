@@ -0,0 +1,328 @@
+package activitypub
+
+import "testing"
+
+// storeMembers returns the items of the col collection in the s Store
+func storeMembers(s Store, col IRI) ItemCollection {
+	var members ItemCollection
+	if it, err := s.Load(col); err == nil {
+		_ = OnCollectionIntf(it, func(c CollectionInterface) error {
+			members = c.Collection()
+			return nil
+		})
+	}
+	return members
+}
+
+func TestInboxProcessor_Process(t *testing.T) {
+	jdoe := PersonNew("https://example.com/~jdoe")
+	alice := PersonNew("https://example.org/~alice")
+	note := &Object{
+		ID:           "https://example.org/~alice/notes/1",
+		Type:         NoteType,
+		AttributedTo: alice.GetLink(),
+		Likes:        IRI("https://example.org/~alice/notes/1/likes"),
+	}
+	like := &Activity{ID: "https://example.com/~jdoe/likes/1", Type: LikeType, Actor: jdoe.GetLink(), Object: note.GetLink()}
+	likes := func() *OrderedCollection {
+		col := OrderedCollectionNew(note.Likes.GetLink())
+		col.OrderedItems = ItemCollection{like.GetLink()}
+		return col
+	}
+	follow := &Activity{ID: "https://example.com/~jdoe/follows/1", Type: FollowType, Actor: jdoe.GetLink(), Object: alice.GetLink()}
+	remoteFollow := &Activity{ID: "https://example.org/~alice/follows/1", Type: FollowType, Actor: alice.GetLink(), Object: jdoe.GetLink()}
+	mallory := IRI("https://example.net/~mallory")
+	featured := OrderedCollectionNew("https://example.com/~jdoe/featured")
+	featured.AttributedTo = jdoe.GetLink()
+	isLocal := func(iri IRI) bool {
+		u, err := iri.URL()
+		return err == nil && u.Host == "example.com"
+	}
+
+	tests := []struct {
+		name     string
+		store    Store
+		acceptFn func(*Follow) bool
+		it       Item
+		wantErr  bool
+		check    func(*testing.T, Store, ItemCollection)
+	}{
+		{
+			name:    "nil",
+			store:   MemoryStoreNew(),
+			wantErr: true,
+		},
+		{
+			name:    "not an activity",
+			store:   MemoryStoreNew(),
+			it:      note,
+			wantErr: true,
+		},
+		{
+			name:    "missing actor",
+			store:   MemoryStoreNew(),
+			it:      &Activity{Type: CreateType, Object: note},
+			wantErr: true,
+		},
+		{
+			name:    "update of an object of another actor",
+			store:   MemoryStoreNew(note),
+			it:      &Activity{Type: UpdateType, Actor: jdoe.GetLink(), Object: &Object{ID: note.ID, Type: NoteType}},
+			wantErr: true,
+		},
+		{
+			name:    "delete of an unknown object",
+			store:   MemoryStoreNew(),
+			it:      &Activity{Type: DeleteType, Actor: alice.GetLink(), Object: note.GetLink()},
+			wantErr: true,
+		},
+		{
+			name:    "add to a collection of another actor",
+			store:   MemoryStoreNew(OrderedCollectionNew("https://example.org/~alice/featured")),
+			it:      &Activity{Type: AddType, Actor: jdoe.GetLink(), Object: note.GetLink(), Target: IRI("https://example.org/~alice/featured")},
+			wantErr: true,
+		},
+		{
+			name:  "add to a local collection with an embedded target claiming another owner",
+			store: MemoryStoreNew(featured),
+			it: &Activity{
+				Type:   AddType,
+				Actor:  alice.GetLink(),
+				Object: note.GetLink(),
+				Target: &OrderedCollection{ID: featured.ID, Type: OrderedCollectionType, AttributedTo: alice.GetLink()},
+			},
+			wantErr: true,
+			check: func(t *testing.T, s Store, got ItemCollection) {
+				if members := storeMembers(s, featured.ID); len(members) > 0 {
+					t.Errorf("Process() featured = %v, want none", members)
+				}
+			},
+		},
+		{
+			name:    "add to an unknown local collection",
+			store:   MemoryStoreNew(),
+			it:      &Activity{Type: AddType, Actor: jdoe.GetLink(), Object: note.GetLink(), Target: featured.GetLink()},
+			wantErr: true,
+		},
+		{
+			name:  "add to a local collection of the actor",
+			store: MemoryStoreNew(featured),
+			it:    &Activity{Type: AddType, Actor: jdoe.GetLink(), Object: note.GetLink(), Target: featured.GetLink()},
+			check: func(t *testing.T, s Store, got ItemCollection) {
+				if !storeMembers(s, featured.ID).Contains(note.GetLink()) {
+					t.Errorf("Process() featured = %v, want %s", storeMembers(s, featured.ID), note.GetLink())
+				}
+			},
+		},
+		{
+			name:     "rejected follow",
+			store:    MemoryStoreNew(jdoe),
+			acceptFn: func(*Follow) bool { return false },
+			it:       remoteFollow,
+			check: func(t *testing.T, s Store, got ItemCollection) {
+				if len(got) != 1 || got[0].GetType() != RejectType {
+					t.Errorf("Process() = %v, want a Reject response", got)
+				}
+				if members := storeMembers(s, Followers.IRI(jdoe)); len(members) > 0 {
+					t.Errorf("Process() followers = %v, want none", members)
+				}
+			},
+		},
+		{
+			name:  "accepted follow",
+			store: MemoryStoreNew(jdoe),
+			it:    remoteFollow,
+			check: func(t *testing.T, s Store, got ItemCollection) {
+				if !storeMembers(s, Followers.IRI(jdoe)).Contains(alice.GetLink()) {
+					t.Errorf("Process() followers = %v, want %s", storeMembers(s, Followers.IRI(jdoe)), alice.GetLink())
+				}
+			},
+		},
+		{
+			name:    "follow of a remote actor",
+			store:   MemoryStoreNew(alice),
+			it:      &Activity{ID: "https://example.net/~mallory/follows/1", Type: FollowType, Actor: mallory, Object: alice.GetLink()},
+			wantErr: true,
+		},
+		{
+			name:  "follow of an embedded actor with a forged followers collection",
+			store: MemoryStoreNew(jdoe),
+			it: &Activity{
+				ID:     "https://example.net/~mallory/follows/1",
+				Type:   FollowType,
+				Actor:  mallory,
+				Object: &Actor{ID: "https://example.com/~nobody", Type: PersonType, Followers: Followers.IRI(jdoe)},
+			},
+			wantErr: true,
+			check: func(t *testing.T, s Store, got ItemCollection) {
+				if members := storeMembers(s, Followers.IRI(jdoe)); len(members) > 0 {
+					t.Errorf("Process() followers = %v, want none", members)
+				}
+			},
+		},
+		{
+			name:  "like of an embedded object with a forged likes collection",
+			store: MemoryStoreNew(note),
+			it: &Activity{
+				ID:     "https://example.net/~mallory/likes/1",
+				Type:   LikeType,
+				Actor:  mallory,
+				Object: &Object{ID: note.ID, Type: NoteType, Likes: Followers.IRI(jdoe)},
+			},
+			check: func(t *testing.T, s Store, got ItemCollection) {
+				if members := storeMembers(s, Followers.IRI(jdoe)); len(members) > 0 {
+					t.Errorf("Process() followers = %v, want none", members)
+				}
+				if !storeMembers(s, note.Likes.GetLink()).Contains(IRI("https://example.net/~mallory/likes/1")) {
+					t.Errorf("Process() likes = %v, want the like", storeMembers(s, note.Likes.GetLink()))
+				}
+			},
+		},
+		{
+			name:    "activity with the ID of a local actor",
+			store:   MemoryStoreNew(jdoe),
+			it:      &Activity{ID: jdoe.ID, Type: LikeType, Actor: alice.GetLink(), Object: note.GetLink()},
+			wantErr: true,
+			check: func(t *testing.T, s Store, got ItemCollection) {
+				if it, _ := s.Load(jdoe.ID); it != jdoe {
+					t.Errorf("Process() replaced %s with %v", jdoe.ID, it)
+				}
+			},
+		},
+		{
+			name:  "activity with the ID of its actor and a fragment",
+			store: MemoryStoreNew(alice, note),
+			it:    &Activity{ID: "https://example.org/~alice#likes/1", Type: LikeType, Actor: alice.GetLink(), Object: note.GetLink()},
+			check: func(t *testing.T, s Store, got ItemCollection) {
+				if it, _ := s.Load(alice.ID); it != alice {
+					t.Errorf("Process() replaced %s with %v", alice.ID, it)
+				}
+			},
+		},
+		{
+			name:    "activity with the ID of an existing item",
+			store:   MemoryStoreNew(note),
+			it:      &Activity{ID: note.ID, Type: LikeType, Actor: alice.GetLink(), Object: note.GetLink()},
+			wantErr: true,
+			check: func(t *testing.T, s Store, got ItemCollection) {
+				if it, _ := s.Load(note.ID); it != note {
+					t.Errorf("Process() replaced %s with %v", note.ID, it)
+				}
+			},
+		},
+		{
+			name:  "create",
+			store: MemoryStoreNew(),
+			it:    &Activity{ID: "https://example.org/~alice/activities/1", Type: CreateType, Actor: alice.GetLink(), Object: note},
+			check: func(t *testing.T, s Store, got ItemCollection) {
+				if it, _ := s.Load(note.ID); it != note {
+					t.Errorf("Process() stored %v, want %v", it, note)
+				}
+			},
+		},
+		{
+			name:  "create of an object on the local host",
+			store: MemoryStoreNew(jdoe),
+			it: &Activity{
+				Type:   CreateType,
+				Actor:  alice.GetLink(),
+				Object: &Object{ID: "https://example.com/~jdoe/notes/1", Type: NoteType, AttributedTo: alice.GetLink()},
+			},
+			wantErr: true,
+			check: func(t *testing.T, s Store, got ItemCollection) {
+				if _, err := s.Load("https://example.com/~jdoe/notes/1"); err == nil {
+					t.Errorf("Process() stored an object on the local host")
+				}
+			},
+		},
+		{
+			name:    "create of an existing object",
+			store:   MemoryStoreNew(note),
+			it:      &Activity{Type: CreateType, Actor: alice.GetLink(), Object: &Object{ID: note.ID, Type: NoteType, AttributedTo: alice.GetLink()}},
+			wantErr: true,
+			check: func(t *testing.T, s Store, got ItemCollection) {
+				if it, _ := s.Load(note.ID); it != note {
+					t.Errorf("Process() replaced %s with %v", note.ID, it)
+				}
+			},
+		},
+		{
+			name:  "undo like",
+			store: MemoryStoreNew(note, like, likes()),
+			it:    &Activity{Type: UndoType, Actor: jdoe.GetLink(), Object: like.GetLink()},
+			check: func(t *testing.T, s Store, got ItemCollection) {
+				if storeMembers(s, note.Likes.GetLink()).Contains(like.GetLink()) {
+					t.Errorf("Process() likes still contain %s", like.GetLink())
+				}
+			},
+		},
+		{
+			name:    "undo of an activity of another actor",
+			store:   MemoryStoreNew(note, like, likes()),
+			it:      &Activity{Type: UndoType, Actor: alice.GetLink(), Object: like.GetLink()},
+			wantErr: true,
+		},
+		{
+			name:  "undo of an embedded activity claiming another actor",
+			store: MemoryStoreNew(note, like, likes()),
+			it: &Activity{
+				Type:   UndoType,
+				Actor:  alice.GetLink(),
+				Object: &Activity{ID: like.ID, Type: LikeType, Actor: alice.GetLink(), Object: note.GetLink()},
+			},
+			wantErr: true,
+			check: func(t *testing.T, s Store, got ItemCollection) {
+				if !storeMembers(s, note.Likes.GetLink()).Contains(like.GetLink()) {
+					t.Errorf("Process() removed %s from the likes", like.GetLink())
+				}
+			},
+		},
+		{
+			name:  "accept of a stored follow",
+			store: MemoryStoreNew(jdoe, alice, follow),
+			it:    &Activity{Type: AcceptType, Actor: alice.GetLink(), Object: follow.GetLink()},
+			check: func(t *testing.T, s Store, got ItemCollection) {
+				if !storeMembers(s, Following.IRI(jdoe)).Contains(alice.GetLink()) {
+					t.Errorf("Process() following = %v, want %s", storeMembers(s, Following.IRI(jdoe)), alice.GetLink())
+				}
+			},
+		},
+		{
+			name:    "accept of a follow which was never stored",
+			store:   MemoryStoreNew(jdoe, alice),
+			it:      &Activity{Type: AcceptType, Actor: alice.GetLink(), Object: follow},
+			wantErr: true,
+			check: func(t *testing.T, s Store, got ItemCollection) {
+				if members := storeMembers(s, Following.IRI(jdoe)); len(members) > 0 {
+					t.Errorf("Process() following = %v, want none", members)
+				}
+			},
+		},
+		{
+			name:    "accept of a follow of a remote actor",
+			store:   MemoryStoreNew(jdoe, alice, remoteFollow),
+			it:      &Activity{Type: AcceptType, Actor: jdoe.GetLink(), Object: remoteFollow.GetLink()},
+			wantErr: true,
+		},
+		{
+			name:    "accept by an actor which was not followed",
+			store:   MemoryStoreNew(jdoe, alice, follow),
+			it:      &Activity{Type: AcceptType, Actor: IRI("https://example.net/~mallory"), Object: follow.GetLink()},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := InboxProcessorNew(tt.store)
+			p.AcceptFollowFn = tt.acceptFn
+			p.IsLocal = isLocal
+			got, err := p.Process(tt.it)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Process() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.check != nil {
+				tt.check(t, tt.store, got)
+			}
+		})
+	}
+}
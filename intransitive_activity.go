@@ -110,6 +110,8 @@ type IntransitiveActivity struct {
 	// as a form of provenance, or to support future editing by clients.
 	// In general, clients do the conversion from source to content, not the other way around.
 	Source Source `jsonld:"source,omitempty"`
+
+	Extensions Extensions `jsonld:"-"`
 	// CanReceiveActivities describes one or more entities that either performed or are expected to perform the activity.
 	// Any single activity can have multiple actors. The actor may be specified using an indirect Link.
 	Actor CanReceiveActivities `jsonld:"actor,omitempty"`
@@ -272,7 +272,7 @@ func TestIntransitiveActivity_Equals(t *testing.T) {
 		ID           ID
 		Type         ActivityVocabularyType
 		Name         NaturalLanguageValues
-		Attachment   Item
+		Attachment   ItemCollection
 		AttributedTo Item
 		Audience     ItemCollection
 		Content      NaturalLanguageValues
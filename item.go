@@ -130,6 +130,7 @@ func IsObject(it Item) bool {
 	switch ob := it.(type) {
 	case Actor, *Actor,
 		Object, *Object, Profile, *Profile, Place, *Place, Relationship, *Relationship, Tombstone, *Tombstone,
+		PropertyValue, *PropertyValue, IdentityProof, *IdentityProof,
 		Activity, *Activity, IntransitiveActivity, *IntransitiveActivity, Question, *Question,
 		Collection, *Collection, CollectionPage, *CollectionPage,
 		OrderedCollection, *OrderedCollection, OrderedCollectionPage, *OrderedCollectionPage:
@@ -1,6 +1,7 @@
 package activitypub
 
 import (
+	"iter"
 	"sort"
 )
 
@@ -70,6 +71,17 @@ func (i ItemCollection) First() Item {
 	return i[0]
 }
 
+// All returns an iterator over the items of the ItemCollection
+func (i ItemCollection) All() iter.Seq[Item] {
+	return func(yield func(Item) bool) {
+		for _, it := range i {
+			if !yield(it) {
+				return
+			}
+		}
+	}
+}
+
 // Normalize returns the first item if the collection contains only one,
 // the full collection if the collection contains more than one item,
 // or nil
@@ -0,0 +1,298 @@
+package activitypub
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/valyala/fastjson"
+)
+
+// ItemContextFn will return the JSON-LD context that MarshalJSON writes for a top level Item
+// The default for this package is DefaultItemContext but can be overwritten
+var ItemContextFn ContextFn = DefaultItemContext
+
+// ContextFn is the type of the function which returns the JSON-LD context for an Item
+type ContextFn func(Item) LDContext
+
+// LDContext represents the value of the "@context" property of a JSON-LD document.
+//
+// https://www.w3.org/TR/json-ld/#the-context
+//
+// IRIs holds the remote contexts referenced by the document, in the order they were declared,
+// and Terms holds the inline term definitions, mapping a term or a compact IRI prefix
+// (eg: "toot", "sensitive") to its definition.
+type LDContext struct {
+	IRIs  IRIs
+	Terms map[string]LDTerm
+}
+
+// LDTerm is the definition of a term in a JSON-LD context.
+//
+// https://www.w3.org/TR/json-ld/#expanded-term-definition
+type LDTerm struct {
+	// ID is the IRI the term expands to
+	ID IRI
+	// Type is the type the values of the term are coerced to, eg: "@id" for the properties
+	// whose values are IRIs. If empty, the term is written as a simple term definition.
+	Type IRI
+}
+
+// LDIDType is the type of the terms whose values are IRIs
+const LDIDType IRI = "@id"
+
+// LDContextNew initializes a new LDContext referencing the received IRIs
+func LDContextNew(iris ...IRI) LDContext {
+	c := LDContext{}
+	for _, iri := range iris {
+		c.AddIRI(iri)
+	}
+	return c
+}
+
+// DefaultItemContext returns the ActivityStreams context, to which it adds the Security context
+// for Actors which have a PublicKey, and the term definitions for the extension properties they use.
+func DefaultItemContext(it Item) LDContext {
+	c := LDContextNew(ActivityBaseURI)
+	if IsNil(it) || !ActorTypes.Contains(it.GetType()) {
+		return c
+	}
+	_ = OnActor(it, func(a *Actor) error {
+		if len(a.PublicKey.ID)+len(a.PublicKey.PublicKeyPem) > 0 {
+			c.AddIRI(SecurityContextURI)
+		}
+		if a.ManuallyApprovesFollowers {
+			c.AddTerm("manuallyApprovesFollowers", "as:manuallyApprovesFollowers")
+		}
+		if len(a.AlsoKnownAs) > 0 {
+			c.AddTypedTerm("alsoKnownAs", "as:alsoKnownAs", LDIDType)
+		}
+		if a.MovedTo != nil {
+			c.AddTypedTerm("movedTo", "as:movedTo", LDIDType)
+		}
+		tootTerms := map[string]LDTerm{}
+		if a.Discoverable {
+			tootTerms["discoverable"] = LDTerm{ID: "toot:discoverable"}
+		}
+		if a.Indexable {
+			tootTerms["indexable"] = LDTerm{ID: "toot:indexable"}
+		}
+		if a.Featured != nil {
+			tootTerms["featured"] = LDTerm{ID: "toot:featured", Type: LDIDType}
+		}
+		if a.FeaturedTags != nil {
+			tootTerms["featuredTags"] = LDTerm{ID: "toot:featuredTags", Type: LDIDType}
+		}
+		for term, def := range tootTerms {
+			c.AddTerm("toot", TootNS)
+			c.AddTypedTerm(term, def.ID, def.Type)
+		}
+		return nil
+	})
+	return c
+}
+
+// IsEmpty returns true if the context contains no IRIs and no term definitions
+func (c LDContext) IsEmpty() bool {
+	return len(c.IRIs) == 0 && len(c.Terms) == 0
+}
+
+// AddIRI appends the iri to the list of remote contexts, if it's not already present
+func (c *LDContext) AddIRI(iri IRI) {
+	if len(iri) == 0 || c.IRIs.Contains(iri) {
+		return
+	}
+	c.IRIs = append(c.IRIs, iri)
+}
+
+// AddTerm adds a term definition to the context
+func (c *LDContext) AddTerm(term string, iri IRI) {
+	c.AddTypedTerm(term, iri, "")
+}
+
+// AddTypedTerm adds a term definition to the context, whose values are coerced to the typ type
+func (c *LDContext) AddTypedTerm(term string, iri, typ IRI) {
+	if len(term) == 0 || len(iri) == 0 {
+		return
+	}
+	if c.Terms == nil {
+		c.Terms = make(map[string]LDTerm)
+	}
+	c.Terms[term] = LDTerm{ID: iri, Type: typ}
+}
+
+// Merge returns a new context containing the IRIs and terms of both the receiver and "with".
+// The term definitions from "with" take precedence.
+func (c LDContext) Merge(with LDContext) LDContext {
+	r := LDContext{}
+	for _, iri := range c.IRIs {
+		r.AddIRI(iri)
+	}
+	for _, iri := range with.IRIs {
+		r.AddIRI(iri)
+	}
+	for t, def := range c.Terms {
+		r.AddTypedTerm(t, def.ID, def.Type)
+	}
+	for t, def := range with.Terms {
+		r.AddTypedTerm(t, def.ID, def.Type)
+	}
+	return r
+}
+
+// Expand resolves a term or a compact IRI of the form "prefix:suffix" using the context's
+// term definitions. Values that can't be resolved are returned unchanged.
+//
+// As the context can come from untrusted documents, term definitions which refer back to a term
+// that is already being expanded are not followed, and the value reached at that point is returned.
+func (c LDContext) Expand(s string) IRI {
+	return c.expand(s, make(map[string]struct{}))
+}
+
+func (c LDContext) expand(s string, visited map[string]struct{}) IRI {
+	if _, ok := visited[s]; ok {
+		return IRI(s)
+	}
+	visited[s] = struct{}{}
+	if def, ok := c.Terms[s]; ok {
+		return c.expand(string(def.ID), visited)
+	}
+	prefix, suffix, ok := strings.Cut(s, ":")
+	if !ok || strings.HasPrefix(suffix, "//") {
+		return IRI(s)
+	}
+	if _, ok := visited[prefix]; ok {
+		return IRI(s)
+	}
+	if def, ok := c.Terms[prefix]; ok {
+		return def.ID + IRI(suffix)
+	}
+	return IRI(s)
+}
+
+// MarshalJSON encodes the receiver object to a JSON document.
+// A context with a single IRI and no terms is written as a plain string, otherwise it's written as an
+// array with the term definitions grouped into an object as the last element.
+func (c LDContext) MarshalJSON() ([]byte, error) {
+	if c.IsEmpty() {
+		return nil, nil
+	}
+	b := make([]byte, 0)
+	if len(c.IRIs) == 1 && len(c.Terms) == 0 {
+		JSONWriteStringValue(&b, c.IRIs[0].String())
+		return b, nil
+	}
+	if len(c.IRIs) == 0 {
+		jsonWriteContextTerms(&b, c.Terms)
+		return b, nil
+	}
+	JSONWrite(&b, '[')
+	for i, iri := range c.IRIs {
+		if i > 0 {
+			JSONWrite(&b, ',')
+		}
+		JSONWriteStringValue(&b, iri.String())
+	}
+	if len(c.Terms) > 0 {
+		JSONWrite(&b, ',')
+		jsonWriteContextTerms(&b, c.Terms)
+	}
+	JSONWrite(&b, ']')
+	return b, nil
+}
+
+// UnmarshalJSON decodes an incoming JSON document into the receiver object.
+func (c *LDContext) UnmarshalJSON(data []byte) error {
+	p := fastjson.Parser{}
+	val, err := p.ParseBytes(data)
+	if err != nil {
+		return err
+	}
+	*c = LDContext{}
+	jsonLoadContextValue(val, c)
+	return nil
+}
+
+func jsonWriteContextTerms(b *[]byte, terms map[string]LDTerm) {
+	keys := make([]string, 0, len(terms))
+	for t := range terms {
+		keys = append(keys, t)
+	}
+	sort.Strings(keys)
+
+	t := make([]byte, 0)
+	JSONWrite(&t, '{')
+	for _, k := range keys {
+		def := terms[k]
+		if len(def.Type) == 0 {
+			JSONWriteStringProp(&t, k, def.ID.String())
+			continue
+		}
+		d := make([]byte, 0)
+		JSONWrite(&d, '{')
+		JSONWriteStringProp(&d, "@id", def.ID.String())
+		JSONWriteStringProp(&d, "@type", def.Type.String())
+		JSONWrite(&d, '}')
+		JSONWriteProp(&t, k, d)
+	}
+	JSONWrite(&t, '}')
+	JSONWrite(b, t...)
+}
+
+func jsonLoadContextValue(val *fastjson.Value, c *LDContext) {
+	if val == nil {
+		return
+	}
+	switch val.Type() {
+	case fastjson.TypeString:
+		c.AddIRI(IRI(val.GetStringBytes()))
+	case fastjson.TypeArray:
+		for _, v := range val.GetArray() {
+			jsonLoadContextValue(v, c)
+		}
+	case fastjson.TypeObject:
+		ob, _ := val.Object()
+		ob.Visit(func(key []byte, v *fastjson.Value) {
+			if len(key) == 0 || key[0] == '@' {
+				// we don't record JSON-LD keywords like @language or @vocab
+				return
+			}
+			switch v.Type() {
+			case fastjson.TypeString:
+				c.AddTerm(string(key), IRI(v.GetStringBytes()))
+			case fastjson.TypeObject:
+				c.AddTypedTerm(string(key), IRI(v.GetStringBytes("@id")), IRI(v.GetStringBytes("@type")))
+			}
+		})
+	}
+}
+
+// JSONGetContext loads the JSON-LD context declared by the "@context" property of val
+func JSONGetContext(val *fastjson.Value) LDContext {
+	c := LDContext{}
+	if val == nil {
+		return c
+	}
+	jsonLoadContextValue(val.Get("@context"), &c)
+	return c
+}
+
+// jsonWriteContext adds the "@context" property as the first member of the JSON object in data.
+// Data that doesn't represent a JSON object is returned unchanged.
+func jsonWriteContext(data []byte, c LDContext) []byte {
+	if len(data) < 2 || data[0] != '{' {
+		return data
+	}
+	v, err := c.MarshalJSON()
+	if err != nil || len(v) == 0 {
+		return data
+	}
+	b := make([]byte, 0, len(data)+len(v)+len(`"@context":,`))
+	JSONWrite(&b, '{')
+	JSONWritePropName(&b, "@context")
+	JSONWrite(&b, v...)
+	if data[1] != '}' {
+		JSONWrite(&b, ',')
+	}
+	JSONWrite(&b, data[1:]...)
+	return b
+}
@@ -0,0 +1,188 @@
+package activitypub
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/valyala/fastjson"
+)
+
+func TestLDContext_MarshalJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		ctx  LDContext
+		want string
+	}{
+		{
+			name: "empty",
+			ctx:  LDContext{},
+			want: "",
+		},
+		{
+			name: "single IRI",
+			ctx:  LDContextNew(ActivityBaseURI),
+			want: `"https://www.w3.org/ns/activitystreams"`,
+		},
+		{
+			name: "multiple IRIs",
+			ctx:  LDContextNew(ActivityBaseURI, SecurityContextURI),
+			want: `["https://www.w3.org/ns/activitystreams","https://w3id.org/security/v1"]`,
+		},
+		{
+			name: "IRI and terms",
+			ctx: LDContext{
+				IRIs:  IRIs{ActivityBaseURI},
+				Terms: map[string]LDTerm{"toot": {ID: "http://joinmastodon.org/ns#"}, "sensitive": {ID: "as:sensitive"}},
+			},
+			want: `["https://www.w3.org/ns/activitystreams",{"sensitive":"as:sensitive","toot":"http://joinmastodon.org/ns#"}]`,
+		},
+		{
+			name: "only terms",
+			ctx:  LDContext{Terms: map[string]LDTerm{"toot": {ID: "http://joinmastodon.org/ns#"}}},
+			want: `{"toot":"http://joinmastodon.org/ns#"}`,
+		},
+		{
+			name: "typed terms",
+			ctx: LDContext{
+				IRIs: IRIs{ActivityBaseURI},
+				Terms: map[string]LDTerm{
+					"toot":     {ID: "http://joinmastodon.org/ns#"},
+					"featured": {ID: "toot:featured", Type: LDIDType},
+				},
+			},
+			want: `["https://www.w3.org/ns/activitystreams",{"featured":{"@id":"toot:featured","@type":"@id"},"toot":"http://joinmastodon.org/ns#"}]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.ctx.MarshalJSON()
+			if err != nil {
+				t.Errorf("MarshalJSON() error = %v", err)
+				return
+			}
+			if string(got) != tt.want {
+				t.Errorf("MarshalJSON() got = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONGetContext(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want LDContext
+	}{
+		{
+			name: "missing",
+			data: `{"type":"Note"}`,
+			want: LDContext{},
+		},
+		{
+			name: "string",
+			data: `{"@context":"https://www.w3.org/ns/activitystreams"}`,
+			want: LDContextNew(ActivityBaseURI),
+		},
+		{
+			name: "mastodon",
+			data: `{"@context":["https://www.w3.org/ns/activitystreams","https://w3id.org/security/v1",{"manuallyApprovesFollowers":"as:manuallyApprovesFollowers","toot":"http://joinmastodon.org/ns#","featured":{"@id":"toot:featured","@type":"@id"},"@language":"und"}]}`,
+			want: LDContext{
+				IRIs: IRIs{ActivityBaseURI, SecurityContextURI},
+				Terms: map[string]LDTerm{
+					"manuallyApprovesFollowers": {ID: "as:manuallyApprovesFollowers"},
+					"toot":                      {ID: "http://joinmastodon.org/ns#"},
+					"featured":                  {ID: "toot:featured", Type: LDIDType},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			val, err := fastjson.Parse(tt.data)
+			if err != nil {
+				t.Fatalf("invalid test data %s", err)
+			}
+			if got := JSONGetContext(val); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("JSONGetContext() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLDContext_Expand(t *testing.T) {
+	ctx := LDContext{
+		IRIs: IRIs{ActivityBaseURI},
+		Terms: map[string]LDTerm{
+			"as":       {ID: ActivityBaseURI + "#"},
+			"toot":     {ID: "http://joinmastodon.org/ns#"},
+			"featured": {ID: "toot:featured"},
+		},
+	}
+	tests := []struct {
+		term string
+		want IRI
+	}{
+		{term: "featured", want: "http://joinmastodon.org/ns#featured"},
+		{term: "as:Public", want: PublicNS},
+		{term: "toot:Emoji", want: "http://joinmastodon.org/ns#Emoji"},
+		{term: "https://example.com/~jdoe", want: "https://example.com/~jdoe"},
+		{term: "unknown", want: "unknown"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.term, func(t *testing.T) {
+			if got := ctx.Expand(tt.term); got != tt.want {
+				t.Errorf("Expand() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLDContext_ExpandCycle(t *testing.T) {
+	ctx := LDContext{Terms: map[string]LDTerm{"a": {ID: "b"}, "b": {ID: "a"}, "self": {ID: "self"}, "p": {ID: "p:x"}}}
+	tests := []struct {
+		term string
+		want IRI
+	}{
+		{term: "a", want: "a"},
+		{term: "b", want: "b"},
+		{term: "self", want: "self"},
+		{term: "p", want: "p:x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.term, func(t *testing.T) {
+			if got := ctx.Expand(tt.term); got != tt.want {
+				t.Errorf("Expand() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLDContext_Merge(t *testing.T) {
+	c1 := LDContext{IRIs: IRIs{ActivityBaseURI}, Terms: map[string]LDTerm{"toot": {ID: "http://joinmastodon.org/ns#"}}}
+	c2 := LDContext{IRIs: IRIs{ActivityBaseURI, SecurityContextURI}, Terms: map[string]LDTerm{"misskey": {ID: "https://misskey-hub.net/ns#"}}}
+	want := LDContext{
+		IRIs: IRIs{ActivityBaseURI, SecurityContextURI},
+		Terms: map[string]LDTerm{
+			"toot":    {ID: "http://joinmastodon.org/ns#"},
+			"misskey": {ID: "https://misskey-hub.net/ns#"},
+		},
+	}
+	if got := c1.Merge(c2); !reflect.DeepEqual(got, want) {
+		t.Errorf("Merge() = %#v, want %#v", got, want)
+	}
+}
+
+func TestUnmarshalJSONWithContext(t *testing.T) {
+	data := []byte(`{"@context":["https://www.w3.org/ns/activitystreams",{"toot":"http://joinmastodon.org/ns#"}],"id":"https://example.com/1","type":"Note","inReplyTo":{"@context":"https://example.com/ns","id":"https://example.com/0","type":"Note"}}`)
+	it, ctx, err := UnmarshalJSONWithContext(data)
+	if err != nil {
+		t.Fatalf("UnmarshalJSONWithContext() error = %v", err)
+	}
+	wantCtx := LDContext{IRIs: IRIs{ActivityBaseURI}, Terms: map[string]LDTerm{"toot": {ID: "http://joinmastodon.org/ns#"}}}
+	if !reflect.DeepEqual(ctx, wantCtx) {
+		t.Errorf("UnmarshalJSONWithContext() context = %#v, want %#v", ctx, wantCtx)
+	}
+	if it == nil || it.GetLink() != "https://example.com/1" {
+		t.Errorf("UnmarshalJSONWithContext() item = %v, want %s", it, "https://example.com/1")
+	}
+}
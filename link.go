@@ -12,6 +12,7 @@ import (
 var LinkTypes = ActivityVocabularyTypes{
 	LinkType,
 	MentionType,
+	HashtagType,
 }
 
 type Links interface {
@@ -50,6 +51,8 @@ type Link struct {
 	// Hints as to the language used by the target resource.
 	// Value must be a [BCP47](https://tools.ietf.org/html/bcp47) Language-Tag.
 	HrefLang LangRef `jsonld:"hrefLang,omitempty"`
+
+	Extensions Extensions `jsonld:"-"`
 }
 
 // Mention is a specialized Link that represents an @mention.
@@ -0,0 +1,67 @@
+package activitypub
+
+// TootNS is the namespace of the Mastodon extensions to the ActivityStreams vocabulary
+const TootNS IRI = "http://joinmastodon.org/ns#"
+
+// Mastodon extension types
+//
+// https://docs.joinmastodon.org/spec/activitypub/#extensions
+const (
+	// HashtagType is a link type for #hashtags
+	HashtagType ActivityVocabularyType = "Hashtag"
+	// EmojiType is an object type for custom emojis
+	EmojiType ActivityVocabularyType = "Emoji"
+	// PropertyValueType is an object type for profile metadata fields
+	PropertyValueType ActivityVocabularyType = "PropertyValue"
+	// IdentityProofType is an object type for proofs of identity on external services
+	IdentityProofType ActivityVocabularyType = "IdentityProof"
+)
+
+// MastodonTypes contains the Mastodon extension types that this package knows how to handle
+var MastodonTypes = ActivityVocabularyTypes{
+	HashtagType,
+	EmojiType,
+	PropertyValueType,
+	IdentityProofType,
+}
+
+// Hashtag is a specialized Link that represents a #hashtag.
+//
+// https://docs.joinmastodon.org/spec/activitypub/#Hashtag
+type Hashtag = Link
+
+// Emoji is a specialized Object that represents a custom emoji, the "name" property contains
+// the shortcode and the "icon" property contains the Image to be displayed.
+//
+// https://docs.joinmastodon.org/spec/activitypub/#Emoji
+type Emoji = Object
+
+// HashtagNew initializes a new Hashtag with the received name, pointing to the href IRI
+func HashtagNew(name string, href IRI) *Hashtag {
+	return &Hashtag{Type: HashtagType, Name: DefaultNaturalLanguageValue(name), Href: href}
+}
+
+// EmojiNew initializes a new Emoji with the received shortcode name and icon
+func EmojiNew(id ID, name string, icon Item) *Emoji {
+	return &Emoji{ID: id, Type: EmojiType, Name: DefaultNaturalLanguageValue(name), Icon: icon}
+}
+
+// ToHashtag returns a Hashtag pointer to the data in the current Item
+func ToHashtag(it LinkOrIRI) (*Hashtag, error) {
+	return ToLink(it)
+}
+
+// OnHashtag calls function fn on it Item if it can be asserted to type *Hashtag
+func OnHashtag(it LinkOrIRI, fn WithLinkFn) error {
+	return OnLink(it, fn)
+}
+
+// ToEmoji returns an Emoji pointer to the data in the current Item
+func ToEmoji(it Item) (*Emoji, error) {
+	return ToObject(it)
+}
+
+// OnEmoji calls function fn on it Item if it can be asserted to type *Emoji
+func OnEmoji(it Item, fn WithObjectFn) error {
+	return OnObject(it, fn)
+}
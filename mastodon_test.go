@@ -0,0 +1,161 @@
+package activitypub
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+var mastodonActorJSON = `{
+  "id": "https://mastodon.example/users/jdoe",
+  "type": "Person",
+  "preferredUsername": "jdoe",
+  "tag": [
+    {
+      "id": "https://mastodon.example/emojis/1",
+      "type": "Emoji",
+      "name": ":blobcat:",
+      "updated": "2023-01-01T00:00:00Z",
+      "icon": {"type": "Image", "mediaType": "image/png", "url": "https://mastodon.example/emojis/blobcat.png"}
+    },
+    {"type": "Hashtag", "href": "https://mastodon.example/tags/cats", "name": "#cats"}
+  ],
+  "attachment": [
+    {"type": "PropertyValue", "name": "Website", "value": "<a href=\"https://example.com\">example.com</a>"},
+    {"type": "IdentityProof", "name": "jdoe", "signatureAlgorithm": "keybase", "signatureValue": "5cfc20c7018f2beefb42a68836da59a792e55daa4d118498c9b1898de7e845690f"}
+  ]
+}`
+
+func TestMastodonTypes_UnmarshalJSON(t *testing.T) {
+	it, err := UnmarshalJSON([]byte(mastodonActorJSON))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON() error = %v", err)
+	}
+	a, err := ToActor(it)
+	if err != nil {
+		t.Fatalf("UnmarshalJSON() returned %T, expected an Actor", it)
+	}
+
+	wantTags := ItemCollection{
+		&Emoji{
+			ID:      "https://mastodon.example/emojis/1",
+			Type:    EmojiType,
+			Name:    DefaultNaturalLanguageValue(":blobcat:"),
+			Updated: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+			Icon: &Object{
+				Type:      ImageType,
+				MediaType: "image/png",
+				URL:       IRI("https://mastodon.example/emojis/blobcat.png"),
+			},
+		},
+		&Hashtag{
+			Type: HashtagType,
+			Name: DefaultNaturalLanguageValue("#cats"),
+			Href: "https://mastodon.example/tags/cats",
+		},
+	}
+	if !reflect.DeepEqual(a.Tag, wantTags) {
+		t.Errorf("UnmarshalJSON() tag = %#v, want %#v", a.Tag, wantTags)
+	}
+
+	wantAttachments := ItemCollection{
+		PropertyValueNew("Website", `<a href="https://example.com">example.com</a>`),
+		IdentityProofNew("jdoe", "keybase", "5cfc20c7018f2beefb42a68836da59a792e55daa4d118498c9b1898de7e845690f"),
+	}
+	if !reflect.DeepEqual(a.Attachment, wantAttachments) {
+		t.Errorf("UnmarshalJSON() attachment = %#v, want %#v", a.Attachment, wantAttachments)
+	}
+}
+
+func TestMastodonTypes_Gob(t *testing.T) {
+	tests := []struct {
+		name string
+		it   Item
+		want Item
+	}{
+		{
+			name: "Emoji",
+			it:   EmojiNew("https://mastodon.example/emojis/1", ":blobcat:", IRI("https://mastodon.example/emojis/blobcat.png")),
+			want: &Emoji{
+				ID:      "https://mastodon.example/emojis/1",
+				Type:    EmojiType,
+				Name:    DefaultNaturalLanguageValue(":blobcat:"),
+				Content: NaturalLanguageValuesNew(),
+				Icon:    IRI("https://mastodon.example/emojis/blobcat.png"),
+			},
+		},
+		{
+			name: "Hashtag",
+			it:   HashtagNew("#cats", "https://mastodon.example/tags/cats"),
+		},
+		{
+			name: "PropertyValue",
+			it:   PropertyValueNew("Website", "https://example.com"),
+		},
+		{
+			name: "IdentityProof",
+			it:   IdentityProofNew("jdoe", "keybase", "5cfc20c7018f2b"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := GobEncode(tt.it)
+			if err != nil {
+				t.Fatalf("GobEncode() error = %v", err)
+			}
+			got, err := GobDecode(data)
+			if err != nil {
+				t.Fatalf("GobDecode() error = %v", err)
+			}
+			want := tt.want
+			if want == nil {
+				want = tt.it
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("GobDecode() got = %#v, want %#v", got, want)
+			}
+		})
+	}
+}
+
+func TestPropertyValue_MarshalJSON(t *testing.T) {
+	p := PropertyValueNew("Website", "https://example.com")
+	got, err := p.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON() error = %v", err)
+	}
+	want := `{"type":"PropertyValue","name":"Website","value":"https://example.com"}`
+	if string(got) != want {
+		t.Errorf("MarshalJSON() got = %s, want %s", got, want)
+	}
+}
+
+func TestIdentityProof_MarshalJSON(t *testing.T) {
+	p := IdentityProofNew("jdoe", "keybase", "5cfc20c7018f2b")
+	got, err := p.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON() error = %v", err)
+	}
+	want := `{"type":"IdentityProof","name":"jdoe","signatureAlgorithm":"keybase","signatureValue":"5cfc20c7018f2b"}`
+	if string(got) != want {
+		t.Errorf("MarshalJSON() got = %s, want %s", got, want)
+	}
+}
+
+func TestOnPropertyValue(t *testing.T) {
+	col := ItemCollection{
+		PropertyValueNew("Website", "https://example.com"),
+		PropertyValueNew("Pronouns", "they/them"),
+	}
+	names := make([]string, 0)
+	err := OnPropertyValue(col, func(p *PropertyValue) error {
+		names = append(names, p.Name.String())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("OnPropertyValue() error = %v", err)
+	}
+	if want := []string{"Website", "Pronouns"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("OnPropertyValue() visited %v, want %v", names, want)
+	}
+}
@@ -0,0 +1,194 @@
+package activitypub
+
+import (
+	"sync"
+	"time"
+
+	"github.com/go-ap/errors"
+)
+
+// MemoryStore is a Store which keeps its items in memory, and is safe for concurrent use.
+//
+// Items are identified by their exact IRI, so IRIs differing only in their fragment, or in the
+// case of their host, identify different items.
+//
+// The stored collections are never modified in place: AddTo and RemoveFrom replace them with
+// updated copies, so the items returned by Load can be safely read while the store is in use.
+type MemoryStore struct {
+	mu    sync.RWMutex
+	items map[IRI]Item
+}
+
+var _ Store = new(MemoryStore)
+
+// MemoryStoreNew initializes a MemoryStore containing the items
+func MemoryStoreNew(items ...Item) *MemoryStore {
+	m := MemoryStore{items: make(map[IRI]Item)}
+	for _, it := range items {
+		_, _ = m.Save(it)
+	}
+	return &m
+}
+
+func (m *MemoryStore) load(iri IRI) (Item, error) {
+	it, ok := m.items[iri]
+	if !ok {
+		return nil, errors.NotFoundf("%s not found", iri)
+	}
+	return it, nil
+}
+
+// Load returns the item identified by the iri
+func (m *MemoryStore) Load(iri IRI) (Item, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.load(iri)
+}
+
+func (m *MemoryStore) save(it Item) {
+	m.items[it.GetLink()] = it
+}
+
+// Save stores the "it" item, replacing any previous version of it.
+//
+// The collections of objects and actors which are embedded, instead of being referenced by their IRI,
+// are stored separately, so they can be modified with AddTo and RemoveFrom.
+func (m *MemoryStore) Save(it Item) (Item, error) {
+	if IsNil(it) {
+		return nil, errors.NotValidf("unable to save nil item")
+	}
+	if len(it.GetLink()) == 0 {
+		return nil, errors.NotValidf("unable to save %s item without an ID", it.GetType())
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	paths := append(CollectionPaths{}, OfObject...)
+	if ActorTypes.Contains(it.GetType()) {
+		paths = append(paths, OfActor...)
+	}
+	for _, path := range paths {
+		col := path.Of(it)
+		if IsNil(col) || col.IsLink() || !col.IsCollection() || len(col.GetLink()) == 0 {
+			continue
+		}
+		m.save(col)
+	}
+	m.save(it)
+	return it, nil
+}
+
+// Delete replaces the stored version of the "it" item with a Tombstone
+func (m *MemoryStore) Delete(it Item) (Item, error) {
+	if IsNil(it) {
+		return nil, errors.NotValidf("unable to delete nil item")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	old, err := m.load(it.GetLink())
+	if err != nil {
+		return nil, err
+	}
+	if old.GetType() == TombstoneType {
+		return old, nil
+	}
+	t := Tombstone{
+		ID:         old.GetLink(),
+		Type:       TombstoneType,
+		FormerType: old.GetType(),
+		Deleted:    time.Now().UTC(),
+	}
+	m.save(&t)
+	return &t, nil
+}
+
+// collectionNew returns a new OrderedCollection with the col IRI. If col corresponds to one of the
+// collections of a stored object or actor, the collection is attributed to it.
+func (m *MemoryStore) collectionNew(col IRI) *OrderedCollection {
+	c := OrderedCollectionNew(col)
+	owner, path := Split(col)
+	if path == Unknown {
+		return c
+	}
+	if ob, err := m.load(owner); err == nil {
+		if iri := path.Of(ob); !IsNil(iri) && iri.GetLink() == col {
+			c.AttributedTo = ob.GetLink()
+		}
+	}
+	return c
+}
+
+// updateCollection replaces the col collection with a copy having its items modified by fn.
+func (m *MemoryStore) updateCollection(col IRI, create bool, fn func(*ItemCollection)) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	it, err := m.load(col)
+	if err != nil {
+		if !create || !errors.IsNotFound(err) {
+			return err
+		}
+		it = m.collectionNew(col)
+	}
+	switch c := it.(type) {
+	case *OrderedCollection:
+		cc := *c
+		cc.OrderedItems = append(make(ItemCollection, 0, len(c.OrderedItems)), c.OrderedItems...)
+		fn(&cc.OrderedItems)
+		cc.TotalItems = cc.OrderedItems.Count()
+		m.save(&cc)
+	case *Collection:
+		cc := *c
+		cc.Items = append(make(ItemCollection, 0, len(c.Items)), c.Items...)
+		fn(&cc.Items)
+		cc.TotalItems = cc.Items.Count()
+		m.save(&cc)
+	default:
+		return errors.NotValidf("%s[%s] is not a collection", col, it.GetType())
+	}
+	return nil
+}
+
+// AddTo appends the items to the col collection, which is created if it doesn't exist.
+// Items already in the collection are not added again.
+func (m *MemoryStore) AddTo(col IRI, items ...Item) error {
+	return m.updateCollection(col, true, func(c *ItemCollection) {
+		for _, it := range items {
+			if IsNil(it) || c.Contains(it.GetLink()) {
+				continue
+			}
+			*c = append(*c, it.GetLink())
+		}
+	})
+}
+
+// RemoveFrom removes the items from the col collection
+func (m *MemoryStore) RemoveFrom(col IRI, items ...Item) error {
+	return m.updateCollection(col, false, func(c *ItemCollection) {
+		for _, it := range items {
+			if !IsNil(it) {
+				c.Remove(it.GetLink())
+			}
+		}
+	})
+}
+
+// Page returns a page of the col collection containing at most count items, starting with the
+// one at the offset position. The page is built by Paginate, so it links to the previous and
+// next pages, if they exist.
+func (m *MemoryStore) Page(col IRI, offset, count int) (CollectionInterface, error) {
+	if offset < 0 || count <= 0 {
+		return nil, errors.NotValidf("invalid page offset %d and count %d", offset, count)
+	}
+	m.mu.RLock()
+	it, err := m.load(col)
+	m.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	if c, ok := it.(CollectionInterface); ok && (c.GetType() == OrderedCollectionType || c.GetType() == CollectionType) {
+		return Paginate(c, Cursor{Offset: offset, Count: count})
+	}
+	return nil, errors.NotValidf("%s[%s] is not a collection", col, it.GetType())
+}
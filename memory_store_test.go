@@ -0,0 +1,231 @@
+package activitypub
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/go-ap/errors"
+)
+
+func TestMemoryStore_Load(t *testing.T) {
+	note := &Object{ID: "https://example.com/notes/1", Type: NoteType}
+	s := MemoryStoreNew(note)
+
+	tests := []struct {
+		name    string
+		iri     IRI
+		want    Item
+		wantErr bool
+	}{
+		{
+			name: "exact IRI",
+			iri:  note.ID,
+			want: note,
+		},
+		{
+			name:    "IRI with fragment",
+			iri:     "https://example.com/notes/1#main",
+			wantErr: true,
+		},
+		{
+			name:    "IRI with different host case",
+			iri:     "https://EXAMPLE.com/notes/1",
+			wantErr: true,
+		},
+		{
+			name:    "IRI with different scheme",
+			iri:     "http://example.com/notes/1",
+			wantErr: true,
+		},
+		{
+			name:    "unknown IRI",
+			iri:     "https://example.com/notes/2",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.Load(tt.iri)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.IsNotFound(err) {
+				t.Errorf("Load() error = %v, want a not found error", err)
+			}
+			if got != tt.want {
+				t.Errorf("Load() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryStore_Save(t *testing.T) {
+	alice := PersonNew("https://example.com/~alice")
+	alice.Followers = &OrderedCollection{
+		ID:           "https://example.com/~alice/followers",
+		Type:         OrderedCollectionType,
+		OrderedItems: ItemCollection{IRI("https://example.com/~bob")},
+	}
+	s := MemoryStoreNew()
+	if _, err := s.Save(alice); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if _, err := s.Save(&Object{Type: NoteType}); err == nil {
+		t.Errorf("Save() of an item without ID should fail")
+	}
+	if got, err := s.Load(alice.Followers.GetLink()); err != nil || got != alice.Followers {
+		t.Errorf("Save() should store the embedded followers collection, got %v, %v", got, err)
+	}
+}
+
+func TestMemoryStore_SaveFragment(t *testing.T) {
+	bob := PersonNew("https://example.com/users/bob")
+	like := &Activity{ID: "https://example.com/users/bob#likes/1", Type: LikeType, Actor: bob.GetLink()}
+	s := MemoryStoreNew(bob)
+	if _, err := s.Save(like); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if got, err := s.Load(bob.ID); err != nil || got != bob {
+		t.Errorf("Load(%s) = %v, %v, want %v", bob.ID, got, err, bob)
+	}
+	if got, err := s.Load(like.ID); err != nil || got != like {
+		t.Errorf("Load(%s) = %v, %v, want %v", like.ID, got, err, like)
+	}
+}
+
+func TestMemoryStore_Delete(t *testing.T) {
+	note := &Object{ID: "https://example.com/notes/1", Type: NoteType}
+	s := MemoryStoreNew(note)
+
+	if _, err := s.Delete(IRI("https://example.com/notes/2")); !errors.IsNotFound(err) {
+		t.Errorf("Delete() error = %v, want a not found error", err)
+	}
+	got, err := s.Delete(note.GetLink())
+	if err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	loaded, _ := s.Load(note.ID)
+	if loaded != got {
+		t.Errorf("Delete() = %v, stored %v", got, loaded)
+	}
+	_ = OnTombstone(got, func(tomb *Tombstone) error {
+		if tomb.ID != note.ID || tomb.FormerType != NoteType || tomb.Deleted.IsZero() {
+			t.Errorf("Delete() = %#v, want a Tombstone of %s", tomb, note.ID)
+		}
+		return nil
+	})
+}
+
+func TestMemoryStore_AddTo(t *testing.T) {
+	alice := PersonNew("https://example.com/~alice")
+	bob := IRI("https://example.com/~bob")
+	s := MemoryStoreNew(alice)
+	followers := Followers.IRI(alice)
+
+	if err := s.AddTo(followers, bob, bob); err != nil {
+		t.Fatalf("AddTo() error = %v", err)
+	}
+	before, _ := s.Load(followers)
+	if err := s.AddTo(followers, IRI("https://example.com/~jane")); err != nil {
+		t.Fatalf("AddTo() error = %v", err)
+	}
+	after, _ := s.Load(followers)
+
+	_ = OnOrderedCollection(before, func(c *OrderedCollection) error {
+		if c.TotalItems != 1 || !c.OrderedItems.Contains(bob) {
+			t.Errorf("AddTo() = %v, want only %s", c.OrderedItems, bob)
+		}
+		if !c.AttributedTo.GetLink().Equals(alice.GetLink(), true) {
+			t.Errorf("AddTo() collection attributedTo = %v, want %s", c.AttributedTo, alice.GetLink())
+		}
+		return nil
+	})
+	_ = OnOrderedCollection(after, func(c *OrderedCollection) error {
+		if c.TotalItems != 2 {
+			t.Errorf("AddTo() = %v, want 2 items", c.OrderedItems)
+		}
+		return nil
+	})
+
+	if err := s.RemoveFrom(followers, bob); err != nil {
+		t.Fatalf("RemoveFrom() error = %v", err)
+	}
+	if err := s.RemoveFrom("https://example.com/~alice/following", bob); !errors.IsNotFound(err) {
+		t.Errorf("RemoveFrom() error = %v, want a not found error", err)
+	}
+	if err := s.AddTo(alice.GetLink(), bob); err == nil {
+		t.Errorf("AddTo() on an actor should fail")
+	}
+}
+
+func TestMemoryStore_Page(t *testing.T) {
+	col := IRI("https://example.com/~alice/outbox")
+	s := MemoryStoreNew()
+	for i := 0; i < 5; i++ {
+		_ = s.AddTo(col, IRI(fmt.Sprintf("https://example.com/activities/%d", i)))
+	}
+
+	tests := []struct {
+		name     string
+		offset   int
+		count    int
+		want     int
+		wantPrev bool
+		wantNext bool
+		wantErr  bool
+	}{
+		{name: "first page", offset: 0, count: 2, want: 2, wantNext: true},
+		{name: "middle page", offset: 2, count: 2, want: 2, wantPrev: true, wantNext: true},
+		{name: "last page", offset: 4, count: 2, want: 1, wantPrev: true},
+		{name: "past the end", offset: 10, count: 2, want: 0, wantPrev: true},
+		{name: "invalid count", offset: 0, count: 0, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.Page(col, tt.offset, tt.count)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Page() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			_ = OnOrderedCollectionPage(got, func(p *OrderedCollectionPage) error {
+				if len(p.OrderedItems) != tt.want {
+					t.Errorf("Page() items = %v, want %d", p.OrderedItems, tt.want)
+				}
+				if !p.PartOf.GetLink().Equals(col, true) || p.StartIndex != uint(tt.offset) {
+					t.Errorf("Page() partOf = %v, startIndex = %d", p.PartOf, p.StartIndex)
+				}
+				if IsNil(p.Prev) == tt.wantPrev || IsNil(p.Next) == tt.wantNext {
+					t.Errorf("Page() prev = %v, next = %v", p.Prev, p.Next)
+				}
+				return nil
+			})
+		})
+	}
+}
+
+func TestMemoryStore_Concurrent(t *testing.T) {
+	col := IRI("https://example.com/~alice/inbox")
+	s := MemoryStoreNew()
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_ = s.AddTo(col, IRI(fmt.Sprintf("https://example.com/activities/%d", i)))
+			_, _ = s.Page(col, 0, 10)
+		}(i)
+	}
+	wg.Wait()
+
+	got, _ := s.Load(col)
+	_ = OnOrderedCollection(got, func(c *OrderedCollection) error {
+		if c.TotalItems != 20 {
+			t.Errorf("concurrent AddTo() = %d items, want 20", c.TotalItems)
+		}
+		return nil
+	})
+}
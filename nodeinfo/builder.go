@@ -0,0 +1,82 @@
+package nodeinfo
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-ap/errors"
+)
+
+// CounterFn returns one of the usage statistics of the server, eg: the number of local posts
+type CounterFn func(ctx context.Context) (int64, error)
+
+// Counters are the functions the Builder uses for filling the usage statistics.
+// The statistics whose counter is nil are left as 0.
+type Counters struct {
+	Users          CounterFn
+	ActiveMonth    CounterFn
+	ActiveHalfyear CounterFn
+	LocalPosts     CounterFn
+	LocalComments  CounterFn
+}
+
+// Builder creates the NodeInfo documents describing the local server
+type Builder struct {
+	Software          Software
+	Protocols         []string
+	Services          Services
+	OpenRegistrations bool
+	Metadata          map[string]any
+	Counters          Counters
+}
+
+// Build returns the NodeInfo document for the received schema version, with the usage statistics
+// filled using the builder's counters. When no protocols were set, the document lists ActivityPub.
+func (b Builder) Build(ctx context.Context, version string) (*NodeInfo, error) {
+	if version != Version20 && version != Version21 {
+		return nil, errors.NotSupportedf("unsupported NodeInfo version %q", version)
+	}
+	sw := b.Software
+	sw.Name = strings.ToLower(sw.Name)
+	if !validSoftwareName.MatchString(sw.Name) {
+		return nil, errors.NotValidf("invalid software name %q", b.Software.Name)
+	}
+	if version == Version20 {
+		sw.Repository, sw.Homepage = "", ""
+	}
+	protocols := b.Protocols
+	if len(protocols) == 0 {
+		protocols = []string{ProtocolActivityPub}
+	}
+
+	n := NodeInfo{
+		Version:           version,
+		Software:          sw,
+		Protocols:         protocols,
+		Services:          b.Services,
+		OpenRegistrations: b.OpenRegistrations,
+		Metadata:          b.Metadata,
+	}
+	counters := []struct {
+		name string
+		fn   CounterFn
+		into *int64
+	}{
+		{name: "users", fn: b.Counters.Users, into: &n.Usage.Users.Total},
+		{name: "activeMonth", fn: b.Counters.ActiveMonth, into: &n.Usage.Users.ActiveMonth},
+		{name: "activeHalfyear", fn: b.Counters.ActiveHalfyear, into: &n.Usage.Users.ActiveHalfyear},
+		{name: "localPosts", fn: b.Counters.LocalPosts, into: &n.Usage.LocalPosts},
+		{name: "localComments", fn: b.Counters.LocalComments, into: &n.Usage.LocalComments},
+	}
+	for _, c := range counters {
+		if c.fn == nil {
+			continue
+		}
+		v, err := c.fn(ctx)
+		if err != nil {
+			return nil, errors.Annotatef(err, "unable to count %s", c.name)
+		}
+		*c.into = v
+	}
+	return &n, nil
+}
@@ -0,0 +1,80 @@
+package nodeinfo
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestBuilder_Build(t *testing.T) {
+	count := func(v int64) CounterFn {
+		return func(context.Context) (int64, error) { return v, nil }
+	}
+	b := Builder{
+		Software: Software{Name: "FedBOX", Version: "1.0.0", Homepage: "https://fedbox.git"},
+		Counters: Counters{Users: count(10), ActiveMonth: count(4), LocalPosts: count(100)},
+	}
+	tests := []struct {
+		name    string
+		b       Builder
+		version string
+		want    *NodeInfo
+		wantErr bool
+	}{
+		{
+			name:    "2.1",
+			b:       b,
+			version: Version21,
+			want: &NodeInfo{
+				Version:   Version21,
+				Software:  Software{Name: "fedbox", Version: "1.0.0", Homepage: "https://fedbox.git"},
+				Protocols: []string{ProtocolActivityPub},
+				Usage:     Usage{Users: Users{Total: 10, ActiveMonth: 4}, LocalPosts: 100},
+			},
+		},
+		{
+			name:    "2.0",
+			b:       b,
+			version: Version20,
+			want: &NodeInfo{
+				Version:   Version20,
+				Software:  Software{Name: "fedbox", Version: "1.0.0"},
+				Protocols: []string{ProtocolActivityPub},
+				Usage:     Usage{Users: Users{Total: 10, ActiveMonth: 4}, LocalPosts: 100},
+			},
+		},
+		{
+			name:    "unsupported version",
+			b:       b,
+			version: "1.0",
+			wantErr: true,
+		},
+		{
+			name:    "invalid software name",
+			b:       Builder{Software: Software{Name: "Fed Box"}},
+			version: Version21,
+			wantErr: true,
+		},
+		{
+			name: "failing counter",
+			b: Builder{
+				Software: Software{Name: "fedbox"},
+				Counters: Counters{LocalComments: func(context.Context) (int64, error) { return 0, errors.New("storage offline") }},
+			},
+			version: Version21,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.b.Build(context.Background(), tt.version)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Build() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Build() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package nodeinfo
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-ap/errors"
+	vocab "github.com/snoymy/activitypub"
+	"github.com/valyala/fastjson"
+)
+
+const (
+	// WellKnownPath is the path of the discovery document listing the NodeInfo documents of a server
+	WellKnownPath = "/.well-known/nodeinfo"
+
+	// SchemaRel20 is the link relation of NodeInfo 2.0 documents
+	SchemaRel20 = "http://nodeinfo.diaspora.software/ns/schema/2.0"
+	// SchemaRel21 is the link relation of NodeInfo 2.1 documents
+	SchemaRel21 = "http://nodeinfo.diaspora.software/ns/schema/2.1"
+)
+
+// Link points to a NodeInfo document, its relation identifies the version of the schema
+type Link struct {
+	Rel  string
+	Href vocab.IRI
+}
+
+// Version returns the schema version of the linked document, or an empty string if the relation is unknown
+func (l Link) Version() string {
+	switch strings.TrimRight(l.Rel, "#/") {
+	case SchemaRel20:
+		return Version20
+	case SchemaRel21:
+		return Version21
+	}
+	return ""
+}
+
+// WellKnown represents the discovery document served at WellKnownPath
+type WellKnown struct {
+	Links []Link
+}
+
+// WellKnownNew returns the discovery document linking the NodeInfo documents for the received versions,
+// which are expected to be published at base followed by the version, eg: "https://example.com/nodeinfo/2.1"
+func WellKnownNew(base vocab.IRI, versions ...string) WellKnown {
+	w := WellKnown{}
+	for _, v := range versions {
+		var rel string
+		switch v {
+		case Version20:
+			rel = SchemaRel20
+		case Version21:
+			rel = SchemaRel21
+		default:
+			continue
+		}
+		w.Links = append(w.Links, Link{Rel: rel, Href: base.AddPath(v)})
+	}
+	return w
+}
+
+// Best returns the link to the document with the most recent schema version we support
+func (w WellKnown) Best() (Link, bool) {
+	best, found := Link{}, false
+	for _, l := range w.Links {
+		v := l.Version()
+		if len(v) == 0 || len(l.Href) == 0 {
+			continue
+		}
+		if !found || v > best.Version() {
+			best, found = l, true
+		}
+	}
+	return best, found
+}
+
+// MarshalJSON encodes the receiver object to a JSON document.
+func (w WellKnown) MarshalJSON() ([]byte, error) {
+	b := make([]byte, 0)
+	vocab.JSONWrite(&b, '{')
+	l := make([]byte, 0)
+	vocab.JSONWrite(&l, '[')
+	for i, link := range w.Links {
+		if i > 0 {
+			vocab.JSONWrite(&l, ',')
+		}
+		v := make([]byte, 0)
+		vocab.JSONWrite(&v, '{')
+		vocab.JSONWriteStringProp(&v, "rel", link.Rel)
+		vocab.JSONWriteIRIProp(&v, "href", link.Href)
+		vocab.JSONWrite(&v, '}')
+		vocab.JSONWrite(&l, v...)
+	}
+	vocab.JSONWrite(&l, ']')
+	vocab.JSONWriteProp(&b, "links", l)
+	vocab.JSONWrite(&b, '}')
+	return b, nil
+}
+
+// UnmarshalJSON decodes an incoming JSON document into the receiver object.
+func (w *WellKnown) UnmarshalJSON(data []byte) error {
+	p := fastjson.Parser{}
+	val, err := p.ParseBytes(data)
+	if err != nil {
+		return err
+	}
+	if val.Type() != fastjson.TypeObject {
+		return errors.NotValidf("NodeInfo discovery document must be a JSON object")
+	}
+	w.Links = nil
+	for _, v := range val.GetArray("links") {
+		w.Links = append(w.Links, Link{Rel: vocab.JSONGetString(v, "rel"), Href: vocab.JSONGetIRI(v, "href")})
+	}
+	return nil
+}
+
+// Client is the interface of the HTTP client used for discovery, it is satisfied by *http.Client
+type Client interface {
+	Do(*http.Request) (*http.Response, error)
+}
+
+// Discover fetches the discovery document of the host, and then the most recent NodeInfo document it links to.
+// If cl is nil http.DefaultClient is used.
+func Discover(ctx context.Context, cl Client, host string) (*NodeInfo, error) {
+	if cl == nil {
+		cl = http.DefaultClient
+	}
+	w := WellKnown{}
+	if err := fetch(ctx, cl, "https://"+host+WellKnownPath, &w); err != nil {
+		return nil, err
+	}
+	l, ok := w.Best()
+	if !ok {
+		return nil, errors.NotFoundf("no supported NodeInfo document for %s", host)
+	}
+	n := NodeInfo{}
+	if err := fetch(ctx, cl, l.Href.String(), &n); err != nil {
+		return nil, err
+	}
+	if len(n.Version) == 0 {
+		n.Version = l.Version()
+	}
+	return &n, nil
+}
+
+func fetch(ctx context.Context, cl Client, u string, into interface{ UnmarshalJSON([]byte) error }) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return errors.Annotatef(err, "unable to build request for %s", u)
+	}
+	req.Header.Set("Accept", "application/json")
+	res, err := cl.Do(req)
+	if err != nil {
+		return errors.Annotatef(err, "unable to fetch %s", u)
+	}
+	defer res.Body.Close()
+
+	switch {
+	case res.StatusCode == http.StatusNotFound:
+		return errors.NotFoundf("%s not found", u)
+	case res.StatusCode != http.StatusOK:
+		return errors.Newf("unexpected status %d for %s", res.StatusCode, u)
+	}
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return errors.Annotatef(err, "unable to read %s", u)
+	}
+	if err = into.UnmarshalJSON(data); err != nil {
+		return errors.NewNotValid(err, "invalid document at %s", u)
+	}
+	return nil
+}
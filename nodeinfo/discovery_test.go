@@ -0,0 +1,98 @@
+package nodeinfo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	vocab "github.com/snoymy/activitypub"
+)
+
+func TestWellKnown_Best(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		want   Link
+		wantOk bool
+	}{
+		{
+			name:   "2.0 and 2.1",
+			data:   `{"links":[{"rel":"http://nodeinfo.diaspora.software/ns/schema/2.0","href":"https://example.com/nodeinfo/2.0"},{"rel":"http://nodeinfo.diaspora.software/ns/schema/2.1","href":"https://example.com/nodeinfo/2.1"}]}`,
+			want:   Link{Rel: SchemaRel21, Href: "https://example.com/nodeinfo/2.1"},
+			wantOk: true,
+		},
+		{
+			name:   "only 2.0",
+			data:   `{"links":[{"rel":"http://nodeinfo.diaspora.software/ns/schema/2.0","href":"https://example.com/nodeinfo/2.0"}]}`,
+			want:   Link{Rel: SchemaRel20, Href: "https://example.com/nodeinfo/2.0"},
+			wantOk: true,
+		},
+		{
+			name: "unsupported version",
+			data: `{"links":[{"rel":"http://nodeinfo.diaspora.software/ns/schema/1.1","href":"https://example.com/nodeinfo/1.1"}]}`,
+		},
+		{
+			name: "no links",
+			data: `{}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := WellKnown{}
+			if err := w.UnmarshalJSON([]byte(tt.data)); err != nil {
+				t.Fatalf("UnmarshalJSON() error = %s", err)
+			}
+			got, ok := w.Best()
+			if ok != tt.wantOk || got != tt.want {
+				t.Errorf("Best() = %v, %t, want %v, %t", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestWellKnownNew(t *testing.T) {
+	w := WellKnownNew("https://example.com/nodeinfo/", Version20, Version21, "3.0")
+	want := `{"links":[{"rel":"http://nodeinfo.diaspora.software/ns/schema/2.0","href":"https://example.com/nodeinfo/2.0"},{"rel":"http://nodeinfo.diaspora.software/ns/schema/2.1","href":"https://example.com/nodeinfo/2.1"}]}`
+	got, _ := w.MarshalJSON()
+	if string(got) != want {
+		t.Errorf("WellKnownNew() = %s, want %s", got, want)
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	var base string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case WellKnownPath:
+			data, _ := WellKnownNew(vocab.IRI(base+"/nodeinfo"), Version20).MarshalJSON()
+			_, _ = w.Write(data)
+		case "/nodeinfo/2.0":
+			_, _ = w.Write([]byte(mastodonNodeInfo))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	base = srv.URL
+
+	got, err := Discover(context.Background(), srv.Client(), strings.TrimPrefix(srv.URL, "https://"))
+	if err != nil {
+		t.Fatalf("Discover() error = %s", err)
+	}
+	want := &NodeInfo{
+		Version:   Version20,
+		Software:  Software{Name: "mastodon", Version: "4.2.1"},
+		Protocols: []string{ProtocolActivityPub},
+		Usage:     Usage{Users: Users{Total: 12, ActiveMonth: 5, ActiveHalfyear: 9}, LocalPosts: 1234},
+		Metadata:  map[string]any{"nodeName": "Example"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Discover() = %#v, want %#v", got, want)
+	}
+	if !got.SupportsActivityPub() {
+		t.Errorf("SupportsActivityPub() = false, want true")
+	}
+}
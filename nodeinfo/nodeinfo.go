@@ -0,0 +1,215 @@
+// Package nodeinfo implements the NodeInfo documents servers use for publishing the software
+// they run, the protocols they support and their usage statistics.
+//
+// https://nodeinfo.diaspora.software/protocol
+package nodeinfo
+
+import (
+	"encoding/json"
+	"regexp"
+
+	"github.com/go-ap/errors"
+	vocab "github.com/snoymy/activitypub"
+	"github.com/valyala/fastjson"
+)
+
+const (
+	// Version20 is the 2.0 version of the NodeInfo schema
+	Version20 = "2.0"
+	// Version21 is the 2.1 version of the NodeInfo schema, which adds the repository and homepage of the software
+	Version21 = "2.1"
+
+	// ProtocolActivityPub is the name of the ActivityPub protocol in the NodeInfo "protocols" list
+	ProtocolActivityPub = "activitypub"
+)
+
+// validSoftwareName is the pattern the software name must match according to the schema
+var validSoftwareName = regexp.MustCompile(`^[a-z0-9-]+$`)
+
+// Software describes the server software
+type Software struct {
+	// Name is the canonical name of the software, eg: "mastodon", "pleroma", "misskey"
+	Name    string
+	Version string
+	// Repository is the URL of the source code repository, only present in NodeInfo 2.1
+	Repository vocab.IRI
+	// Homepage is the URL of the software's homepage, only present in NodeInfo 2.1
+	Homepage vocab.IRI
+}
+
+// Services lists the third party sites the server can retrieve messages from or publish messages to
+type Services struct {
+	Inbound  []string
+	Outbound []string
+}
+
+// Users holds the user statistics of the server
+type Users struct {
+	Total          int64
+	ActiveMonth    int64
+	ActiveHalfyear int64
+}
+
+// Usage holds the usage statistics of the server
+type Usage struct {
+	Users         Users
+	LocalPosts    int64
+	LocalComments int64
+}
+
+// NodeInfo represents a NodeInfo 2.0 or 2.1 document
+type NodeInfo struct {
+	Version           string
+	Software          Software
+	Protocols         []string
+	Services          Services
+	OpenRegistrations bool
+	Usage             Usage
+	// Metadata holds the free form "metadata" object, as decoded by encoding/json
+	Metadata map[string]any
+}
+
+// MarshalJSON encodes the receiver object to a JSON document.
+// The properties which are not part of the document's schema version are omitted.
+func (n NodeInfo) MarshalJSON() ([]byte, error) {
+	version := n.Version
+	if len(version) == 0 {
+		version = Version21
+	}
+	b := make([]byte, 0)
+	vocab.JSONWrite(&b, '{')
+	vocab.JSONWriteStringProp(&b, "version", version)
+
+	s := make([]byte, 0)
+	vocab.JSONWrite(&s, '{')
+	vocab.JSONWriteStringProp(&s, "name", n.Software.Name)
+	vocab.JSONWriteStringProp(&s, "version", n.Software.Version)
+	if version != Version20 {
+		if len(n.Software.Repository) > 0 {
+			vocab.JSONWriteIRIProp(&s, "repository", n.Software.Repository)
+		}
+		if len(n.Software.Homepage) > 0 {
+			vocab.JSONWriteIRIProp(&s, "homepage", n.Software.Homepage)
+		}
+	}
+	vocab.JSONWrite(&s, '}')
+	vocab.JSONWriteProp(&b, "software", s)
+
+	// the schema requires the protocols and services properties, even when they're empty
+	vocab.JSONWriteProp(&b, "protocols", jsonStringArray(n.Protocols))
+	sv := make([]byte, 0)
+	vocab.JSONWrite(&sv, '{')
+	vocab.JSONWriteProp(&sv, "inbound", jsonStringArray(n.Services.Inbound))
+	vocab.JSONWriteProp(&sv, "outbound", jsonStringArray(n.Services.Outbound))
+	vocab.JSONWrite(&sv, '}')
+	vocab.JSONWriteProp(&b, "services", sv)
+
+	vocab.JSONWriteBoolProp(&b, "openRegistrations", n.OpenRegistrations)
+
+	u := make([]byte, 0)
+	vocab.JSONWrite(&u, '{')
+	uu := make([]byte, 0)
+	vocab.JSONWrite(&uu, '{')
+	vocab.JSONWriteIntProp(&uu, "total", n.Usage.Users.Total)
+	vocab.JSONWriteIntProp(&uu, "activeMonth", n.Usage.Users.ActiveMonth)
+	vocab.JSONWriteIntProp(&uu, "activeHalfyear", n.Usage.Users.ActiveHalfyear)
+	vocab.JSONWrite(&uu, '}')
+	vocab.JSONWriteProp(&u, "users", uu)
+	vocab.JSONWriteIntProp(&u, "localPosts", n.Usage.LocalPosts)
+	vocab.JSONWriteIntProp(&u, "localComments", n.Usage.LocalComments)
+	vocab.JSONWrite(&u, '}')
+	vocab.JSONWriteProp(&b, "usage", u)
+
+	m := []byte("{}")
+	if len(n.Metadata) > 0 {
+		var err error
+		if m, err = json.Marshal(n.Metadata); err != nil {
+			return nil, errors.Annotatef(err, "unable to encode NodeInfo metadata")
+		}
+	}
+	vocab.JSONWriteProp(&b, "metadata", m)
+
+	vocab.JSONWrite(&b, '}')
+	return b, nil
+}
+
+// UnmarshalJSON decodes an incoming JSON document into the receiver object.
+func (n *NodeInfo) UnmarshalJSON(data []byte) error {
+	p := fastjson.Parser{}
+	val, err := p.ParseBytes(data)
+	if err != nil {
+		return err
+	}
+	return JSONLoadNodeInfo(val, n)
+}
+
+// JSONLoadNodeInfo loads the properties of val into n
+func JSONLoadNodeInfo(val *fastjson.Value, n *NodeInfo) error {
+	if val.Type() != fastjson.TypeObject {
+		return errors.NotValidf("NodeInfo document must be a JSON object")
+	}
+	n.Version = vocab.JSONGetString(val, "version")
+	if sv := val.Get("software"); sv != nil {
+		n.Software.Name = vocab.JSONGetString(sv, "name")
+		n.Software.Version = vocab.JSONGetString(sv, "version")
+		n.Software.Repository = vocab.JSONGetIRI(sv, "repository")
+		n.Software.Homepage = vocab.JSONGetIRI(sv, "homepage")
+	}
+	n.Protocols = jsonGetStrings(val, "protocols")
+	n.Services.Inbound = jsonGetStrings(val, "services", "inbound")
+	n.Services.Outbound = jsonGetStrings(val, "services", "outbound")
+	n.OpenRegistrations = vocab.JSONGetBoolean(val, "openRegistrations")
+	if uv := val.Get("usage"); uv != nil {
+		if users := uv.Get("users"); users != nil {
+			n.Usage.Users.Total = vocab.JSONGetInt(users, "total")
+			n.Usage.Users.ActiveMonth = vocab.JSONGetInt(users, "activeMonth")
+			n.Usage.Users.ActiveHalfyear = vocab.JSONGetInt(users, "activeHalfyear")
+		}
+		n.Usage.LocalPosts = vocab.JSONGetInt(uv, "localPosts")
+		n.Usage.LocalComments = vocab.JSONGetInt(uv, "localComments")
+	}
+	if ob := val.GetObject("metadata"); ob != nil && ob.Len() > 0 {
+		n.Metadata = make(map[string]any, ob.Len())
+		if err := json.Unmarshal(ob.MarshalTo(nil), &n.Metadata); err != nil {
+			return errors.Annotatef(err, "unable to decode NodeInfo metadata")
+		}
+	}
+	return nil
+}
+
+// SupportsActivityPub returns true if the node lists ActivityPub among its protocols
+func (n NodeInfo) SupportsActivityPub() bool {
+	for _, p := range n.Protocols {
+		if p == ProtocolActivityPub {
+			return true
+		}
+	}
+	return false
+}
+
+func jsonStringArray(s []string) []byte {
+	b := make([]byte, 0)
+	vocab.JSONWrite(&b, '[')
+	for i, v := range s {
+		if i > 0 {
+			vocab.JSONWrite(&b, ',')
+		}
+		vocab.JSONWriteStringValue(&b, v)
+	}
+	vocab.JSONWrite(&b, ']')
+	return b
+}
+
+func jsonGetStrings(val *fastjson.Value, path ...string) []string {
+	arr := val.GetArray(path...)
+	if len(arr) == 0 {
+		return nil
+	}
+	s := make([]string, 0, len(arr))
+	for _, v := range arr {
+		if sb, err := v.StringBytes(); err == nil {
+			s = append(s, string(sb))
+		}
+	}
+	return s
+}
@@ -0,0 +1,118 @@
+package nodeinfo
+
+import (
+	"reflect"
+	"testing"
+)
+
+const mastodonNodeInfo = `{"version":"2.0","software":{"name":"mastodon","version":"4.2.1"},"protocols":["activitypub"],"services":{"outbound":[],"inbound":[]},"usage":{"users":{"total":12,"activeMonth":5,"activeHalfyear":9},"localPosts":1234},"openRegistrations":false,"metadata":{"nodeName":"Example"}}`
+
+func TestNodeInfo_MarshalJSON(t *testing.T) {
+	software := Software{
+		Name:       "fedbox",
+		Version:    "1.0.0",
+		Repository: "https://git.sr.ht/~mariusor/fedbox",
+		Homepage:   "https://fedbox.git",
+	}
+	tests := []struct {
+		name string
+		n    NodeInfo
+		want string
+	}{
+		{
+			name: "2.1",
+			n: NodeInfo{
+				Version:           Version21,
+				Software:          software,
+				Protocols:         []string{ProtocolActivityPub},
+				OpenRegistrations: true,
+				Usage:             Usage{Users: Users{Total: 3, ActiveMonth: 2, ActiveHalfyear: 3}, LocalPosts: 42},
+				Metadata:          map[string]any{"nodeName": "Example"},
+			},
+			want: `{"version":"2.1","software":{"name":"fedbox","version":"1.0.0","repository":"https://git.sr.ht/~mariusor/fedbox","homepage":"https://fedbox.git"},"protocols":["activitypub"],"services":{"inbound":[],"outbound":[]},"openRegistrations":true,"usage":{"users":{"total":3,"activeMonth":2,"activeHalfyear":3},"localPosts":42,"localComments":0},"metadata":{"nodeName":"Example"}}`,
+		},
+		{
+			name: "2.0 omits repository and homepage",
+			n: NodeInfo{
+				Version:  Version20,
+				Software: software,
+			},
+			want: `{"version":"2.0","software":{"name":"fedbox","version":"1.0.0"},"protocols":[],"services":{"inbound":[],"outbound":[]},"openRegistrations":false,"usage":{"users":{"total":0,"activeMonth":0,"activeHalfyear":0},"localPosts":0,"localComments":0},"metadata":{}}`,
+		},
+		{
+			name: "metadata with AS2 property names",
+			n: NodeInfo{
+				Version:  Version20,
+				Software: software,
+				Metadata: map[string]any{"name": "fedbox", "summary": "test", "nodeName": "Example"},
+			},
+			want: `{"version":"2.0","software":{"name":"fedbox","version":"1.0.0"},"protocols":[],"services":{"inbound":[],"outbound":[]},"openRegistrations":false,"usage":{"users":{"total":0,"activeMonth":0,"activeHalfyear":0},"localPosts":0,"localComments":0},"metadata":{"name":"fedbox","nodeName":"Example","summary":"test"}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.n.MarshalJSON()
+			if err != nil {
+				t.Fatalf("MarshalJSON() error = %s", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("MarshalJSON() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNodeInfo_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    NodeInfo
+		wantErr bool
+	}{
+		{
+			name: "mastodon",
+			data: mastodonNodeInfo,
+			want: NodeInfo{
+				Version:   Version20,
+				Software:  Software{Name: "mastodon", Version: "4.2.1"},
+				Protocols: []string{ProtocolActivityPub},
+				Usage:     Usage{Users: Users{Total: 12, ActiveMonth: 5, ActiveHalfyear: 9}, LocalPosts: 1234},
+				Metadata:  map[string]any{"nodeName": "Example"},
+			},
+		},
+		{
+			name: "pleroma metadata",
+			data: `{"version":"2.1","software":{"name":"pleroma","version":"2.6.0"},"protocols":["activitypub"],"openRegistrations":true,"metadata":{"nodeName":"Example","name":"example","summary":"An example instance","features":["mastodon_api"],"federation":{"enabled":true}}}`,
+			want: NodeInfo{
+				Version:           Version21,
+				Software:          Software{Name: "pleroma", Version: "2.6.0"},
+				Protocols:         []string{ProtocolActivityPub},
+				OpenRegistrations: true,
+				Metadata: map[string]any{
+					"nodeName":   "Example",
+					"name":       "example",
+					"summary":    "An example instance",
+					"features":   []any{"mastodon_api"},
+					"federation": map[string]any{"enabled": true},
+				},
+			},
+		},
+		{
+			name:    "not an object",
+			data:    `"2.1"`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NodeInfo{}
+			err := got.UnmarshalJSON([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UnmarshalJSON() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
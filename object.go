@@ -63,6 +63,10 @@ var ObjectTypes = ActivityVocabularyTypes{
 	RelationshipType,
 	TombstoneType,
 	VideoType,
+
+	EmojiType,
+	PropertyValueType,
+	IdentityProofType,
 }
 
 type (
@@ -135,7 +139,7 @@ func (a ActivityVocabularyType) MarshalBinary() ([]byte, error) {
 }
 
 type Objects interface {
-	Object | Tombstone | Place | Profile | Relationship |
+	Object | Tombstone | Place | Profile | Relationship | PropertyValue | IdentityProof |
 		Actors |
 		Activities |
 		IntransitiveActivities |
@@ -239,6 +243,8 @@ type Object struct {
 	// as a form of provenance, or to support future editing by clients.
 	// In general, clients do the conversion from source to content, not the other way around.
 	Source Source `jsonld:"source,omitempty"`
+
+	Extensions Extensions `jsonld:"-"`
 }
 
 // ObjectNew initializes a new Object
@@ -633,6 +639,14 @@ func ToObject(it Item) (*Object, error) {
 		return (*Object)(unsafe.Pointer(i)), nil
 	case Profile:
 		return (*Object)(unsafe.Pointer(&i)), nil
+	case *PropertyValue:
+		return (*Object)(unsafe.Pointer(i)), nil
+	case PropertyValue:
+		return (*Object)(unsafe.Pointer(&i)), nil
+	case *IdentityProof:
+		return (*Object)(unsafe.Pointer(i)), nil
+	case IdentityProof:
+		return (*Object)(unsafe.Pointer(&i)), nil
 	case *Relationship:
 		return (*Object)(unsafe.Pointer(i)), nil
 	case Relationship:
@@ -684,7 +684,7 @@ func TestObject_MarshalJSON(t *testing.T) {
 		ID           ID
 		Type         ActivityVocabularyType
 		Name         NaturalLanguageValues
-		Attachment   Item
+		Attachment   ItemCollection
 		AttributedTo Item
 		Audience     ItemCollection
 		Content      NaturalLanguageValues
@@ -815,10 +815,10 @@ func TestObject_MarshalJSON(t *testing.T) {
 		{
 			name: "Attachment",
 			fields: fields{
-				Attachment: &Object{
+				Attachment: ItemCollection{&Object{
 					ID:   "some example",
 					Type: VideoType,
-				},
+				}},
 			},
 			want:    []byte(`{"attachment":{"id":"some example","type":"Video"}}`),
 			wantErr: false,
@@ -986,7 +986,7 @@ func TestObject_Equals(t *testing.T) {
 		ID           ID
 		Type         ActivityVocabularyType
 		Name         NaturalLanguageValues
-		Attachment   Item
+		Attachment   ItemCollection
 		AttributedTo Item
 		Audience     ItemCollection
 		Content      NaturalLanguageValues
@@ -1099,7 +1099,7 @@ func TestObject_GobEncode(t *testing.T) {
 		ID           ID
 		Type         ActivityVocabularyType
 		Name         NaturalLanguageValues
-		Attachment   Item
+		Attachment   ItemCollection
 		AttributedTo Item
 		Audience     ItemCollection
 		Content      NaturalLanguageValues
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/gob"
 	"fmt"
+	"iter"
 	"time"
 	"unsafe"
 
@@ -105,6 +106,8 @@ type OrderedCollection struct {
 	// as a form of provenance, or to support future editing by clients.
 	// In general, clients do the conversion from source to content, not the other way around.
 	Source Source `jsonld:"source,omitempty"`
+
+	Extensions Extensions `jsonld:"-"`
 	// In a paged Collection, indicates the page that contains the most recently updated member items.
 	Current ObjectOrLink `jsonld:"current,omitempty"`
 	// In a paged Collection, indicates the furthest preceding page of items in the collection.
@@ -180,6 +183,11 @@ func (o OrderedCollection) Collection() ItemCollection {
 	return o.OrderedItems
 }
 
+// All returns an iterator over the OrderedCollection's items, in order
+func (o OrderedCollection) All() iter.Seq[Item] {
+	return o.OrderedItems.All()
+}
+
 // IsCollection returns true for OrderedCollection objects.
 func (o OrderedCollection) IsCollection() bool {
 	return true
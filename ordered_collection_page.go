@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/gob"
 	"fmt"
+	"iter"
 	"time"
 
 	"github.com/valyala/fastjson"
@@ -106,6 +107,8 @@ type OrderedCollectionPage struct {
 	// as a form of provenance, or to support future editing by clients.
 	// In general, clients do the conversion from source to content, not the other way around.
 	Source Source `jsonld:"source,omitempty"`
+
+	Extensions Extensions `jsonld:"-"`
 	// In a paged Collection, indicates the page that contains the most recently updated member items.
 	Current ObjectOrLink `jsonld:"current,omitempty"`
 	// In a paged Collection, indicates the furthest preceding page of items in the collection.
@@ -162,6 +165,11 @@ func (o OrderedCollectionPage) Collection() ItemCollection {
 	return o.OrderedItems
 }
 
+// All returns an iterator over the OrderedCollectionPage's items, in order
+func (o OrderedCollectionPage) All() iter.Seq[Item] {
+	return o.OrderedItems.All()
+}
+
 // Count returns the maximum between the length of Items in the collection page and its TotalItems property
 func (o *OrderedCollectionPage) Count() uint {
 	if o == nil {
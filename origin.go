@@ -0,0 +1,200 @@
+package activitypub
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-ap/errors"
+)
+
+// OriginReason describes why an item delivered to an inbox failed the origin verification
+type OriginReason string
+
+const (
+	// ActorMismatch means the actor of the activity is not the owner of the signing key
+	ActorMismatch OriginReason = "actor-mismatch"
+	// AttributionMismatch means the object is attributed to someone else than the owner of the signing key
+	AttributionMismatch OriginReason = "attribution-mismatch"
+	// HostMismatch means the ID of the activity, or of the object it creates, updates or deletes, is on
+	// a different host than the owner of the signing key
+	HostMismatch OriginReason = "host-mismatch"
+	// RefetchRequired means the embedded object is on a different host than the owner of the signing key,
+	// so it can't be trusted, and needs to be fetched from its origin
+	RefetchRequired OriginReason = "refetch-required"
+)
+
+// OriginError is a structured reason for an item failing the origin verification
+type OriginError struct {
+	Reason OriginReason
+	// IRI is the item, or the value of the property, which failed the verification
+	IRI IRI
+	// Owner is the owner of the signing key
+	Owner IRI
+}
+
+// Error returns a human readable description of the failed verification
+func (o OriginError) Error() string {
+	switch o.Reason {
+	case ActorMismatch:
+		return fmt.Sprintf("actor %s does not match the key owner %s", o.IRI, o.Owner)
+	case AttributionMismatch:
+		return fmt.Sprintf("%s is not attributed to the key owner %s", o.IRI, o.Owner)
+	case HostMismatch:
+		return fmt.Sprintf("%s is not on the same host as the key owner %s", o.IRI, o.Owner)
+	case RefetchRequired:
+		return fmt.Sprintf("%s needs to be fetched from its origin, it can't be trusted from %s", o.IRI, o.Owner)
+	}
+	return fmt.Sprintf("%s failed origin verification: %s", o.IRI, o.Reason)
+}
+
+// OriginVerifier checks that the activities delivered to an inbox were authored by the owner
+// of the key which signed the request delivering them.
+//
+// https://www.w3.org/TR/activitypub/#authentication-authorization
+//
+// The actor of the activity must be the owner of the key, the activity must be on the key owner's
+// host, and the objects of Create and Update activities must be attributed to the key owner.
+// The objects referenced by IRI in Create, Update and Delete activities must be on the key owner's host.
+// Embedded objects which are on another host are flagged with RefetchRequired, and should be
+// fetched from their origin instead of being trusted as delivered.
+//
+// It can be used as a Validator by itself, or as a rule of the DefaultValidator through its Check method.
+type OriginVerifier struct {
+	// Key is the public key which verified the signature of the delivery request
+	Key PublicKey
+}
+
+var _ Validator = OriginVerifier{}
+
+// OriginVerifierNew initializes an OriginVerifier for a request signed with the key
+func OriginVerifierNew(key PublicKey) OriginVerifier {
+	return OriginVerifier{Key: key}
+}
+
+// Validate verifies the origin of the incoming item, returning an OriginError for each failure
+func (o OriginVerifier) Validate(receiver IRI, incoming Item) (bool, ValidationErrors) {
+	errs := make(ValidationErrorList, 0)
+	errs.Add(o.Check(receiver, incoming))
+	if len(errs) > 0 {
+		return false, &errs
+	}
+	return true, nil
+}
+
+// Check verifies the origin of the incoming item, it can be used as a DefaultValidator rule
+func (o OriginVerifier) Check(_ IRI, incoming Item) error {
+	owner := o.Key.Owner
+	if len(owner) == 0 {
+		return errors.Unauthorizedf("missing owner for key %s", o.Key.ID)
+	}
+	if IsNil(incoming) || incoming.IsLink() {
+		return nil
+	}
+
+	errs := make(ValidationErrorList, 0)
+	typ := incoming.GetType()
+	if !ActivityTypes.Contains(typ) && !IntransitiveActivityTypes.Contains(typ) {
+		o.checkObject(&errs, incoming, true)
+		return errorsOrNil(errs)
+	}
+
+	if id := incoming.GetLink(); len(id) > 0 && !sameHost(id, owner) {
+		errs.Add(OriginError{Reason: HostMismatch, IRI: incoming.GetLink(), Owner: owner})
+	}
+	_ = OnIntransitiveActivity(incoming, func(act *IntransitiveActivity) error {
+		if IsNil(act.Actor) || !act.Actor.GetLink().Equals(owner, true) {
+			var actor IRI
+			if !IsNil(act.Actor) {
+				actor = act.Actor.GetLink()
+			}
+			errs.Add(OriginError{Reason: ActorMismatch, IRI: actor, Owner: owner})
+		}
+		return nil
+	})
+	if ActivityTypes.Contains(typ) {
+		_ = OnActivity(incoming, func(act *Activity) error {
+			attributed := typ == CreateType || typ == UpdateType
+			for _, ob := range objectsOf(act.Object) {
+				if IsNil(ob) {
+					continue
+				}
+				if ob.IsLink() {
+					// other activities can reference remote objects, like a Like or an Announce,
+					// but an actor can only create, update or delete objects on its own host
+					if iri := ob.GetLink(); (attributed || typ == DeleteType) && !sameHost(iri, owner) {
+						errs.Add(OriginError{Reason: HostMismatch, IRI: iri, Owner: owner})
+					}
+					continue
+				}
+				o.checkObject(&errs, ob, attributed)
+			}
+			return nil
+		})
+	}
+	return errorsOrNil(errs)
+}
+
+// checkObject verifies the "it" embedded object is on the key owner's host, and, if attributed is true,
+// that it's attributed to the key owner, and to no one else
+func (o OriginVerifier) checkObject(errs ValidationErrors, it Item, attributed bool) {
+	owner := o.Key.Owner
+	if iri := it.GetLink(); len(iri) > 0 && !sameHost(iri, owner) {
+		errs.Add(OriginError{Reason: RefetchRequired, IRI: iri, Owner: owner})
+		return
+	}
+	if !attributed {
+		return
+	}
+	_ = OnObject(it, func(ob *Object) error {
+		authors := objectsOf(ob.AttributedTo)
+		if len(authors) == 0 {
+			// an actor updating its own profile is the only object which doesn't need an author
+			if ob.GetLink().Equals(owner, true) {
+				return nil
+			}
+			errs.Add(OriginError{Reason: AttributionMismatch, IRI: ob.GetLink(), Owner: owner})
+			return nil
+		}
+		for _, author := range authors {
+			if !author.GetLink().Equals(owner, true) {
+				errs.Add(OriginError{Reason: AttributionMismatch, IRI: ob.GetLink(), Owner: owner})
+				return nil
+			}
+		}
+		return nil
+	})
+}
+
+// RefetchIRIs returns the IRIs of the embedded objects that were flagged with RefetchRequired in the errs
+func RefetchIRIs(errs ValidationErrors) IRIs {
+	if errs == nil {
+		return nil
+	}
+	var iris IRIs
+	for _, err := range errs.Errors() {
+		if oe, ok := err.(OriginError); ok && oe.Reason == RefetchRequired && !iris.Contains(oe.IRI) {
+			iris = append(iris, oe.IRI)
+		}
+	}
+	return iris
+}
+
+func errorsOrNil(errs ValidationErrorList) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return &errs
+}
+
+// sameHost returns true if the i1 and i2 IRIs are on the same host
+func sameHost(i1, i2 IRI) bool {
+	u1, err := i1.URL()
+	if err != nil {
+		return false
+	}
+	u2, err := i2.URL()
+	if err != nil {
+		return false
+	}
+	return len(u1.Host) > 0 && strings.EqualFold(u1.Hostname(), u2.Hostname())
+}
@@ -0,0 +1,172 @@
+package activitypub
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOriginVerifier_Validate(t *testing.T) {
+	alice := IRI("https://example.com/~alice")
+	key := PublicKey{ID: "https://example.com/~alice#main-key", Owner: alice}
+
+	tests := []struct {
+		name    string
+		key     PublicKey
+		it      Item
+		reasons []OriginReason
+		refetch IRIs
+	}{
+		{
+			name: "create by the key owner",
+			key:  key,
+			it: &Activity{
+				ID:     "https://example.com/~alice/activities/1",
+				Type:   CreateType,
+				Actor:  alice,
+				Object: &Object{ID: "https://example.com/~alice/notes/1", Type: NoteType, AttributedTo: alice},
+			},
+		},
+		{
+			name: "missing key owner",
+			key:  PublicKey{ID: key.ID},
+			it:   &Activity{Type: LikeType, Actor: alice},
+			// this is not an OriginError
+			reasons: []OriginReason{""},
+		},
+		{
+			name: "spoofed create",
+			key:  key,
+			it: &Activity{
+				ID:     "https://example.net/~mallory/activities/1",
+				Type:   CreateType,
+				Actor:  IRI("https://example.net/~mallory"),
+				Object: &Object{ID: "https://example.com/~alice/notes/1", Type: NoteType, AttributedTo: IRI("https://example.net/~mallory")},
+			},
+			reasons: []OriginReason{HostMismatch, ActorMismatch, AttributionMismatch},
+		},
+		{
+			name: "announce with embedded remote object",
+			key:  key,
+			it: &Activity{
+				ID:     "https://example.com/~alice/activities/2",
+				Type:   AnnounceType,
+				Actor:  alice,
+				Object: &Object{ID: "https://example.org/~bob/notes/1", Type: NoteType, AttributedTo: IRI("https://example.org/~bob")},
+			},
+			reasons: []OriginReason{RefetchRequired},
+			refetch: IRIs{"https://example.org/~bob/notes/1"},
+		},
+		{
+			name: "announce with referenced remote object",
+			key:  key,
+			it: &Activity{
+				ID:     "https://example.com/~alice/activities/3",
+				Type:   AnnounceType,
+				Actor:  alice,
+				Object: IRI("https://example.org/~bob/notes/1"),
+			},
+		},
+		{
+			name: "delete of a referenced remote object",
+			key:  key,
+			it: &Activity{
+				ID:     "https://example.com/~alice/activities/4",
+				Type:   DeleteType,
+				Actor:  alice,
+				Object: IRI("https://example.org/~bob/notes/1"),
+			},
+			reasons: []OriginReason{HostMismatch},
+		},
+		{
+			name: "update of a referenced remote object",
+			key:  key,
+			it: &Activity{
+				ID:     "https://example.com/~alice/activities/5",
+				Type:   UpdateType,
+				Actor:  alice,
+				Object: IRI("https://example.org/~bob/notes/1"),
+			},
+			reasons: []OriginReason{HostMismatch},
+		},
+		{
+			name: "delete of a referenced local object",
+			key:  key,
+			it: &Activity{
+				ID:     "https://example.com/~alice/activities/6",
+				Type:   DeleteType,
+				Actor:  alice,
+				Object: IRI("https://example.com/~alice/notes/1"),
+			},
+		},
+		{
+			name: "create of an object without attribution",
+			key:  key,
+			it: &Activity{
+				ID:     "https://example.com/~alice/activities/7",
+				Type:   CreateType,
+				Actor:  alice,
+				Object: &Object{ID: "https://example.com/~alice/notes/1", Type: NoteType},
+			},
+			reasons: []OriginReason{AttributionMismatch},
+		},
+		{
+			name: "update of an object without attribution",
+			key:  key,
+			it: &Activity{
+				ID:     "https://example.com/~alice/activities/8",
+				Type:   UpdateType,
+				Actor:  alice,
+				Object: &Object{ID: "https://example.com/~alice/notes/1", Type: NoteType},
+			},
+			reasons: []OriginReason{AttributionMismatch},
+		},
+		{
+			name: "update of the key owner's profile",
+			key:  key,
+			it: &Activity{
+				ID:     "https://example.com/~alice/activities/9",
+				Type:   UpdateType,
+				Actor:  alice,
+				Object: &Actor{ID: alice, Type: PersonType},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			valid, errs := OriginVerifierNew(tt.key).Validate("https://example.org/~bob/inbox", tt.it)
+			if valid != (len(tt.reasons) == 0) {
+				t.Fatalf("Validate() = %t, errors %v", valid, errs)
+			}
+			if errs == nil {
+				return
+			}
+			reasons := make([]OriginReason, 0)
+			for _, err := range errs.Errors() {
+				oe, _ := err.(OriginError)
+				reasons = append(reasons, oe.Reason)
+			}
+			if !reflect.DeepEqual(reasons, tt.reasons) {
+				t.Errorf("Validate() reasons = %v, want %v", reasons, tt.reasons)
+			}
+			if got := RefetchIRIs(errs); !reflect.DeepEqual(got, tt.refetch) {
+				t.Errorf("RefetchIRIs() = %v, want %v", got, tt.refetch)
+			}
+		})
+	}
+}
+
+func TestOriginVerifier_Check(t *testing.T) {
+	key := PublicKey{ID: "https://example.com/~alice#main-key", Owner: "https://example.com/~alice"}
+	v := DefaultValidator{Rules: []ValidationRule{OriginVerifierNew(key).Check}}
+
+	spoofed := &Activity{
+		ID:     "https://example.com/~alice/activities/1",
+		Type:   CreateType,
+		Actor:  IRI("https://example.com/~mallory"),
+		Object: &Object{Type: NoteType, AttributedTo: key.Owner},
+	}
+	valid, errs := v.Validate("https://example.org/~bob/inbox", spoofed)
+	if valid || len(errs.Errors()) != 1 {
+		t.Errorf("Validate() = %t, errors %v, want the actor mismatch", valid, errs)
+	}
+}
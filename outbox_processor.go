@@ -0,0 +1,168 @@
+package activitypub
+
+import "github.com/go-ap/errors"
+
+// IDGenerator returns a new ID for the "it" item, which is being posted to an outbox.
+type IDGenerator func(it Item) (ID, error)
+
+// OutboxProcessor normalises the items clients post to the outboxes of local actors.
+//
+// https://www.w3.org/TR/activitypub/#client-to-server-interactions
+type OutboxProcessor struct {
+	GenerateID IDGenerator
+}
+
+// OutboxProcessorNew initializes an OutboxProcessor which assigns IDs using the gen IDGenerator
+func OutboxProcessorNew(gen IDGenerator) *OutboxProcessor {
+	return &OutboxProcessor{GenerateID: gen}
+}
+
+// Process normalises the "it" item posted by a client to the outbox of the owner actor, so it's
+// ready to be stored and delivered.
+//
+// A bare object is wrapped in a Create activity, with the owner as actor. Activities without an actor
+// get the owner as actor, and the ones having a different actor are rejected.
+// For Create activities the addressing is copied between the activity and its object, in both directions,
+// and the object is attributed to the owner if it's not already attributed. Objects attributed to other
+// actors, which don't include the owner, are rejected.
+// The activity, and the object of a Create activity, receive new IDs, any value set by the client being ignored.
+//
+// Process returns the normalised activity and the recipients it needs to be delivered to.
+// The bto and bcc properties are stripped from the activity and its object, but their values
+// are still part of the returned recipients.
+func (p OutboxProcessor) Process(owner IRI, it Item) (Item, ItemCollection, error) {
+	if p.GenerateID == nil {
+		return nil, nil, errors.Newf("nil ID generator for the outbox processor")
+	}
+	if len(owner) == 0 {
+		return nil, nil, errors.NotValidf("missing outbox owner")
+	}
+	if IsNil(it) {
+		return nil, nil, errors.NotValidf("nil item")
+	}
+	if it.IsLink() {
+		return nil, nil, errors.NotValidf("unable to process IRI %s, an object or activity is required", it.GetLink())
+	}
+
+	typ := it.GetType()
+	if !ActivityTypes.Contains(typ) && !IntransitiveActivityTypes.Contains(typ) {
+		act, err := wrapInCreate(owner, it)
+		if err != nil {
+			return nil, nil, err
+		}
+		it = act
+		typ = act.Type
+	}
+
+	err := OnIntransitiveActivity(it, func(act *IntransitiveActivity) error {
+		if IsNil(act.Actor) {
+			act.Actor = owner
+		}
+		if !act.Actor.GetLink().Equals(owner, false) {
+			return errors.Forbiddenf("%s can not post %s activities of %s", owner, act.Type, act.Actor.GetLink())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if typ == CreateType {
+		err = OnActivity(it, func(act *Activity) error {
+			return p.normaliseCreate(act)
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	id, err := p.GenerateID(it)
+	if err != nil {
+		return nil, nil, errors.Annotatef(err, "unable to generate ID for %s activity", typ)
+	}
+	_ = OnObject(it, func(o *Object) error {
+		o.ID = id
+		return nil
+	})
+
+	var recipients ItemCollection
+	if r, ok := it.(HasRecipients); ok {
+		recipients = r.Recipients()
+	}
+	CleanRecipients(it)
+
+	return it, recipients, nil
+}
+
+// normaliseCreate copies the addressing between the act Create activity and its object,
+// and assigns a new ID to the object.
+func (p OutboxProcessor) normaliseCreate(act *Activity) error {
+	if IsNil(act.Object) {
+		return errors.NotValidf("missing object for %s activity", act.Type)
+	}
+	if act.Object.IsLink() {
+		return nil
+	}
+	return OnObject(act.Object, func(ob *Object) error {
+		for _, aud := range []struct{ act, ob *ItemCollection }{
+			{&act.To, &ob.To},
+			{&act.Bto, &ob.Bto},
+			{&act.CC, &ob.CC},
+			{&act.BCC, &ob.BCC},
+			{&act.Audience, &ob.Audience},
+		} {
+			copyRecipients(aud.act, *aud.ob)
+			copyRecipients(aud.ob, *aud.act)
+		}
+		if err := attributeTo(ob, act.Actor.GetLink()); err != nil {
+			return err
+		}
+
+		id, err := p.GenerateID(ob)
+		if err != nil {
+			return errors.Annotatef(err, "unable to generate ID for %s object", ob.Type)
+		}
+		ob.ID = id
+		return nil
+	})
+}
+
+// wrapInCreate returns a Create activity having the "it" object, and the owner as actor.
+func wrapInCreate(owner IRI, it Item) (*Create, error) {
+	var act *Create
+	err := OnObject(it, func(ob *Object) error {
+		if err := attributeTo(ob, owner); err != nil {
+			return err
+		}
+		act = CreateNew("", it)
+		act.Actor = owner
+		act.Published = ob.Published
+		return nil
+	})
+	return act, err
+}
+
+// attributeTo attributes the ob object to the actor if it's not attributed, and checks that
+// the actor is one of its authors otherwise.
+func attributeTo(ob *Object, actor IRI) error {
+	if IsNil(ob.AttributedTo) {
+		ob.AttributedTo = actor
+		return nil
+	}
+	for _, author := range objectsOf(ob.AttributedTo) {
+		if !IsNil(author) && author.GetLink().Equals(actor, false) {
+			return nil
+		}
+	}
+	return errors.Forbiddenf("%s can not post %s object attributed to %s", actor, ob.Type, ob.AttributedTo.GetLink())
+}
+
+// copyRecipients appends to the "to" collection the recipients in "from" it doesn't already contain
+func copyRecipients(to *ItemCollection, from ItemCollection) {
+	for _, rec := range from {
+		if IsNil(rec) || to.Contains(rec.GetLink()) {
+			continue
+		}
+		*to = append(*to, rec.GetLink())
+	}
+}
@@ -0,0 +1,183 @@
+package activitypub
+
+import (
+	"fmt"
+	"testing"
+)
+
+func mockIDGenerator() IDGenerator {
+	count := 0
+	return func(it Item) (ID, error) {
+		count++
+		return ID(fmt.Sprintf("https://example.org/%s/%d", it.GetType(), count)), nil
+	}
+}
+
+func TestOutboxProcessor_Process(t *testing.T) {
+	alice := IRI("https://example.org/~alice")
+	bob := IRI("https://example.com/~bob")
+	jane := IRI("https://example.com/~jane")
+
+	tests := []struct {
+		name           string
+		it             Item
+		wantErr        bool
+		wantRecipients ItemCollection
+		check          func(*testing.T, Item)
+	}{
+		{
+			name:    "nil",
+			wantErr: true,
+		},
+		{
+			name:    "IRI",
+			it:      IRI("https://example.org/~alice/notes/1"),
+			wantErr: true,
+		},
+		{
+			name:           "object without attribution is attributed to the owner",
+			it:             &Object{Type: NoteType, To: ItemCollection{bob}},
+			wantRecipients: ItemCollection{bob},
+			check: func(t *testing.T, it Item) {
+				_ = OnActivity(it, func(act *Activity) error {
+					if !act.Actor.GetLink().Equals(alice, true) {
+						t.Errorf("Process() actor = %v, want %s", act.Actor, alice)
+					}
+					return OnObject(act.Object, func(ob *Object) error {
+						if IsNil(ob.AttributedTo) || !ob.AttributedTo.GetLink().Equals(alice, true) {
+							t.Errorf("Process() object attributedTo = %v, want %s", ob.AttributedTo, alice)
+						}
+						return nil
+					})
+				})
+			},
+		},
+		{
+			name:           "activity without actor gets the owner as actor",
+			it:             &Activity{Type: LikeType, Object: IRI("https://example.com/~bob/notes/1"), To: ItemCollection{bob}},
+			wantRecipients: ItemCollection{bob},
+			check: func(t *testing.T, it Item) {
+				_ = OnActivity(it, func(act *Activity) error {
+					if IsNil(act.Actor) || !act.Actor.GetLink().Equals(alice, true) {
+						t.Errorf("Process() actor = %v, want %s", act.Actor, alice)
+					}
+					return nil
+				})
+			},
+		},
+		{
+			name:    "activity of another actor",
+			it:      &Activity{Type: LikeType, Actor: bob, Object: IRI("https://example.com/~bob/notes/1")},
+			wantErr: true,
+		},
+		{
+			name:    "object attributed to another actor",
+			it:      &Object{Type: NoteType, AttributedTo: bob},
+			wantErr: true,
+		},
+		{
+			name:    "create of an object attributed to another actor",
+			it:      &Activity{Type: CreateType, Actor: alice, Object: &Object{Type: NoteType, AttributedTo: bob}},
+			wantErr: true,
+		},
+		{
+			name:           "object with multiple authors including the owner",
+			it:             &Object{Type: NoteType, AttributedTo: ItemCollection{bob, alice}, To: ItemCollection{jane}},
+			wantRecipients: ItemCollection{jane},
+		},
+		{
+			name:           "bare object is wrapped in a Create",
+			it:             &Object{ID: "https://example.org/client-id", Type: NoteType, AttributedTo: alice, To: ItemCollection{bob}, BCC: ItemCollection{jane}},
+			wantRecipients: ItemCollection{bob, jane},
+			check: func(t *testing.T, it Item) {
+				_ = OnActivity(it, func(act *Activity) error {
+					if act.Type != CreateType || act.ID != "https://example.org/Create/2" {
+						t.Errorf("Process() = %s[%s], want a Create with a generated ID", act.Type, act.ID)
+					}
+					if !act.Actor.GetLink().Equals(alice, true) {
+						t.Errorf("Process() actor = %v, want %s", act.Actor, alice)
+					}
+					if !act.To.Contains(bob) || len(act.BCC) > 0 {
+						t.Errorf("Process() To = %v, BCC = %v, want the addressing of the object without BCC", act.To, act.BCC)
+					}
+					return OnObject(act.Object, func(ob *Object) error {
+						if ob.ID != "https://example.org/Note/1" {
+							t.Errorf("Process() object ID = %s, want a generated ID", ob.ID)
+						}
+						if len(ob.BCC) > 0 {
+							t.Errorf("Process() object BCC = %v, want none", ob.BCC)
+						}
+						return nil
+					})
+				})
+			},
+		},
+		{
+			name: "create copies addressing both ways",
+			it: &Activity{
+				Type:   CreateType,
+				Actor:  alice,
+				To:     ItemCollection{PublicNS},
+				Object: &Object{Type: NoteType, CC: ItemCollection{bob}, Bto: ItemCollection{jane}},
+			},
+			wantRecipients: ItemCollection{PublicNS, bob, jane},
+			check: func(t *testing.T, it Item) {
+				_ = OnActivity(it, func(act *Activity) error {
+					if !act.CC.Contains(bob) || len(act.Bto) > 0 {
+						t.Errorf("Process() activity CC = %v, Bto = %v", act.CC, act.Bto)
+					}
+					return OnObject(act.Object, func(ob *Object) error {
+						if !ob.To.Contains(PublicNS) || len(ob.Bto) > 0 {
+							t.Errorf("Process() object To = %v, Bto = %v", ob.To, ob.Bto)
+						}
+						if !ob.AttributedTo.GetLink().Equals(alice, true) {
+							t.Errorf("Process() object attributedTo = %v, want %s", ob.AttributedTo, alice)
+						}
+						return nil
+					})
+				})
+			},
+		},
+		{
+			name:           "other activities keep their object",
+			it:             &Activity{ID: "https://example.org/client-id", Type: LikeType, Actor: alice, Object: IRI("https://example.com/~bob/notes/1"), To: ItemCollection{bob}},
+			wantRecipients: ItemCollection{bob},
+			check: func(t *testing.T, it Item) {
+				_ = OnActivity(it, func(act *Activity) error {
+					if act.ID != "https://example.org/Like/1" {
+						t.Errorf("Process() ID = %s, want a generated ID", act.ID)
+					}
+					if act.Object != IRI("https://example.com/~bob/notes/1") {
+						t.Errorf("Process() object = %v, want it unchanged", act.Object)
+					}
+					return nil
+				})
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, recipients, err := OutboxProcessorNew(mockIDGenerator()).Process(alice, tt.it)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Process() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(recipients) != len(tt.wantRecipients) {
+				t.Errorf("Process() recipients = %v, want %v", recipients, tt.wantRecipients)
+			}
+			for _, rec := range tt.wantRecipients {
+				if !recipients.Contains(rec) {
+					t.Errorf("Process() recipients = %v, missing %s", recipients, rec)
+				}
+			}
+			if tt.check != nil {
+				tt.check(t, got)
+			}
+		})
+	}
+}
+
+func TestOutboxProcessor_ProcessWithoutOwner(t *testing.T) {
+	if _, _, err := OutboxProcessorNew(mockIDGenerator()).Process("", &Object{Type: NoteType}); err == nil {
+		t.Errorf("Process() without an outbox owner should fail")
+	}
+}
@@ -0,0 +1,110 @@
+package activitypub
+
+import (
+	"iter"
+
+	"github.com/go-ap/errors"
+)
+
+// DefaultMaxPages is the number of pages PagedItems walks when its maxPages argument is not positive
+const DefaultMaxPages = 100
+
+// PagedItems returns an iterator over the items of the col collection, which follows its pages.
+//
+// If col is a collection without items, the iteration continues with its first page, and from a page
+// it continues with the next one. The collections and pages which are IRIs are loaded using d.
+// The walk stops after maxPages documents, including col, or when reaching a page that was already
+// visited. When a page fails to load, the error is yielded and the iteration stops.
+func PagedItems(col Item, d Dereferencer, maxPages int) iter.Seq2[Item, error] {
+	if maxPages <= 0 {
+		maxPages = DefaultMaxPages
+	}
+	return func(yield func(Item, error) bool) {
+		seen := make(map[IRI]struct{})
+		isSeen := func(iri IRI) bool {
+			_, ok := seen[iri]
+			return ok
+		}
+
+		cur := col
+		for pages := 0; !IsNil(cur) && pages < maxPages; pages++ {
+			var link IRI
+			if cur.IsLink() {
+				link = cur.GetLink()
+				if isSeen(link) {
+					return
+				}
+				if d == nil {
+					yield(nil, errors.Newf("nil dereferencer, unable to load %s", link))
+					return
+				}
+				loaded, err := d.Load(link)
+				if err != nil {
+					yield(nil, err)
+					return
+				}
+				if IsNil(loaded) {
+					yield(nil, errors.NotFoundf("unable to load %s", link))
+					return
+				}
+				cur = loaded
+				seen[link] = struct{}{}
+			}
+			if id := cur.GetLink(); len(id) > 0 && id != link {
+				if isSeen(id) {
+					return
+				}
+				seen[id] = struct{}{}
+			}
+
+			var items ItemCollection
+			err := OnCollectionIntf(cur, func(c CollectionInterface) error {
+				items = c.Collection()
+				return nil
+			})
+			if err != nil || !cur.IsCollection() {
+				yield(nil, errors.NotValidf("%s[%s] is not a collection", cur.GetLink(), cur.GetType()))
+				return
+			}
+			for _, it := range items {
+				if !yield(it, nil) {
+					return
+				}
+			}
+			cur = nextPage(cur, len(items) > 0)
+		}
+	}
+}
+
+// nextPage returns the page following the cur collection page, or the first page of the cur
+// collection if it has no items
+func nextPage(cur Item, hasItems bool) Item {
+	var next Item
+	switch cur.GetType() {
+	case OrderedCollectionPageType:
+		_ = OnOrderedCollectionPage(cur, func(p *OrderedCollectionPage) error {
+			next = p.Next
+			return nil
+		})
+	case CollectionPageType:
+		_ = OnCollectionPage(cur, func(p *CollectionPage) error {
+			next = p.Next
+			return nil
+		})
+	case OrderedCollectionType:
+		_ = OnOrderedCollection(cur, func(c *OrderedCollection) error {
+			if !hasItems {
+				next = c.First
+			}
+			return nil
+		})
+	case CollectionType:
+		_ = OnCollection(cur, func(c *Collection) error {
+			if !hasItems {
+				next = c.First
+			}
+			return nil
+		})
+	}
+	return next
+}
@@ -0,0 +1,104 @@
+package activitypub
+
+import (
+	"iter"
+	"reflect"
+	"testing"
+
+	"github.com/go-ap/errors"
+)
+
+func TestItemCollection_All(t *testing.T) {
+	items := ItemCollection{IRI("https://example.com/1"), IRI("https://example.com/2"), IRI("https://example.com/3")}
+	cols := map[string]CollectionInterface{
+		"Collection":            &Collection{Items: items},
+		"OrderedCollection":     &OrderedCollection{OrderedItems: items},
+		"CollectionPage":        &CollectionPage{Items: items},
+		"OrderedCollectionPage": &OrderedCollectionPage{OrderedItems: items},
+	}
+
+	var got ItemCollection
+	for it := range items.All() {
+		got = append(got, it)
+	}
+	if !reflect.DeepEqual(got, items) {
+		t.Errorf("All() = %v, want %v", got, items)
+	}
+	for name, col := range cols {
+		got = got[:0]
+		for it := range col.(interface{ All() iter.Seq[Item] }).All() {
+			got = append(got, it)
+			if len(got) == 2 {
+				break
+			}
+		}
+		if !reflect.DeepEqual(got, items[:2]) {
+			t.Errorf("%s.All() with a break = %v, want %v", name, got, items[:2])
+		}
+	}
+}
+
+func TestPagedItems(t *testing.T) {
+	col := IRI("https://example.com/outbox")
+	docs := map[IRI]Item{
+		col: &OrderedCollection{ID: "https://example.com/outbox", Type: OrderedCollectionType, First: IRI("https://example.com/outbox?page=1")},
+		"https://example.com/outbox?page=1": &OrderedCollectionPage{
+			ID:           "https://example.com/outbox?page=1",
+			Type:         OrderedCollectionPageType,
+			OrderedItems: ItemCollection{IRI("https://example.com/1"), IRI("https://example.com/2")},
+			Next:         IRI("https://example.com/outbox?page=2"),
+		},
+		"https://example.com/outbox?page=2": &OrderedCollectionPage{
+			ID:           "https://example.com/outbox?page=2",
+			Type:         OrderedCollectionPageType,
+			OrderedItems: ItemCollection{IRI("https://example.com/3")},
+			// a misbehaving server linking back to the first page
+			Next: IRI("https://example.com/outbox?page=1"),
+		},
+	}
+	loads := 0
+	load := LoadFn(func(iri IRI) (Item, error) {
+		loads++
+		if it, ok := docs[iri]; ok {
+			return it, nil
+		}
+		return nil, errors.NotFoundf("%s not found", iri)
+	})
+	collect := func(col Item, maxPages int) (IRIs, error) {
+		var iris IRIs
+		for it, err := range PagedItems(col, load, maxPages) {
+			if err != nil {
+				return iris, err
+			}
+			iris = append(iris, it.GetLink())
+		}
+		return iris, nil
+	}
+
+	got, err := collect(col, 0)
+	if err != nil {
+		t.Fatalf("PagedItems() error = %v", err)
+	}
+	if want := (IRIs{"https://example.com/1", "https://example.com/2", "https://example.com/3"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("PagedItems() = %v, want %v", got, want)
+	}
+	if loads != 3 {
+		t.Errorf("PagedItems() loaded %d documents, want 3 with the cycle stopping the walk", loads)
+	}
+
+	if got, _ = collect(col, 2); len(got) != 2 {
+		t.Errorf("PagedItems() with 2 pages = %v, want the items of the first page only", got)
+	}
+
+	embedded := &Collection{ID: "https://example.com/likes", Type: CollectionType, Items: ItemCollection{IRI("https://example.com/4")}}
+	if got, _ = collect(embedded, 0); !reflect.DeepEqual(got, IRIs{"https://example.com/4"}) {
+		t.Errorf("PagedItems() of an embedded collection = %v", got)
+	}
+
+	if _, err = collect(IRI("https://example.com/missing"), 0); !errors.IsNotFound(err) {
+		t.Errorf("PagedItems() of a missing collection error = %v, want NotFound", err)
+	}
+	if _, err = collect(&Object{ID: "https://example.com/notes/1", Type: NoteType}, 0); err == nil {
+		t.Errorf("PagedItems() of a Note should fail")
+	}
+}
@@ -0,0 +1,206 @@
+package activitypub
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/go-ap/errors"
+)
+
+// DefaultPageSize is the number of items in a page, when the Cursor doesn't specify it
+const DefaultPageSize = 20
+
+// The query parameters of the page IRIs
+const (
+	offsetParam = "offset"
+	countParam  = "count"
+	maxIDParam  = "max_id"
+	minIDParam  = "min_id"
+)
+
+// Cursor identifies a page of a collection, either by the position of its first item, or,
+// similarly to the Mastodon API, by the IDs of the items that bound it.
+//
+// The items of a collection are considered to be ordered from the newest to the oldest, so
+// with a MaxID the page contains the items following it, and with a MinID the items preceding it.
+type Cursor struct {
+	// Offset is the position of the first item of the page, it's used only when MaxID and MinID are empty
+	Offset int
+	// Count is the maximum number of items in the page, when zero DefaultPageSize is used
+	Count int
+	// MaxID selects the items which come after the one with this ID
+	MaxID IRI
+	// MinID selects the items which come immediately before the one with this ID
+	MinID IRI
+}
+
+// IRI returns the IRI of the page the cursor identifies in the col collection
+func (c Cursor) IRI(col IRI) IRI {
+	params := make([]string, 0, 3)
+	if len(c.MaxID) > 0 {
+		params = append(params, maxIDParam+"="+url.QueryEscape(c.MaxID.String()))
+	}
+	if len(c.MinID) > 0 {
+		params = append(params, minIDParam+"="+url.QueryEscape(c.MinID.String()))
+	}
+	if len(params) == 0 {
+		params = append(params, fmt.Sprintf("%s=%d", offsetParam, c.Offset))
+	}
+	if c.Count > 0 {
+		params = append(params, fmt.Sprintf("%s=%d", countParam, c.Count))
+	}
+	sep := "?"
+	if strings.Contains(col.String(), "?") {
+		sep = "&"
+	}
+	return IRI(col.String() + sep + strings.Join(params, "&"))
+}
+
+// ParseCursor returns the IRI of the collection and the Cursor of the page the iri identifies.
+//
+// It's the reverse of Cursor.IRI, the query parameters which are not part of the cursor are kept
+// in the collection IRI.
+func ParseCursor(iri IRI) (IRI, Cursor, error) {
+	c := Cursor{}
+	u, err := iri.URL()
+	if err != nil {
+		return iri, c, errors.NewNotValid(err, "invalid page IRI %s", iri)
+	}
+	q := u.Query()
+	toInt := func(param string) (int, error) {
+		v := q.Get(param)
+		if len(v) == 0 {
+			return 0, nil
+		}
+		i, err := strconv.Atoi(v)
+		if err != nil || i < 0 {
+			return 0, errors.NotValidf("invalid %s value %q", param, v)
+		}
+		return i, nil
+	}
+	if c.Offset, err = toInt(offsetParam); err != nil {
+		return iri, c, err
+	}
+	if c.Count, err = toInt(countParam); err != nil {
+		return iri, c, err
+	}
+	c.MaxID = IRI(q.Get(maxIDParam))
+	c.MinID = IRI(q.Get(minIDParam))
+
+	for _, param := range []string{offsetParam, countParam, maxIDParam, minIDParam} {
+		q.Del(param)
+	}
+	u.RawQuery = q.Encode()
+	return IRI(u.String()), c, nil
+}
+
+// Paginate returns the page of the col OrderedCollection or Collection which the c Cursor identifies,
+// as an OrderedCollectionPage or a CollectionPage respectively.
+//
+// The page is part of col, and links to its first and last pages, which are identified by offset,
+// and to its previous and next pages, if they exist, which are identified in the same way as the
+// cursor identifies the page: by offset, or by the IDs of the items bounding it.
+// The items of the page are a copy of the ones in col.
+func Paginate(col CollectionInterface, c Cursor) (CollectionInterface, error) {
+	if IsNil(col) {
+		return nil, errors.NotValidf("nil collection")
+	}
+	if c.Offset < 0 || c.Count < 0 {
+		return nil, errors.NotValidf("invalid page offset %d and count %d", c.Offset, c.Count)
+	}
+	if c.Count == 0 {
+		c.Count = DefaultPageSize
+	}
+	colIRI := col.GetLink()
+	items := col.Collection()
+
+	start, end, err := c.window(items)
+	if err != nil {
+		return nil, err
+	}
+	// an offset past the end of the collection results in an empty page
+	pageItems := append(ItemCollection{}, items[min(start, len(items)):min(end, len(items))]...)
+
+	var prev, next Item
+	if len(c.MaxID) > 0 || len(c.MinID) > 0 {
+		if start > 0 && len(pageItems) > 0 {
+			prev = Cursor{MinID: pageItems.First().GetLink(), Count: c.Count}.IRI(colIRI)
+		}
+		if end < len(items) && len(pageItems) > 0 {
+			next = Cursor{MaxID: pageItems[len(pageItems)-1].GetLink(), Count: c.Count}.IRI(colIRI)
+		}
+	} else {
+		if start > 0 {
+			prev = Cursor{Offset: max(start-c.Count, 0), Count: c.Count}.IRI(colIRI)
+		}
+		if end < len(items) {
+			next = Cursor{Offset: end, Count: c.Count}.IRI(colIRI)
+		}
+	}
+	first := Cursor{Count: c.Count}.IRI(colIRI)
+	last := first
+	if len(items) > 0 {
+		last = Cursor{Offset: (len(items) - 1) / c.Count * c.Count, Count: c.Count}.IRI(colIRI)
+	}
+
+	switch col.GetType() {
+	case OrderedCollectionType:
+		p := OrderedCollectionPageNew(col)
+		p.ID = c.IRI(colIRI)
+		p.OrderedItems = pageItems
+		p.StartIndex = uint(start)
+		p.First, p.Last, p.Prev, p.Next = first, last, prev, next
+		return p, nil
+	case CollectionType:
+		p := CollectionPageNew(col)
+		p.ID = c.IRI(colIRI)
+		p.Items = pageItems
+		p.First, p.Last, p.Prev, p.Next = first, last, prev, next
+		return p, nil
+	}
+	return nil, errors.NotSupportedf("unable to paginate %s[%s]", colIRI, col.GetType())
+}
+
+// window returns the bounds of the page the cursor identifies in the items, which can be past their end
+func (c Cursor) window(items ItemCollection) (int, int, error) {
+	indexOf := func(iri IRI) (int, error) {
+		for i, it := range items {
+			if !IsNil(it) && it.GetLink().Equals(iri, true) {
+				return i, nil
+			}
+		}
+		return -1, errors.NotFoundf("%s is not part of the collection", iri)
+	}
+
+	start, end := c.Offset, len(items)
+	if len(c.MaxID) > 0 || len(c.MinID) > 0 {
+		start = 0
+	}
+	if len(c.MaxID) > 0 {
+		i, err := indexOf(c.MaxID)
+		if err != nil {
+			return 0, 0, err
+		}
+		start = i + 1
+	}
+	if len(c.MinID) > 0 {
+		i, err := indexOf(c.MinID)
+		if err != nil {
+			return 0, 0, err
+		}
+		end = i
+		if len(c.MaxID) == 0 && end-c.Count > 0 {
+			// the page contains the items immediately preceding MinID
+			start = end - c.Count
+		}
+	}
+	if end-start > c.Count {
+		end = start + c.Count
+	}
+	if end < start {
+		end = start
+	}
+	return start, end, nil
+}
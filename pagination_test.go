@@ -0,0 +1,154 @@
+package activitypub
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/go-ap/errors"
+)
+
+func TestCursor_IRI(t *testing.T) {
+	col := IRI("https://example.com/~alice/outbox")
+	tests := []struct {
+		c    Cursor
+		want IRI
+	}{
+		{c: Cursor{}, want: "https://example.com/~alice/outbox?offset=0"},
+		{c: Cursor{Offset: 20, Count: 10}, want: "https://example.com/~alice/outbox?offset=20&count=10"},
+		{c: Cursor{MaxID: "https://example.com/activities/1", Count: 10}, want: "https://example.com/~alice/outbox?max_id=https%3A%2F%2Fexample.com%2Factivities%2F1&count=10"},
+		{c: Cursor{MinID: "https://example.com/activities/1"}, want: "https://example.com/~alice/outbox?min_id=https%3A%2F%2Fexample.com%2Factivities%2F1"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.want), func(t *testing.T) {
+			got := tt.c.IRI(col)
+			if got != tt.want {
+				t.Errorf("IRI() = %s, want %s", got, tt.want)
+			}
+			gotCol, gotCursor, err := ParseCursor(got)
+			if err != nil {
+				t.Fatalf("ParseCursor() error = %v", err)
+			}
+			if gotCol != col || !reflect.DeepEqual(gotCursor, tt.c) {
+				t.Errorf("ParseCursor() = %s, %+v, want %s, %+v", gotCol, gotCursor, col, tt.c)
+			}
+		})
+	}
+
+	if gotCol, _, _ := ParseCursor("https://example.com/tags?name=go&offset=10"); gotCol != "https://example.com/tags?name=go" {
+		t.Errorf("ParseCursor() collection = %s, want the other query parameters kept", gotCol)
+	}
+	if _, _, err := ParseCursor("https://example.com/outbox?count=many"); !errors.IsNotValid(err) {
+		t.Errorf("ParseCursor() of an invalid count error = %v, want NotValid", err)
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	col := OrderedCollectionNew("https://example.com/~alice/outbox")
+	for i := 0; i < 5; i++ {
+		col.OrderedItems = append(col.OrderedItems, IRI(fmt.Sprintf("https://example.com/activities/%d", i)))
+	}
+	col.TotalItems = 5
+	act := func(i int) IRI { return col.OrderedItems[i].GetLink() }
+	cursorIRI := func(c Cursor) Item { return c.IRI(col.ID) }
+
+	tests := []struct {
+		name       string
+		c          Cursor
+		items      IRIs
+		startIndex uint
+		prev, next Item
+		wantErr    bool
+	}{
+		{
+			name:  "first page",
+			c:     Cursor{Count: 2},
+			items: IRIs{act(0), act(1)},
+			next:  cursorIRI(Cursor{Offset: 2, Count: 2}),
+		},
+		{
+			name:       "last page",
+			c:          Cursor{Offset: 4, Count: 2},
+			items:      IRIs{act(4)},
+			startIndex: 4,
+			prev:       cursorIRI(Cursor{Offset: 2, Count: 2}),
+		},
+		{
+			name:       "max_id",
+			c:          Cursor{MaxID: act(1), Count: 2},
+			items:      IRIs{act(2), act(3)},
+			startIndex: 2,
+			prev:       cursorIRI(Cursor{MinID: act(2), Count: 2}),
+			next:       cursorIRI(Cursor{MaxID: act(3), Count: 2}),
+		},
+		{
+			name:       "min_id",
+			c:          Cursor{MinID: act(4), Count: 2},
+			items:      IRIs{act(2), act(3)},
+			startIndex: 2,
+			prev:       cursorIRI(Cursor{MinID: act(2), Count: 2}),
+			next:       cursorIRI(Cursor{MaxID: act(3), Count: 2}),
+		},
+		{
+			name:  "min_id at the start",
+			c:     Cursor{MinID: act(1), Count: 2},
+			items: IRIs{act(0)},
+			next:  cursorIRI(Cursor{MaxID: act(0), Count: 2}),
+		},
+		{
+			name:       "max_id and min_id",
+			c:          Cursor{MaxID: act(0), MinID: act(3), Count: 10},
+			items:      IRIs{act(1), act(2)},
+			startIndex: 1,
+			prev:       cursorIRI(Cursor{MinID: act(1), Count: 10}),
+			next:       cursorIRI(Cursor{MaxID: act(2), Count: 10}),
+		},
+		{name: "unknown max_id", c: Cursor{MaxID: "https://example.com/activities/unknown"}, wantErr: true},
+		{name: "negative offset", c: Cursor{Offset: -1}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Paginate(col, tt.c)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Paginate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			p, ok := got.(*OrderedCollectionPage)
+			if !ok {
+				t.Fatalf("Paginate() = %T, want *OrderedCollectionPage", got)
+			}
+			var items IRIs
+			for _, it := range p.OrderedItems {
+				items = append(items, it.GetLink())
+			}
+			if !reflect.DeepEqual(items, tt.items) {
+				t.Errorf("Paginate() items = %v, want %v", items, tt.items)
+			}
+			if p.ID != tt.c.IRI(col.ID) || p.PartOf.GetLink() != col.ID || p.TotalItems != 5 || p.StartIndex != tt.startIndex {
+				t.Errorf("Paginate() id = %s, partOf = %v, totalItems = %d, startIndex = %d", p.ID, p.PartOf, p.TotalItems, p.StartIndex)
+			}
+			if !reflect.DeepEqual(p.Prev, tt.prev) || !reflect.DeepEqual(p.Next, tt.next) {
+				t.Errorf("Paginate() prev = %v, next = %v, want %v, %v", p.Prev, p.Next, tt.prev, tt.next)
+			}
+			wantFirst := cursorIRI(Cursor{Count: tt.c.Count})
+			wantLast := cursorIRI(Cursor{Offset: 4 / tt.c.Count * tt.c.Count, Count: tt.c.Count})
+			if !reflect.DeepEqual(p.First, wantFirst) || !reflect.DeepEqual(p.Last, wantLast) {
+				t.Errorf("Paginate() first = %v, last = %v, want %v, %v", p.First, p.Last, wantFirst, wantLast)
+			}
+		})
+	}
+
+	unordered := &Collection{ID: "https://example.com/~alice/liked", Type: CollectionType, Items: col.OrderedItems}
+	got, err := Paginate(unordered, Cursor{})
+	if err != nil {
+		t.Fatalf("Paginate() error = %v", err)
+	}
+	if got.GetType() != CollectionPageType || got.Count() != 5 {
+		t.Errorf("Paginate() = %s with %d items, want a %s with 5 items", got.GetType(), got.Count(), CollectionPageType)
+	}
+	if _, err = Paginate(&ItemCollection{IRI("https://example.com/1")}, Cursor{}); err == nil {
+		t.Errorf("Paginate() of an ItemCollection should fail")
+	}
+}
@@ -103,6 +103,8 @@ type Place struct {
 	// as a form of provenance, or to support future editing by clients.
 	// In general, clients do the conversion from source to content, not the other way around.
 	Source Source `jsonld:"source,omitempty"`
+
+	Extensions Extensions `jsonld:"-"`
 	// Accuracy indicates the accuracy of position coordinates on a Place objects.
 	// Expressed in properties of percentage. e.g. "94.0" means "94.0% accurate".
 	Accuracy float64 `jsonld:"accuracy,omitempty"`
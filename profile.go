@@ -105,6 +105,8 @@ type Profile struct {
 	// as a form of provenance, or to support future editing by clients.
 	// In general, clients do the conversion from source to content, not the other way around.
 	Source Source `jsonld:"source,omitempty"`
+
+	Extensions Extensions `jsonld:"-"`
 	// Describes On a Profile object, the describes property identifies the object described by the Profile.
 	Describes Item `jsonld:"describes,omitempty"`
 }
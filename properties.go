@@ -0,0 +1,149 @@
+package activitypub
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-ap/errors"
+)
+
+// GetProperty returns the value of the name property of the "it" item, where name is the JSON-LD
+// name of the property, like "inReplyTo" or "summary".
+//
+// The value has the type of the corresponding struct field, for example an Item for "inReplyTo",
+// NaturalLanguageValues for "summary" or time.Time for "published".
+// If "it" doesn't have such a property a NotSupported error is returned.
+func GetProperty(it Item, name string) (any, error) {
+	if IsNil(it) {
+		return nil, errors.NotValidf("unable to get %q property of nil item", name)
+	}
+	p, ok := propertiesOf(it)[name]
+	if !ok {
+		return nil, errors.NotSupportedf("%T[%s] has no %q property", it, it.GetType(), name)
+	}
+	return reflect.ValueOf(p).Elem().Interface(), nil
+}
+
+// SetProperty sets the name property of the "it" item to the v value, where name is the JSON-LD
+// name of the property, like "inReplyTo" or "summary". The "it" item must be a pointer, as it's
+// modified in place.
+//
+// Besides values of the type of the corresponding struct field, v can be:
+//   - a string, for the properties which are IRIs, natural language values or RFC3339 timestamps,
+//   - any number, for the numeric properties,
+//   - a single item, for the properties which are collections of items,
+//   - nil, which clears the property.
+//
+// If "it" doesn't have such a property a NotSupported error is returned, and if v is not a
+// valid value for it a NotValid error.
+func SetProperty(it Item, name string, v any) error {
+	if IsNil(it) {
+		return errors.NotValidf("unable to set %q property of nil item", name)
+	}
+	if reflect.ValueOf(it).Kind() != reflect.Ptr {
+		return errors.NotValidf("unable to set %q property of %T, it must be a pointer", name, it)
+	}
+	p, ok := propertiesOf(it)[name]
+	if !ok {
+		return errors.NotSupportedf("%T[%s] has no %q property", it, it.GetType(), name)
+	}
+	return setValue(reflect.ValueOf(p).Elem(), name, v)
+}
+
+// setValue assigns the v value, converted if needed, to the dst field of the name property
+func setValue(dst reflect.Value, name string, v any) error {
+	if v == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+	val := reflect.ValueOf(v)
+	if val.Type().AssignableTo(dst.Type()) {
+		dst.Set(val)
+		return nil
+	}
+
+	switch d := dst.Addr().Interface().(type) {
+	case *NaturalLanguageValues:
+		if s, ok := v.(string); ok {
+			*d = DefaultNaturalLanguageValue(s)
+			return nil
+		}
+	case *time.Time:
+		if s, ok := v.(string); ok {
+			t, err := time.Parse(time.RFC3339, s)
+			if err != nil {
+				return errors.NewNotValid(err, "invalid %q value %q", name, s)
+			}
+			*d = t
+			return nil
+		}
+	case *ItemCollection:
+		if ob, ok := v.(Item); ok {
+			*d = ItemCollection{ob}
+			return nil
+		}
+		if s, ok := v.(string); ok {
+			*d = ItemCollection{IRI(s)}
+			return nil
+		}
+	case *Item, *CanReceiveActivities:
+		if s, ok := v.(string); ok {
+			dst.Set(reflect.ValueOf(IRI(s)))
+			return nil
+		}
+	}
+
+	switch {
+	case val.Kind() == reflect.String && dst.Kind() == reflect.String:
+		// IRI, MimeType, LangRef, ActivityVocabularyType, etc.
+		dst.Set(val.Convert(dst.Type()))
+		return nil
+	case isNumeric(val.Kind()) && isNumeric(dst.Kind()):
+		if isUnsigned(dst.Kind()) && (val.CanInt() && val.Int() < 0 || val.CanFloat() && val.Float() < 0) {
+			return errors.NotValidf("invalid negative %q value %v", name, v)
+		}
+		dst.Set(val.Convert(dst.Type()))
+		return nil
+	}
+	return errors.NotValidf("invalid %T value for %q property, expected %s", v, name, dst.Type())
+}
+
+func isNumeric(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Float64
+}
+
+func isUnsigned(k reflect.Kind) bool {
+	return k >= reflect.Uint && k <= reflect.Uintptr
+}
+
+// propertiesOf returns pointers to the fields of the "it" item, indexed by the JSON-LD names of
+// their properties, as they are found in the jsonld tags of its struct. When "it" is not a pointer,
+// the fields are the ones of a copy of it. IRIs and item collections don't have any properties.
+func propertiesOf(it Item) map[string]any {
+	props := make(map[string]any)
+	if IsNil(it) {
+		return props
+	}
+	v := reflect.ValueOf(it)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	} else {
+		cp := reflect.New(v.Type()).Elem()
+		cp.Set(v)
+		v = cp
+	}
+	if v.Kind() != reflect.Struct {
+		return props
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("jsonld"), ",")
+		if !f.IsExported() || len(name) == 0 || name == "-" {
+			continue
+		}
+		props[name] = v.Field(i).Addr().Interface()
+	}
+	return props
+}
@@ -0,0 +1,122 @@
+package activitypub
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-ap/errors"
+)
+
+func TestGetProperty(t *testing.T) {
+	alice := IRI("https://example.com/~alice")
+	note := IRI("https://example.com/notes/1")
+	deleted := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		it   Item
+		prop string
+		want any
+		err  func(error) bool
+	}{
+		{name: "object inReplyTo", it: &Object{Type: NoteType, InReplyTo: note}, prop: "inReplyTo", want: note},
+		{name: "object summary", it: &Object{Type: NoteType, Summary: DefaultNaturalLanguageValue("cw")}, prop: "summary", want: DefaultNaturalLanguageValue("cw")},
+		{name: "object empty property", it: &Object{Type: NoteType}, prop: "context", want: nil},
+		{name: "actor preferredUsername", it: &Actor{Type: PersonType, PreferredUsername: DefaultNaturalLanguageValue("alice")}, prop: "preferredUsername", want: DefaultNaturalLanguageValue("alice")},
+		{name: "actor object property", it: &Actor{Type: PersonType, Name: DefaultNaturalLanguageValue("Alice")}, prop: "name", want: DefaultNaturalLanguageValue("Alice")},
+		{name: "activity actor", it: &Activity{Type: LikeType, Actor: alice}, prop: "actor", want: alice},
+		{name: "activity object", it: &Activity{Type: LikeType, Object: note}, prop: "object", want: note},
+		{name: "question closed", it: &Question{Type: QuestionType, Closed: true}, prop: "closed", want: true},
+		{name: "place latitude", it: &Place{Type: PlaceType, Latitude: 46.77}, prop: "latitude", want: 46.77},
+		{name: "tombstone deleted", it: &Tombstone{Type: TombstoneType, Deleted: deleted}, prop: "deleted", want: deleted},
+		{name: "link href", it: &Link{Type: MentionType, Href: alice}, prop: "href", want: alice},
+		{name: "collection totalItems", it: &OrderedCollection{Type: OrderedCollectionType, TotalItems: 3}, prop: "totalItems", want: uint(3)},
+		{name: "page startIndex", it: &OrderedCollectionPage{Type: OrderedCollectionPageType, StartIndex: 20}, prop: "startIndex", want: uint(20)},
+		{name: "page partOf", it: &CollectionPage{Type: CollectionPageType, PartOf: alice.AddPath("outbox")}, prop: "partOf", want: alice.AddPath("outbox")},
+		{name: "actor likes", it: &Actor{Type: PersonType, Likes: alice.AddPath("likes")}, prop: "likes", want: alice.AddPath("likes")},
+		{name: "property value value", it: &PropertyValue{Type: PropertyValueType, Value: DefaultNaturalLanguageValue("https://example.com")}, prop: "value", want: DefaultNaturalLanguageValue("https://example.com")},
+		{name: "identity proof signatureAlgorithm", it: &IdentityProof{Type: IdentityProofType, SignatureAlgorithm: "keybase"}, prop: "signatureAlgorithm", want: "keybase"},
+		{name: "identity proof signatureValue", it: IdentityProof{Type: IdentityProofType, SignatureValue: "signature"}, prop: "signatureValue", want: "signature"},
+		{name: "unknown property", it: &Object{Type: NoteType}, prop: "oneOf", err: errors.IsNotSupported},
+		{name: "activity property of an object", it: &Object{Type: NoteType}, prop: "actor", err: errors.IsNotSupported},
+		{name: "object property of a link", it: &Link{Type: LinkType}, prop: "inReplyTo", err: errors.IsNotSupported},
+		{name: "IRI", it: note, prop: "id", err: errors.IsNotSupported},
+		{name: "nil", it: nil, prop: "id", err: errors.IsNotValid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetProperty(tt.it, tt.prop)
+			if tt.err != nil {
+				if !tt.err(err) {
+					t.Fatalf("GetProperty() error = %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetProperty() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetProperty() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetProperty(t *testing.T) {
+	alice := IRI("https://example.com/~alice")
+	note := IRI("https://example.com/notes/1")
+
+	tests := []struct {
+		name string
+		it   Item
+		prop string
+		val  any
+		want any
+		err  func(error) bool
+	}{
+		{name: "object summary", it: &Object{Type: NoteType}, prop: "summary", val: DefaultNaturalLanguageValue("cw"), want: DefaultNaturalLanguageValue("cw")},
+		{name: "object summary from string", it: &Object{Type: NoteType}, prop: "summary", val: "cw", want: DefaultNaturalLanguageValue("cw")},
+		{name: "object inReplyTo from string", it: &Object{Type: NoteType}, prop: "inReplyTo", val: note.String(), want: note},
+		{name: "object published from string", it: &Object{Type: NoteType}, prop: "published", val: "2024-01-01T00:00:00Z", want: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "object to from a single item", it: &Object{Type: NoteType}, prop: "to", val: alice, want: ItemCollection{alice}},
+		{name: "object mediaType from string", it: &Object{Type: NoteType}, prop: "mediaType", val: "text/html", want: MimeType("text/html")},
+		{name: "object clear", it: &Object{Type: NoteType, InReplyTo: note}, prop: "inReplyTo", val: nil, want: nil},
+		{name: "actor movedTo", it: &Actor{Type: PersonType}, prop: "movedTo", val: alice, want: alice},
+		{name: "intransitive activity actor", it: &IntransitiveActivity{Type: ArriveType}, prop: "actor", val: alice, want: CanReceiveActivities(alice)},
+		{name: "question oneOf", it: &Question{Type: QuestionType}, prop: "oneOf", val: ItemCollection{note}, want: ItemCollection{note}},
+		{name: "place radius from int", it: &Place{Type: PlaceType}, prop: "radius", val: 5, want: int64(5)},
+		{name: "tombstone formerType", it: &Tombstone{Type: TombstoneType}, prop: "formerType", val: NoteType, want: NoteType},
+		{name: "link width from int", it: &Link{Type: LinkType}, prop: "width", val: 640, want: uint(640)},
+		{name: "collection items", it: &Collection{Type: CollectionType}, prop: "items", val: ItemCollection{note}, want: ItemCollection{note}},
+		{name: "actor shares", it: &Actor{Type: PersonType}, prop: "shares", val: alice.AddPath("shares").String(), want: alice.AddPath("shares")},
+		{name: "property value value from string", it: &PropertyValue{Type: PropertyValueType}, prop: "value", val: "https://example.com", want: DefaultNaturalLanguageValue("https://example.com")},
+		{name: "identity proof signatureValue", it: &IdentityProof{Type: IdentityProofType}, prop: "signatureValue", val: "signature", want: "signature"},
+		{name: "unknown property", it: &Object{Type: NoteType}, prop: "closed", val: true, err: errors.IsNotSupported},
+		{name: "invalid value", it: &Object{Type: NoteType}, prop: "published", val: 42, err: errors.IsNotValid},
+		{name: "invalid timestamp", it: &Object{Type: NoteType}, prop: "published", val: "yesterday", err: errors.IsNotValid},
+		{name: "negative unsigned value", it: &Link{Type: LinkType}, prop: "height", val: -1, err: errors.IsNotValid},
+		{name: "not a pointer", it: Object{Type: NoteType}, prop: "summary", val: "cw", err: errors.IsNotValid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := SetProperty(tt.it, tt.prop, tt.val)
+			if tt.err != nil {
+				if !tt.err(err) {
+					t.Fatalf("SetProperty() error = %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SetProperty() error = %v", err)
+			}
+			got, err := GetProperty(tt.it, tt.prop)
+			if err != nil {
+				t.Fatalf("GetProperty() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetProperty() after SetProperty() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,9 @@
 package activitypub
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestQuestionNew(t *testing.T) {
 	testValue := ID("test")
@@ -87,3 +90,27 @@ func TestQuestion_IsCollection(t *testing.T) {
 func TestQuestion_UnmarshalJSON(t *testing.T) {
 	t.Skipf("TODO")
 }
+
+func TestQuestion_MarshalJSON(t *testing.T) {
+	q := Question{ID: "https://example.com/questions/1", Type: QuestionType, Closed: true}
+	data, err := q.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON() error = %v", err)
+	}
+	if want := `"closed":true`; !strings.Contains(string(data), want) {
+		t.Errorf("MarshalJSON() = %s, want it to contain %s", data, want)
+	}
+	it, err := UnmarshalJSON(data)
+	if err != nil {
+		t.Fatalf("UnmarshalJSON() error = %v", err)
+	}
+	err = OnQuestion(it, func(got *Question) error {
+		if !got.Closed {
+			t.Errorf("UnmarshalJSON() closed = %t, want %t", got.Closed, q.Closed)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Errorf("UnmarshalJSON() = %T, want a Question: %v", it, err)
+	}
+}
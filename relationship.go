@@ -111,6 +111,8 @@ type Relationship struct {
 	// as a form of provenance, or to support future editing by clients.
 	// In general, clients do the conversion from source to content, not the other way around.
 	Source Source `jsonld:"source,omitempty"`
+
+	Extensions Extensions `jsonld:"-"`
 	// Subject Subject On a Relationship object, the subject property identifies one of the connected individuals.
 	// For instance, for a Relationship object describing "John is related to Sally", subject would refer to John.
 	Subject Item `jsonld:"subject,omitempty"`
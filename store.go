@@ -0,0 +1,21 @@
+package activitypub
+
+// Store is the storage the activity processors apply their side effects against.
+//
+// Collections are identified by their IRI, and hold the IRIs of their members.
+type Store interface {
+	// Load returns the item identified by the iri, or an error which satisfies errors.IsNotFound
+	// from the github.com/go-ap/errors package if no such item exists.
+	Load(iri IRI) (Item, error)
+	// Save stores the item, completely replacing any previous version with the same ID.
+	Save(it Item) (Item, error)
+	// Delete replaces the stored version of the item with a Tombstone, which it returns.
+	Delete(it Item) (Item, error)
+	// AddTo appends the items to the collection identified by col.
+	AddTo(col IRI, it ...Item) error
+	// RemoveFrom removes the items from the collection identified by col.
+	RemoveFrom(col IRI, it ...Item) error
+	// Page returns a page of the collection identified by col, containing at most count items
+	// starting with the one at the offset position.
+	Page(col IRI, offset, count int) (CollectionInterface, error)
+}
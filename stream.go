@@ -0,0 +1,301 @@
+package activitypub
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"github.com/go-ap/errors"
+	"github.com/valyala/fastjson"
+)
+
+// CollectionDecoder reads a JSON collection document from a stream, without loading all its items in memory.
+//
+// The properties of the collection are available first through Collection, and the items of its
+// "orderedItems" or "items" property are returned one by one by Next, each being loaded using JSONLoadItem.
+type CollectionDecoder struct {
+	dec *json.Decoder
+
+	col  Item
+	meta []byte
+	// inItems is true while the decoder is inside the items array
+	inItems bool
+	done    bool
+	err     error
+}
+
+// CollectionDecoderNew initializes a CollectionDecoder reading from r
+func CollectionDecoderNew(r io.Reader) *CollectionDecoder {
+	return &CollectionDecoder{dec: json.NewDecoder(r)}
+}
+
+// Collection returns the collection, with the properties which precede its items in the document.
+//
+// The collection doesn't contain the items, and the properties which follow the items are loaded into it
+// only after Next has reached the end of the items.
+func (d *CollectionDecoder) Collection() (Item, error) {
+	if d.col != nil || d.err != nil {
+		return d.col, d.err
+	}
+	if err := d.expect(json.Delim('{')); err != nil {
+		return nil, d.fail(err)
+	}
+	d.meta = append(d.meta, '{')
+	if err := d.readProperties(); err != nil {
+		return nil, d.fail(err)
+	}
+	col, err := d.loadCollection()
+	if err != nil {
+		return nil, d.fail(err)
+	}
+	d.col = col
+	d.done = !d.inItems
+	return d.col, nil
+}
+
+// Next returns the next item of the collection, or io.EOF when there are no more items
+func (d *CollectionDecoder) Next() (Item, error) {
+	if _, err := d.Collection(); err != nil {
+		return nil, err
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	for d.inItems && d.dec.More() {
+		raw := json.RawMessage{}
+		if err := d.dec.Decode(&raw); err != nil {
+			return nil, d.fail(err)
+		}
+		// the values returned by a fastjson.Parser are valid only until its next use,
+		// so each item gets its own
+		p := fastjson.Parser{}
+		val, err := p.ParseBytes(raw)
+		if err != nil {
+			return nil, d.fail(err)
+		}
+		it, err := JSONLoadItem(val)
+		if err != nil {
+			return nil, d.fail(err)
+		}
+		if it == nil {
+			// similarly to JSONItemsFn, we skip the values which can't be loaded as items
+			continue
+		}
+		return it, nil
+	}
+	if d.inItems {
+		if err := d.expect(json.Delim(']')); err != nil {
+			return nil, d.fail(err)
+		}
+		d.inItems = false
+		if err := d.readProperties(); err != nil {
+			return nil, d.fail(err)
+		}
+	}
+	if !d.done {
+		d.done = true
+		if err := d.reloadCollection(); err != nil {
+			return nil, d.fail(err)
+		}
+	}
+	return nil, io.EOF
+}
+
+// readProperties buffers the properties of the collection, until it reaches its items or its end
+func (d *CollectionDecoder) readProperties() error {
+	for d.dec.More() {
+		tok, err := d.dec.Token()
+		if err != nil {
+			return err
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return errors.NotValidf("invalid property name %v", tok)
+		}
+		if key == "orderedItems" || key == "items" {
+			tok, err := d.dec.Token()
+			if err != nil {
+				return err
+			}
+			if tok == json.Delim('[') {
+				d.inItems = true
+				return nil
+			}
+			return errors.NotSupportedf("unable to stream %q property which is not an array", key)
+		}
+		raw := json.RawMessage{}
+		if err := d.dec.Decode(&raw); err != nil {
+			return err
+		}
+		if len(d.meta) > 1 {
+			d.meta = append(d.meta, ',')
+		}
+		name, _ := json.Marshal(key)
+		d.meta = append(d.meta, name...)
+		d.meta = append(d.meta, ':')
+		d.meta = append(d.meta, raw...)
+	}
+	return d.expect(json.Delim('}'))
+}
+
+func (d *CollectionDecoder) loadCollection() (Item, error) {
+	p := fastjson.Parser{}
+	val, err := p.ParseBytes(append(d.meta, '}'))
+	if err != nil {
+		return nil, err
+	}
+	col, err := JSONLoadItem(val)
+	if err != nil {
+		return nil, err
+	}
+	if IsNil(col) || !CollectionTypes.Contains(col.GetType()) {
+		return nil, errors.NotValidf("the document is not a collection")
+	}
+	return col, nil
+}
+
+// reloadCollection loads into the collection the properties which followed its items
+func (d *CollectionDecoder) reloadCollection() error {
+	col, err := d.loadCollection()
+	if err != nil {
+		return err
+	}
+	switch d.col.GetType() {
+	case OrderedCollectionPageType:
+		return OnOrderedCollectionPage(col, func(c *OrderedCollectionPage) error {
+			return OnOrderedCollectionPage(d.col, func(p *OrderedCollectionPage) error {
+				*p = *c
+				return nil
+			})
+		})
+	case OrderedCollectionType:
+		return OnOrderedCollection(col, func(c *OrderedCollection) error {
+			return OnOrderedCollection(d.col, func(p *OrderedCollection) error {
+				*p = *c
+				return nil
+			})
+		})
+	case CollectionPageType:
+		return OnCollectionPage(col, func(c *CollectionPage) error {
+			return OnCollectionPage(d.col, func(p *CollectionPage) error {
+				*p = *c
+				return nil
+			})
+		})
+	case CollectionType:
+		return OnCollection(col, func(c *Collection) error {
+			return OnCollection(d.col, func(p *Collection) error {
+				*p = *c
+				return nil
+			})
+		})
+	}
+	return nil
+}
+
+func (d *CollectionDecoder) expect(delim json.Delim) error {
+	tok, err := d.dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != delim {
+		return errors.NotValidf("expected %s, found %v", delim, tok)
+	}
+	return nil
+}
+
+func (d *CollectionDecoder) fail(err error) error {
+	if d.err == nil {
+		d.err = errors.Annotatef(err, "unable to decode collection")
+	}
+	return d.err
+}
+
+// CollectionEncoder writes an OrderedCollection to a stream, without needing all its items in memory
+type CollectionEncoder struct {
+	w io.Writer
+}
+
+// CollectionEncoderNew initializes a CollectionEncoder writing to w
+func CollectionEncoderNew(w io.Writer) *CollectionEncoder {
+	return &CollectionEncoder{w: w}
+}
+
+// Encode writes the col collection, with the items returned by the next function as its "orderedItems".
+// The next function must return io.EOF when there are no more items, which makes the Next method
+// of a CollectionDecoder usable as it.
+//
+// The items already present in col are written before the ones returned by next, and the
+// "@context" of the document is the one returned by ItemContextFn for col.
+//
+// As "totalItems" is written before the items, the caller needs to set it to the number of all the
+// items, including the ones returned by next. When it's zero and next is not nil, it is omitted.
+func (e *CollectionEncoder) Encode(col OrderedCollection, next func() (Item, error)) error {
+	items := col.OrderedItems
+	col.OrderedItems = nil
+
+	meta, err := MarshalJSON(&col)
+	if err != nil {
+		return errors.Annotatef(err, "unable to encode collection %s", col.ID)
+	}
+	meta = bytes.TrimSpace(meta)
+	if len(meta) < 2 || meta[len(meta)-1] != '}' {
+		return errors.Newf("unable to encode collection %s", col.ID)
+	}
+	if col.TotalItems == 0 && next != nil {
+		// totalItems is the last property written, and we don't know the real value of it
+		if zero := []byte(`"totalItems":0}`); bytes.HasSuffix(meta, zero) {
+			meta = append(bytes.TrimSuffix(bytes.TrimSuffix(meta, zero), []byte{','}), '}')
+		}
+	}
+	b := append(meta[:len(meta)-1:len(meta)-1], []byte(`,"orderedItems":[`)...)
+	if len(meta) == 2 {
+		b = append(meta[:1:1], []byte(`"orderedItems":[`)...)
+	}
+	if _, err = e.w.Write(b); err != nil {
+		return err
+	}
+
+	first := true
+	write := func(it Item) error {
+		if IsNil(it) {
+			return nil
+		}
+		m, ok := it.(json.Marshaler)
+		if !ok {
+			return errors.NotSupportedf("unable to encode %T", it)
+		}
+		v, err := m.MarshalJSON()
+		if err != nil {
+			return errors.Annotatef(err, "unable to encode %s", it.GetLink())
+		}
+		if len(v) == 0 {
+			return nil
+		}
+		if !first {
+			v = append([]byte{','}, v...)
+		}
+		first = false
+		_, err = e.w.Write(v)
+		return err
+	}
+	for _, it := range items {
+		if err = write(it); err != nil {
+			return err
+		}
+	}
+	for next != nil {
+		it, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if err = write(it); err != nil {
+			return err
+		}
+	}
+	_, err = e.w.Write([]byte{']', '}'})
+	return err
+}
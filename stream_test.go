@@ -0,0 +1,195 @@
+package activitypub
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCollectionDecoder(t *testing.T) {
+	doc := `{
+  "@context": "https://www.w3.org/ns/activitystreams",
+  "id": "https://example.com/~alice/outbox",
+  "type": "OrderedCollection",
+  "totalItems": 3,
+  "orderedItems": [
+    {"id": "https://example.com/activities/1", "type": "Create", "object": {"id": "https://example.com/notes/1", "type": "Note", "content": "hello"}},
+    "https://example.com/activities/2",
+    {"id": "https://example.com/activities/3", "type": "Announce", "object": "https://example.com/notes/2"}
+  ],
+  "first": "https://example.com/~alice/outbox?page=true"
+}`
+	d := CollectionDecoderNew(strings.NewReader(doc))
+	col, err := d.Collection()
+	if err != nil {
+		t.Fatalf("Collection() error = %v", err)
+	}
+	if col.GetType() != OrderedCollectionType || col.GetLink() != "https://example.com/~alice/outbox" {
+		t.Errorf("Collection() = %s[%s], want the outbox", col.GetType(), col.GetLink())
+	}
+
+	var got IRIs
+	for {
+		it, err := d.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		got = append(got, it.GetLink())
+	}
+	want := IRIs{"https://example.com/activities/1", "https://example.com/activities/2", "https://example.com/activities/3"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Next() items = %v, want %v", got, want)
+	}
+	if _, err = d.Next(); err != io.EOF {
+		t.Errorf("Next() after the end error = %v, want io.EOF", err)
+	}
+	_ = OnOrderedCollection(col, func(c *OrderedCollection) error {
+		if c.TotalItems != 3 || len(c.OrderedItems) != 0 {
+			t.Errorf("Collection() totalItems = %d, items = %d, want 3 and no items", c.TotalItems, len(c.OrderedItems))
+		}
+		if IsNil(c.First) || c.First.GetLink() != "https://example.com/~alice/outbox?page=true" {
+			t.Errorf("Collection() first = %v, want the property following the items", c.First)
+		}
+		return nil
+	})
+
+	for _, invalid := range []string{
+		`[]`,
+		`{"id": "https://example.com/notes/1", "type": "Note"}`,
+		`{"type": "OrderedCollection", "orderedItems": {"type": "Note"}}`,
+		`{"type": "OrderedCollection", "orderedItems": [{"type": "Note"`,
+	} {
+		d := CollectionDecoderNew(strings.NewReader(invalid))
+		_, err := d.Collection()
+		if err == nil {
+			_, err = d.Next()
+		}
+		if err == nil || err == io.EOF {
+			t.Errorf("decoding %s error = %v, want an error", invalid, err)
+		}
+	}
+}
+
+func TestCollectionEncoder(t *testing.T) {
+	col := OrderedCollection{
+		ID:           "https://example.com/~alice/outbox",
+		Type:         OrderedCollectionType,
+		TotalItems:   100,
+		OrderedItems: ItemCollection{IRI("https://example.com/activities/0")},
+	}
+	n := 0
+	next := func() (Item, error) {
+		if n++; n > 100 {
+			return nil, io.EOF
+		}
+		return &Activity{ID: ID(fmt.Sprintf("https://example.com/activities/%d", n)), Type: CreateType}, nil
+	}
+
+	buf := bytes.Buffer{}
+	if err := CollectionEncoderNew(&buf).Encode(col, next); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if len(col.OrderedItems) != 1 {
+		t.Errorf("Encode() must not modify the collection")
+	}
+
+	it, err := UnmarshalJSON(buf.Bytes())
+	if err != nil {
+		t.Fatalf("UnmarshalJSON() of the encoded collection error = %v", err)
+	}
+	err = OnOrderedCollection(it, func(c *OrderedCollection) error {
+		if c.ID != col.ID || c.TotalItems != 100 {
+			t.Errorf("Encode() = %s with %d total items, want %s with 100", c.ID, c.TotalItems, col.ID)
+		}
+		if len(c.OrderedItems) != 101 {
+			t.Fatalf("Encode() wrote %d items, want 101", len(c.OrderedItems))
+		}
+		if c.OrderedItems[0].GetLink() != "https://example.com/activities/0" || c.OrderedItems[100].GetType() != CreateType {
+			t.Errorf("Encode() items = %v ... %v", c.OrderedItems[0], c.OrderedItems[100])
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Encode() result is not an OrderedCollection: %v", err)
+	}
+
+	// the decoder can be used to re-encode a collection
+	out := bytes.Buffer{}
+	d := CollectionDecoderNew(bytes.NewReader(buf.Bytes()))
+	meta, err := d.Collection()
+	if err != nil {
+		t.Fatalf("Collection() error = %v", err)
+	}
+	_ = OnOrderedCollection(meta, func(c *OrderedCollection) error {
+		return CollectionEncoderNew(&out).Encode(*c, d.Next)
+	})
+	if !bytes.Equal(buf.Bytes(), out.Bytes()) {
+		t.Errorf("re-encoded collection differs:\n%s\n%s", buf.String(), out.String())
+	}
+}
+
+func TestCollectionEncoder_TotalItems(t *testing.T) {
+	items := func(count int) func() (Item, error) {
+		n := 0
+		return func() (Item, error) {
+			if n++; n > count {
+				return nil, io.EOF
+			}
+			return IRI(fmt.Sprintf("https://example.com/activities/%d", n)), nil
+		}
+	}
+	tests := []struct {
+		name      string
+		col       OrderedCollection
+		next      func() (Item, error)
+		wantTotal string
+		wantItems int
+	}{
+		{
+			name:      "set by the caller",
+			col:       OrderedCollection{ID: "https://example.com/~alice/outbox", Type: OrderedCollectionType, TotalItems: 2},
+			next:      items(2),
+			wantTotal: `"totalItems":2`,
+			wantItems: 2,
+		},
+		{
+			name:      "unknown when streaming",
+			col:       OrderedCollection{ID: "https://example.com/~alice/outbox", Type: OrderedCollectionType},
+			next:      items(2),
+			wantItems: 2,
+		},
+		{
+			name:      "empty collection",
+			col:       OrderedCollection{ID: "https://example.com/~alice/outbox", Type: OrderedCollectionType},
+			wantTotal: `"totalItems":0`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := bytes.Buffer{}
+			if err := CollectionEncoderNew(&buf).Encode(tt.col, tt.next); err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if got := strings.Contains(buf.String(), `"totalItems"`); got != (len(tt.wantTotal) > 0) {
+				t.Errorf("Encode() = %s, want totalItems %q", buf.String(), tt.wantTotal)
+			}
+			if len(tt.wantTotal) > 0 && !strings.Contains(buf.String(), tt.wantTotal) {
+				t.Errorf("Encode() = %s, want %s", buf.String(), tt.wantTotal)
+			}
+			it, err := UnmarshalJSON(buf.Bytes())
+			if err != nil {
+				t.Fatalf("UnmarshalJSON() of the encoded collection error = %v", err)
+			}
+			col, ok := it.(CollectionInterface)
+			if !ok || col.Count() != uint(tt.wantItems) {
+				t.Errorf("Encode() = %s, want %d items", buf.String(), tt.wantItems)
+			}
+		})
+	}
+}
@@ -6,7 +6,7 @@ import (
 
 	j "github.com/go-ap/jsonld"
 
-	pub "github.com/go-ap/activitypub"
+	pub "github.com/snoymy/activitypub"
 )
 
 func TestAcceptSerialization(t *testing.T) {
@@ -3,7 +3,10 @@ package tests
 // Common server tests...
 
 import (
+	"fmt"
 	"testing"
+
+	pub "github.com/snoymy/activitypub"
 )
 
 // Server: Fetching the inbox
@@ -29,7 +32,31 @@ Server: Fetching the inbox
 
   inbox is an OrderedCollection
 `
-	t.Skip(desc)
+	t.Log(desc)
+
+	alice := pub.PersonNew("https://example.org/~alice")
+	alice.Inbox = pub.Inbox.IRI(alice)
+	s := pub.MemoryStoreNew(alice)
+	for i := 1; i <= 3; i++ {
+		if err := s.AddTo(alice.Inbox.GetLink(), pub.IRI(fmt.Sprintf("https://example.com/~bob/activities/%d", i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	inbox, err := s.Load(alice.Inbox.GetLink())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inbox.GetType() != pub.OrderedCollectionType {
+		t.Errorf("expected the inbox to be an %s, got %s", pub.OrderedCollectionType, inbox.GetType())
+	}
+	page, err := s.Page(alice.Inbox.GetLink(), 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.GetType() != pub.OrderedCollectionPageType || page.Count() != 2 {
+		t.Errorf("expected an %s with 2 items, got %s with %d", pub.OrderedCollectionPageType, page.GetType(), page.Count())
+	}
 }
 
 // Server: Fetching the inbox
@@ -42,7 +69,73 @@ Server: Fetching the inbox
 
   Server filters inbox content according to the requester's permission
 `
-	t.Skip(desc)
+	t.Log(desc)
+
+	alice := pub.PersonNew("https://example.org/~alice")
+	alice.Inbox = pub.Inbox.IRI(alice)
+	bob := pub.IRI("https://example.com/~bob")
+	carol := pub.IRI("https://example.com/~carol")
+	bobFollowers := pub.Followers.IRI(bob)
+
+	public := pub.CreateNew("https://example.com/~bob/activities/1", mockNote("https://example.com/~bob/notes/1", bob))
+	public.Actor = bob
+	public.To = pub.ItemCollection{pub.PublicNS}
+	followersOnly := pub.CreateNew("https://example.com/~bob/activities/2", mockNote("https://example.com/~bob/notes/2", bob))
+	followersOnly.Actor = bob
+	followersOnly.To = pub.ItemCollection{bobFollowers}
+	direct := pub.CreateNew("https://example.com/~bob/activities/3", mockNote("https://example.com/~bob/notes/3", bob))
+	direct.Actor = bob
+	direct.To = pub.ItemCollection{alice.GetLink()}
+	direct.BCC = pub.ItemCollection{carol}
+
+	s := pub.MemoryStoreNew(alice, public, followersOnly, direct)
+	if err := s.AddTo(alice.Inbox.GetLink(), public, followersOnly, direct); err != nil {
+		t.Fatal(err)
+	}
+	page, err := s.Page(alice.Inbox.GetLink(), 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inbox, err := pub.Dereference(page, pub.LoadFn(s.Load), 1, "orderedItems")
+	if err != nil {
+		t.Fatal(err)
+	}
+	isMember := func(col, viewer pub.IRI) bool {
+		return col.Equals(bobFollowers, true) && viewer.Equals(carol, true)
+	}
+
+	tests := []struct {
+		viewer pub.IRI
+		want   pub.IRIs
+	}{
+		{viewer: "", want: pub.IRIs{public.ID}},
+		{viewer: "https://example.com/~dave", want: pub.IRIs{public.ID}},
+		{viewer: carol, want: pub.IRIs{public.ID, followersOnly.ID, direct.ID}},
+		{viewer: alice.GetLink(), want: pub.IRIs{public.ID, direct.ID}},
+		{viewer: bob, want: pub.IRIs{public.ID, followersOnly.ID, direct.ID}},
+	}
+	for _, tt := range tests {
+		filtered, err := pub.FilterCollection(tt.viewer, inbox, isMember)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got pub.IRIs
+		for _, it := range filtered.Collection() {
+			got = append(got, it.GetLink())
+			_ = pub.OnObject(it, func(o *pub.Object) error {
+				if len(o.BCC) > 0 || len(o.Bto) > 0 {
+					t.Errorf("%s: expected the bto and bcc recipients of %s to be removed", tt.viewer, o.ID)
+				}
+				return nil
+			})
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("%s: expected the inbox to contain %v, got %v", tt.viewer, tt.want, got)
+		}
+	}
+	if len(direct.BCC) != 1 {
+		t.Errorf("expected the stored activity to keep its bcc recipients")
+	}
 }
 
 /*
@@ -4,9 +4,11 @@ package tests
 
 import (
 	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 
-	pub "github.com/go-ap/activitypub"
+	pub "github.com/snoymy/activitypub"
 )
 
 // S2S Server: Activities requiring the object property
@@ -289,6 +291,83 @@ S2S Server: Do-not-deliver considerations
 	}
 }
 
+// S2S Server: Do-not-deliver considerations
+// Server does not deliver to the inbox of the actor of the Activity being notified about
+func TestDoNotDeliverToActorInbox(t *testing.T) {
+	desc := `
+S2S Server: Do-not-deliver considerations
+
+  Server does not deliver to recipients which are the same as the actor of the
+Activity being notified about
+`
+	t.Log(desc)
+
+	p := withInbox(pub.PersonNew("main actor"))
+
+	to := withInbox(pub.PersonNew("bob"))
+	o := pub.ObjectNew(pub.ArticleType)
+	cc := withInbox(pub.PersonNew("alice"))
+
+	o.ID = "something"
+	c := pub.CreateNew("create", o)
+	c.Actor = *p
+
+	c.To.Append(p)
+	c.To.Append(to)
+	c.CC.Append(cc)
+	c.CC.Append(p)
+	c.BCC.Append(cc)
+	c.BCC.Append(p)
+
+	inboxes, err := pub.DeliveryInboxes(c, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inboxes.Contains(p.Inbox) {
+		t.Errorf("%T[%s] Actor of activity should not be in the recipients list", p, p.GetID())
+	}
+	if len(inboxes) != 2 {
+		t.Errorf("expected the inboxes of the 2 other recipients, got %v", inboxes)
+	}
+}
+
+// S2S Server: Do-not-deliver considerations
+// Server does not deliver Block activities to the inbox of their object.
+func TestDoNotDeliverBlockToObjectInbox(t *testing.T) {
+	desc := `
+S2S Server: Do-not-deliver considerations
+
+  Server does not deliver Block activities to their object.
+`
+	t.Log(desc)
+
+	p := withInbox(pub.PersonNew("blocked"))
+
+	bob := withInbox(pub.PersonNew("bob"))
+	jane := withInbox(pub.PersonNew("jane doe"))
+
+	b := pub.BlockNew("block actor", p)
+	b.Actor = *bob
+
+	b.To.Append(jane)
+	b.To.Append(p)
+	b.To.Append(bob)
+
+	inboxes, err := pub.DeliveryInboxes(b, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inboxes.Contains(p.Inbox) {
+		t.Errorf("%T[%s] object of Block activity should not be in the recipients list", p, p.GetID())
+	}
+	if inboxes.Contains(bob.Inbox) {
+		t.Errorf("%T[%s] actor of activity should not be in the recipients list", bob, bob.GetID())
+	}
+	if !inboxes.Contains(jane.Inbox) {
+		t.Errorf("%T[%s] should be in the recipients list", jane, jane.GetID())
+	}
+}
+
 // S2S Sever: Support for sharedInbox
 // Delivers to sharedInbox endpoints to reduce the number of receiving actors delivered
 // to by identifying all followers which share the same sharedInbox who would otherwise be
@@ -301,18 +380,68 @@ S2S Sever: Support for sharedInbox
 to by identifying all followers which share the same sharedInbox who would otherwise be
 individual recipients and instead deliver objects to said sharedInbox.
 `
-	t.Skip(desc)
+	t.Log(desc)
+
+	sharedInbox := pub.IRI("https://example.com/inbox")
+	alice := withSharedInbox(pub.PersonNew("https://example.com/alice"), sharedInbox)
+	bob := withSharedInbox(pub.PersonNew("https://example.com/bob"), sharedInbox)
+	jane := withSharedInbox(pub.PersonNew("https://example.org/jane"), "https://example.org/inbox")
+
+	actor := withInbox(pub.PersonNew("https://example.net/main"))
+	followers := pub.OrderedCollectionNew("https://example.net/main/followers")
+	followers.OrderedItems = pub.ItemCollection{alice.GetLink(), bob.GetLink(), jane.GetLink()}
+	actor.Followers = followers.GetLink()
+
+	o := pub.ObjectNew(pub.NoteType)
+	o.ID = "https://example.net/main/notes/1"
+	c := pub.CreateNew("https://example.net/main/activities/1", o)
+	c.Actor = actor.GetLink()
+	c.To.Append(pub.PublicNS, actor.Followers)
+	c.CC.Append(alice.GetLink())
+
+	inboxes, err := pub.DeliveryInboxes(c, mockLoader(followers, alice, bob, jane))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := pub.IRIs{sharedInbox, "https://example.org/inbox"}
+	if !reflect.DeepEqual(inboxes, want) {
+		t.Errorf("expected inboxes %v, got %v", want, inboxes)
+	}
 }
 
 // S2S Sever: Support for sharedInbox
 // Deliver to actor inboxes and collections otherwise addressed which do not have a sharedInbox.
 func TestSharedInboxActorsWOSharedInbox(t *testing.T) {
 	desc := `
-S2S Server: Do-not-deliver considerations
+S2S Sever: Support for sharedInbox
 
-  Server does not deliver Block activities to their object.
+  Deliver to actor inboxes and collections otherwise addressed which do not have a sharedInbox.
 `
-	t.Skip(desc)
+	t.Log(desc)
+
+	sharedInbox := pub.IRI("https://example.com/inbox")
+	alice := withSharedInbox(pub.PersonNew("https://example.com/alice"), sharedInbox)
+	bob := withInbox(pub.PersonNew("https://example.com/bob"))
+	jane := withInbox(pub.PersonNew("https://example.org/jane"))
+
+	group := pub.CollectionNew("https://example.org/groups/1/members")
+	group.Items = pub.ItemCollection{jane}
+
+	o := pub.ObjectNew(pub.NoteType)
+	o.ID = "https://example.net/main/notes/1"
+	c := pub.CreateNew("https://example.net/main/activities/1", o)
+	c.Actor = pub.IRI("https://example.net/main")
+	c.To.Append(alice.GetLink(), bob.GetLink())
+	c.Audience.Append(group.GetLink())
+
+	inboxes, err := pub.DeliveryInboxes(c, mockLoader(group, alice, bob, jane))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := pub.IRIs{sharedInbox, bob.Inbox.GetLink(), jane.Inbox.GetLink()}
+	if !reflect.DeepEqual(inboxes, want) {
+		t.Errorf("expected inboxes %v, got %v", want, inboxes)
+	}
 }
 
 // S2S Server: Deduplicating received activities
@@ -323,7 +452,25 @@ S2S Server: Deduplicating received activities
 
   Server deduplicates activities received in inbox by comparing activity ids
 `
-	t.Skip(desc)
+	t.Log(desc)
+
+	alice := pub.PersonNew("https://example.org/~alice")
+	inbox := pub.Inbox.IRI(alice)
+	s := pub.MemoryStoreNew(alice)
+
+	like := pub.LikeNew("https://example.com/~bob/activities/1", pub.IRI("https://example.org/~alice/notes/1"))
+	like.Actor = pub.IRI("https://example.com/~bob")
+	for _, id := range []pub.IRI{like.ID, "https://EXAMPLE.com/~bob/activities/1", "https://example.com/~bob/activities/1#dup"} {
+		if _, err := s.Save(like); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.AddTo(inbox, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if items := members(s, inbox); len(items) != 1 {
+		t.Errorf("expected the activity to be in the inbox only once, got %v", items)
+	}
 }
 
 // S2S Server: Special forwarding mechanism
@@ -336,7 +483,34 @@ S2S Server: Special forwarding mechanism
 
   Forwards incoming activities to the values of to, bto, cc, bcc, audience if and only if criteria are met.
 `
-	t.Skip(desc)
+	t.Log(desc)
+
+	s, f := mockForwarder()
+	followers := pub.IRI("https://local.example/~alice/followers")
+
+	reply := mockForwardedReply("https://remote.example/activities/1", "https://local.example/~alice/notes/1", followers)
+	targets, err := f.Targets(reply)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !targets.Contains(followers) || len(targets) != 1 {
+		t.Errorf("expected the reply to be forwarded to %s, got %v", followers, targets)
+	}
+
+	notAddressed := mockForwardedReply("https://remote.example/activities/2", "https://local.example/~alice/notes/1", pub.PublicNS)
+	if targets, _ = f.Targets(notAddressed); len(targets) > 0 {
+		t.Errorf("expected the reply not addressed to a local collection to not be forwarded, got %v", targets)
+	}
+
+	notReply := mockForwardedReply("https://remote.example/activities/3", "https://remote.example/notes/1", followers)
+	if targets, _ = f.Targets(notReply); len(targets) > 0 {
+		t.Errorf("expected the reply to a remote object to not be forwarded, got %v", targets)
+	}
+
+	_, _ = s.Save(reply)
+	if targets, _ = f.Targets(reply); len(targets) > 0 {
+		t.Errorf("expected an already seen activity to not be forwarded, got %v", targets)
+	}
 }
 
 // S2S Server: Special forwarding mechanism
@@ -351,7 +525,22 @@ ActivityPub contains a special mechanism for forwarding replies to avoid "ghost
   Recurse through to, bto, cc, bcc, audience object values to determine whether/where
   to forward according to criteria in 7.1.2
 `
-	t.Skip(desc)
+	t.Log(desc)
+
+	s, f := mockForwarder()
+	followers := pub.IRI("https://local.example/~alice/followers")
+
+	parent := &pub.Object{ID: "https://remote.example/notes/1", Type: pub.NoteType, InReplyTo: pub.IRI("https://local.example/~alice/notes/1")}
+	_, _ = s.Save(parent)
+
+	reply := mockForwardedReply("https://remote.example/activities/1", parent.ID, followers)
+	targets, err := f.Targets(reply)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !targets.Contains(followers) {
+		t.Errorf("expected the reply to a reply of a local object to be forwarded to %s, got %v", followers, targets)
+	}
 }
 
 // S2S Server: Special forwarding mechanism
@@ -364,7 +553,27 @@ S2S Server: Special forwarding mechanism
 
   Limits depth of this recursion.
 `
-	t.Skip(desc)
+	t.Log(desc)
+
+	s, f := mockForwarder()
+	followers := pub.IRI("https://local.example/~alice/followers")
+
+	inReplyTo := pub.IRI("https://local.example/~alice/notes/1")
+	for i := 1; i <= 10; i++ {
+		parent := &pub.Object{ID: pub.ID(fmt.Sprintf("https://remote.example/notes/%d", i)), Type: pub.NoteType, InReplyTo: inReplyTo}
+		_, _ = s.Save(parent)
+		inReplyTo = parent.GetLink()
+	}
+
+	reply := mockForwardedReply("https://remote.example/activities/1", inReplyTo, followers)
+	f.MaxDepth = 4
+	if targets, _ := f.Targets(reply); len(targets) > 0 {
+		t.Errorf("expected the search for local objects to be limited to %d levels, got %v", f.MaxDepth, targets)
+	}
+	f.MaxDepth = 20
+	if targets, _ := f.Targets(reply); !targets.Contains(followers) {
+		t.Errorf("expected the search for local objects to find the local object in %d levels, got %v", f.MaxDepth, targets)
+	}
 }
 
 // S2S Server: Verification of content authorship
@@ -381,7 +590,29 @@ S2S Server: Verification of content authorship
 
   Don't trust content received from a server other than the content's origin without some form of verification.
 `
-	t.Skip(desc)
+	t.Log(desc)
+
+	alice := pub.PersonNew("https://example.com/~alice")
+	alice.PublicKey = pub.PublicKey{ID: pub.ID(alice.ID + "#main-key"), Owner: alice.ID}
+	v := pub.DefaultValidator{Rules: []pub.ValidationRule{pub.OriginVerifierNew(alice.PublicKey).Check}}
+
+	note := &pub.Object{ID: "https://example.com/~alice/notes/1", Type: pub.NoteType, AttributedTo: alice.GetLink()}
+	create := pub.CreateNew("https://example.com/~alice/activities/1", note)
+	create.Actor = alice.GetLink()
+	if ok, errs := v.Validate("https://example.org/~bob/inbox", create); !ok {
+		t.Errorf("expected the Create of %s to be accepted: %v", alice.ID, errs)
+	}
+
+	spoofed := &pub.Object{ID: "https://example.net/~mallory/notes/1", Type: pub.NoteType, AttributedTo: pub.IRI("https://example.net/~mallory")}
+	create = pub.CreateNew("https://example.com/~alice/activities/2", spoofed)
+	create.Actor = pub.IRI("https://example.net/~mallory")
+	ok, errs := v.Validate("https://example.org/~bob/inbox", create)
+	if ok {
+		t.Errorf("expected the spoofed Create to be rejected")
+	}
+	if iris := pub.RefetchIRIs(errs); !iris.Contains(spoofed.GetLink()) {
+		t.Errorf("expected %s to need to be fetched from its origin, got %v", spoofed.ID, iris)
+	}
 }
 
 // S2S Server: Update activity
@@ -394,7 +625,24 @@ S2S Server: Update activity
 
   Takes care to be sure that the Update is authorized to modify its object
 `
-	t.Skip(desc)
+	t.Log(desc)
+
+	alice := pub.PersonNew("https://example.org/~alice")
+	mallory := pub.PersonNew("https://example.net/~mallory")
+	note := mockNote("https://example.org/~alice/notes/1", alice)
+	s := pub.MemoryStoreNew(alice, mallory, note)
+
+	changed := mockNote(note.ID, alice)
+	changed.Content = pub.DefaultNaturalLanguageValue("changed by mallory")
+	u := pub.UpdateNew("https://example.net/~mallory/activities/1", changed)
+	u.Actor = mallory.GetLink()
+
+	if _, err := pub.InboxProcessorNew(s).Process(u); err == nil {
+		t.Errorf("Update of %s by %s should not be authorized", note.ID, mallory.ID)
+	}
+	if ob, _ := s.Load(note.ID); ob != note {
+		t.Errorf("%s should not have been modified by an unauthorized Update", note.ID)
+	}
 }
 
 // S2S Server: Update activity
@@ -407,7 +655,28 @@ S2S Server: Update activity
 
   Completely replaces its copy of the activity with the newly received value
 `
-	t.Skip(desc)
+	t.Log(desc)
+
+	alice := pub.PersonNew("https://example.org/~alice")
+	note := mockNote("https://example.org/~alice/notes/1", alice)
+	note.Summary = pub.DefaultNaturalLanguageValue("a summary")
+	s := pub.MemoryStoreNew(alice, note)
+
+	changed := mockNote(note.ID, alice)
+	changed.Content = pub.DefaultNaturalLanguageValue("new content")
+	u := pub.UpdateNew("https://example.org/~alice/activities/1", changed)
+	u.Actor = alice.GetLink()
+
+	if _, err := pub.InboxProcessorNew(s).Process(u); err != nil {
+		t.Fatal(err)
+	}
+	ob, err := s.Load(note.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ob, changed) {
+		t.Errorf("%s should have been replaced completely by the Update, got %#v", note.ID, ob)
+	}
 }
 
 // S2S Server: Delete activity
@@ -418,7 +687,31 @@ S2S Server: Delete activity
 
   Delete removes object's representation, assuming object is owned by sending actor/server
 `
-	t.Skip(desc)
+	t.Log(desc)
+
+	alice := pub.PersonNew("https://example.org/~alice")
+	note := mockNote("https://example.org/~alice/notes/1", alice)
+	s := pub.MemoryStoreNew(alice, note)
+
+	d := pub.DeleteNew("https://example.org/~alice/activities/1", note.GetLink())
+	d.Actor = alice.GetLink()
+
+	if _, err := pub.InboxProcessorNew(s).Process(d); err != nil {
+		t.Fatal(err)
+	}
+	ob, _ := s.Load(note.ID)
+	if ob.GetType() == pub.NoteType {
+		t.Errorf("%s should have been removed", note.ID)
+	}
+
+	mallory := pub.PersonNew("https://example.net/~mallory")
+	other := mockNote("https://example.org/~alice/notes/2", alice)
+	_, _ = s.Save(other)
+	d = pub.DeleteNew("https://example.net/~mallory/activities/1", other.GetLink())
+	d.Actor = mallory.GetLink()
+	if _, err := pub.InboxProcessorNew(s).Process(d); err == nil {
+		t.Errorf("Delete of %s by %s should not be authorized", other.ID, mallory.ID)
+	}
 }
 
 // S2S Server: Delete activity
@@ -429,7 +722,34 @@ S2S Server: Delete activity
 
   Replaces deleted object with a Tombstone object (optional)
 `
-	t.Skip(desc)
+	t.Log(desc)
+
+	alice := pub.PersonNew("https://example.org/~alice")
+	note := mockNote("https://example.org/~alice/notes/1", alice)
+	s := pub.MemoryStoreNew(alice, note)
+
+	d := pub.DeleteNew("https://example.org/~alice/activities/1", note.GetLink())
+	d.Actor = alice.GetLink()
+
+	if _, err := pub.InboxProcessorNew(s).Process(d); err != nil {
+		t.Fatal(err)
+	}
+	ob, err := s.Load(note.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = pub.OnTombstone(ob, func(t *pub.Tombstone) error {
+		if t.FormerType != pub.NoteType {
+			return fmt.Errorf("invalid former type %s, expected %s", t.FormerType, pub.NoteType)
+		}
+		if t.Deleted.IsZero() {
+			return fmt.Errorf("missing deleted time")
+		}
+		return nil
+	})
+	if err != nil || ob.GetType() != pub.TombstoneType {
+		t.Errorf("%s should have been replaced with a Tombstone: %v", note.ID, err)
+	}
 }
 
 // S2S Server: Following, and handling accept/reject of follows
@@ -440,7 +760,23 @@ S2S Server: Following, and handling accept/reject of follows
 
   Follow should add the activity's actor to the receiving actor's Followers Collection.
 `
-	t.Skip(desc)
+	t.Log(desc)
+
+	alice := pub.PersonNew("https://example.org/~alice")
+	bob := pub.PersonNew("https://example.com/~bob")
+	s := pub.MemoryStoreNew(alice, bob)
+
+	f := pub.FollowNew("https://example.com/~bob/activities/1", alice.GetLink())
+	f.Actor = bob.GetLink()
+
+	p := pub.InboxProcessorNew(s)
+	p.IsLocal = func(iri pub.IRI) bool { return strings.HasPrefix(iri.String(), "https://example.org/") }
+	if _, err := p.Process(f); err != nil {
+		t.Fatal(err)
+	}
+	if !members(s, pub.Followers.IRI(alice)).Contains(bob.GetLink()) {
+		t.Errorf("%s should have been added to the followers of %s", bob.ID, alice.ID)
+	}
 }
 
 // S2S Server: Following, and handling accept/reject of follows
@@ -451,7 +787,47 @@ S2S Server: Following, and handling accept/reject of follows
 
   Generates either an Accept or Reject activity with Follow as object and deliver to actor of the Follow
 `
-	t.Skip(desc)
+	t.Log(desc)
+
+	alice := pub.PersonNew("https://example.org/~alice")
+	bob := pub.PersonNew("https://example.com/~bob")
+
+	for _, accept := range []bool{true, false} {
+		s := pub.MemoryStoreNew(alice, bob)
+		f := pub.FollowNew("https://example.com/~bob/activities/1", alice.GetLink())
+		f.Actor = bob.GetLink()
+
+		p := pub.InboxProcessorNew(s)
+		p.AcceptFollowFn = func(*pub.Follow) bool { return accept }
+		p.IsLocal = func(iri pub.IRI) bool { return strings.HasPrefix(iri.String(), "https://example.org/") }
+		modified, err := p.Process(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := pub.RejectType
+		if accept {
+			want = pub.AcceptType
+		}
+		var response pub.Item
+		for _, it := range modified {
+			if it.GetType() == want {
+				response = it
+			}
+		}
+		if response == nil {
+			t.Errorf("expected a generated %s activity, got %v", want, modified)
+			continue
+		}
+		_ = pub.OnActivity(response, func(a *pub.Activity) error {
+			if a.Object != f || !a.Actor.GetLink().Equals(alice.GetLink(), true) {
+				t.Errorf("%s should have the Follow as object and %s as actor", want, alice.ID)
+			}
+			if !a.To.Contains(bob.GetLink()) {
+				t.Errorf("%s should be delivered to the actor of the Follow %s", want, bob.ID)
+			}
+			return nil
+		})
+	}
 }
 
 // S2S Server: Following, and handling accept/reject of follows
@@ -462,7 +838,25 @@ S2S Server: Following, and handling accept/reject of follows
 
   If receiving an Accept in reply to a Follow activity, adds actor to receiver's Following Collection
 `
-	t.Skip(desc)
+	t.Log(desc)
+
+	alice := pub.PersonNew("https://example.org/~alice")
+	bob := pub.PersonNew("https://example.com/~bob")
+	f := pub.FollowNew("https://example.com/~bob/activities/1", alice.GetLink())
+	f.Actor = bob.GetLink()
+	s := pub.MemoryStoreNew(alice, bob, f)
+
+	a := pub.AcceptNew("https://example.org/~alice/activities/1", f.GetLink())
+	a.Actor = alice.GetLink()
+
+	p := pub.InboxProcessorNew(s)
+	p.IsLocal = func(iri pub.IRI) bool { return strings.HasPrefix(iri.String(), "https://example.com/") }
+	if _, err := p.Process(a); err != nil {
+		t.Fatal(err)
+	}
+	if !members(s, pub.Following.IRI(bob)).Contains(alice.GetLink()) {
+		t.Errorf("%s should have been added to the following of %s", alice.ID, bob.ID)
+	}
 }
 
 // S2S Server: Following, and handling accept/reject of follows
@@ -473,7 +867,23 @@ S2S Server: Following, and handling accept/reject of follows
 
   If receiving a Reject in reply to a Follow activity, does not add actor to receiver's Following Collection
 `
-	t.Skip(desc)
+	t.Log(desc)
+
+	alice := pub.PersonNew("https://example.org/~alice")
+	bob := pub.PersonNew("https://example.com/~bob")
+	f := pub.FollowNew("https://example.com/~bob/activities/1", alice.GetLink())
+	f.Actor = bob.GetLink()
+	s := pub.MemoryStoreNew(alice, bob, f)
+
+	r := pub.RejectNew("https://example.org/~alice/activities/1", f.GetLink())
+	r.Actor = alice.GetLink()
+
+	if _, err := pub.InboxProcessorNew(s).Process(r); err != nil {
+		t.Fatal(err)
+	}
+	if members(s, pub.Following.IRI(bob)).Contains(alice.GetLink()) {
+		t.Errorf("%s should not have been added to the following of %s", alice.ID, bob.ID)
+	}
 }
 
 //S2S Server: Activity acceptance side-effects
@@ -487,7 +897,24 @@ S2S Server: Activity acceptance side-effects
 
   Create makes record of the object existing
 `
-	t.Skip(desc)
+	t.Log(desc)
+
+	alice := pub.PersonNew("https://example.org/~alice")
+	note := mockNote("https://example.org/~alice/notes/1", alice)
+	s := pub.MemoryStoreNew(alice)
+
+	c := pub.CreateNew("https://example.org/~alice/activities/1", note)
+	c.Actor = alice.GetLink()
+
+	if _, err := pub.InboxProcessorNew(s).Process(c); err != nil {
+		t.Fatal(err)
+	}
+	if ob, err := s.Load(note.ID); err != nil || ob != note {
+		t.Errorf("%s should have been recorded: %v", note.ID, err)
+	}
+	if _, err := s.Load(c.ID); err != nil {
+		t.Errorf("%s should have been recorded: %v", c.ID, err)
+	}
 }
 
 //S2S Server: Activity acceptance side-effects
@@ -503,7 +930,36 @@ S2S Server: Activity acceptance side-effects
   Add should add the activity's object to the Collection specified in the target property,
   unless not allowed per requirements
 `
-	t.Skip(desc)
+	t.Log(desc)
+
+	alice := pub.PersonNew("https://example.org/~alice")
+	note := mockNote("https://example.org/~alice/notes/1", alice)
+	featured := pub.OrderedCollectionNew("https://example.org/~alice/featured")
+	featured.AttributedTo = alice.GetLink()
+	s := pub.MemoryStoreNew(alice, note, featured)
+
+	a := pub.AddNew("https://example.org/~alice/activities/1", note.GetLink(), featured.GetLink())
+	a.Actor = alice.GetLink()
+
+	p := pub.InboxProcessorNew(s)
+	p.IsLocal = func(iri pub.IRI) bool { return strings.HasPrefix(iri.String(), "https://example.org/") }
+	if _, err := p.Process(a); err != nil {
+		t.Fatal(err)
+	}
+	if !members(s, featured.ID).Contains(note.GetLink()) {
+		t.Errorf("%s should have been added to %s", note.ID, featured.ID)
+	}
+
+	mallory := pub.PersonNew("https://example.net/~mallory")
+	other := pub.IRI("https://example.net/~mallory/notes/1")
+	a = pub.AddNew("https://example.net/~mallory/activities/1", other, featured.GetLink())
+	a.Actor = mallory.GetLink()
+	if _, err := p.Process(a); err == nil {
+		t.Errorf("%s should not be allowed to add to %s", mallory.ID, featured.ID)
+	}
+	if members(s, featured.ID).Contains(other) {
+		t.Errorf("%s should not have been added to %s", other, featured.ID)
+	}
 }
 
 //S2S Server: Activity acceptance side-effects
@@ -519,7 +975,32 @@ S2S Server: Activity acceptance side-effects
   Remove should remove the object from the Collection specified in the target property,
   unless not allowed per requirements
 `
-	t.Skip(desc)
+	t.Log(desc)
+
+	alice := pub.PersonNew("https://example.org/~alice")
+	note := mockNote("https://example.org/~alice/notes/1", alice)
+	featured := pub.OrderedCollectionNew("https://example.org/~alice/featured")
+	featured.AttributedTo = alice.GetLink()
+	featured.OrderedItems = pub.ItemCollection{note.GetLink()}
+	s := pub.MemoryStoreNew(alice, note, featured)
+	p := pub.InboxProcessorNew(s)
+	p.IsLocal = func(iri pub.IRI) bool { return strings.HasPrefix(iri.String(), "https://example.org/") }
+
+	mallory := pub.PersonNew("https://example.net/~mallory")
+	r := pub.RemoveNew("https://example.net/~mallory/activities/1", note.GetLink(), featured.GetLink())
+	r.Actor = mallory.GetLink()
+	if _, err := p.Process(r); err == nil {
+		t.Errorf("%s should not be allowed to remove from %s", mallory.ID, featured.ID)
+	}
+
+	r = pub.RemoveNew("https://example.org/~alice/activities/1", note.GetLink(), featured.GetLink())
+	r.Actor = alice.GetLink()
+	if _, err := p.Process(r); err != nil {
+		t.Fatal(err)
+	}
+	if members(s, featured.ID).Contains(note.GetLink()) {
+		t.Errorf("%s should have been removed from %s", note.ID, featured.ID)
+	}
 }
 
 //S2S Server: Activity acceptance side-effects
@@ -535,7 +1016,40 @@ S2S Server: Activity acceptance side-effects
   Like increments the object's count of likes by adding the received activity to the likes
   collection if this collection is present
 `
-	t.Skip(desc)
+	t.Log(desc)
+
+	alice := pub.PersonNew("https://example.org/~alice")
+	bob := pub.PersonNew("https://example.com/~bob")
+	note := mockNote("https://example.org/~alice/notes/1", alice)
+	note.Likes = pub.IRI("https://example.org/~alice/notes/1/likes")
+	withoutLikes := mockNote("https://example.org/~alice/notes/2", alice)
+	s := pub.MemoryStoreNew(alice, bob, note, withoutLikes)
+
+	l := pub.LikeNew("https://example.com/~bob/activities/1", note.GetLink())
+	l.Actor = bob.GetLink()
+	if _, err := pub.InboxProcessorNew(s).Process(l); err != nil {
+		t.Fatal(err)
+	}
+	likes, err := s.Load(note.Likes.GetLink())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = pub.OnOrderedCollection(likes, func(c *pub.OrderedCollection) error {
+		if c.TotalItems != 1 || !c.OrderedItems.Contains(l.GetLink()) {
+			t.Errorf("%s should have been added to the likes of %s", l.ID, note.ID)
+		}
+		return nil
+	})
+
+	l = pub.LikeNew("https://example.com/~bob/activities/2", withoutLikes.GetLink())
+	l.Actor = bob.GetLink()
+	modified, err := pub.InboxProcessorNew(s).Process(l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(modified) > 0 {
+		t.Errorf("%s has no likes collection, nothing should have been modified, got %v", withoutLikes.ID, modified)
+	}
 }
 
 //S2S Server: Activity acceptance side-effects
@@ -551,7 +1065,29 @@ S2S Server: Activity acceptance side-effects
   Announce increments object's count of shares by adding the received activity to the
  'shares' collection if this collection is present
 `
-	t.Skip(desc)
+	t.Log(desc)
+
+	alice := pub.PersonNew("https://example.org/~alice")
+	bob := pub.PersonNew("https://example.com/~bob")
+	note := mockNote("https://example.org/~alice/notes/1", alice)
+	note.Shares = pub.IRI("https://example.org/~alice/notes/1/shares")
+	s := pub.MemoryStoreNew(alice, bob, note)
+
+	a := pub.AnnounceNew("https://example.com/~bob/activities/1", note.GetLink())
+	a.Actor = bob.GetLink()
+	if _, err := pub.InboxProcessorNew(s).Process(a); err != nil {
+		t.Fatal(err)
+	}
+	shares, err := s.Load(note.Shares.GetLink())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = pub.OnOrderedCollection(shares, func(c *pub.OrderedCollection) error {
+		if c.TotalItems != 1 || !c.OrderedItems.Contains(a.GetLink()) {
+			t.Errorf("%s should have been added to the shares of %s", a.ID, note.ID)
+		}
+		return nil
+	})
 }
 
 //S2S Server: Activity acceptance side-effects
@@ -565,5 +1101,94 @@ S2S Server: Activity acceptance side-effects
 
   Undo performs Undo of object in federated context
 `
-	t.Skip(desc)
+	t.Log(desc)
+
+	alice := pub.PersonNew("https://example.org/~alice")
+	bob := pub.PersonNew("https://example.com/~bob")
+	note := mockNote("https://example.org/~alice/notes/1", alice)
+	note.Likes = pub.IRI("https://example.org/~alice/notes/1/likes")
+	s := pub.MemoryStoreNew(alice, bob, note)
+	p := pub.InboxProcessorNew(s)
+	p.IsLocal = func(iri pub.IRI) bool { return strings.HasPrefix(iri.String(), "https://example.org/") }
+
+	f := pub.FollowNew("https://example.com/~bob/activities/1", alice.GetLink())
+	f.Actor = bob.GetLink()
+	l := pub.LikeNew("https://example.com/~bob/activities/2", note.GetLink())
+	l.Actor = bob.GetLink()
+	for _, act := range []pub.Item{f, l} {
+		if _, err := p.Process(act); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, act := range []*pub.Activity{f, l} {
+		u := pub.UndoNew("", act.GetLink())
+		u.Actor = bob.GetLink()
+		if _, err := p.Process(u); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if members(s, pub.Followers.IRI(alice)).Contains(bob.GetLink()) {
+		t.Errorf("%s should have been removed from the followers of %s", bob.ID, alice.ID)
+	}
+	if members(s, note.Likes.GetLink()).Contains(l.GetLink()) {
+		t.Errorf("%s should have been removed from the likes of %s", l.ID, note.ID)
+	}
+}
+
+func withInbox(p *pub.Person) *pub.Person {
+	p.Inbox = pub.IRI(p.ID + "/inbox")
+	return p
+}
+
+func withSharedInbox(p *pub.Person, shared pub.IRI) *pub.Person {
+	p = withInbox(p)
+	p.Endpoints = &pub.Endpoints{SharedInbox: shared}
+	return p
+}
+
+func mockLoader(items ...pub.Item) pub.LoadFn {
+	return func(iri pub.IRI) (pub.Item, error) {
+		for _, it := range items {
+			if it.GetLink().Equals(iri, true) {
+				return it, nil
+			}
+		}
+		return nil, fmt.Errorf("%s not found", iri)
+	}
+}
+
+func mockNote(id pub.ID, author pub.Item) *pub.Note {
+	n := pub.ObjectNew(pub.NoteType)
+	n.ID = id
+	n.AttributedTo = author.GetLink()
+	return n
+}
+
+// members returns the items of the col collection in the s store
+func members(s pub.Store, col pub.IRI) pub.ItemCollection {
+	var items pub.ItemCollection
+	if it, err := s.Load(col); err == nil {
+		_ = pub.OnCollectionIntf(it, func(c pub.CollectionInterface) error {
+			items = c.Collection()
+			return nil
+		})
+	}
+	return items
+}
+
+func mockForwarder() (pub.Store, *pub.Forwarder) {
+	s := pub.MemoryStoreNew(pub.PersonNew("https://local.example/~alice"))
+	isLocal := func(iri pub.IRI) bool {
+		return strings.HasPrefix(iri.String(), "https://local.example/")
+	}
+	return s, pub.ForwarderNew(s, isLocal)
+}
+
+func mockForwardedReply(id pub.ID, inReplyTo pub.IRI, to ...pub.Item) *pub.Activity {
+	ob := &pub.Object{ID: id.AddPath("object"), Type: pub.NoteType, InReplyTo: inReplyTo}
+	act := pub.CreateNew(id, ob)
+	act.Actor = pub.IRI("https://remote.example/~bob")
+	act.To = to
+	return act
 }
@@ -11,7 +11,7 @@ import (
 	"time"
 	"unsafe"
 
-	pub "github.com/go-ap/activitypub"
+	pub "github.com/snoymy/activitypub"
 
 	j "github.com/go-ap/jsonld"
 )
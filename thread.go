@@ -0,0 +1,259 @@
+package activitypub
+
+import (
+	"sort"
+
+	"github.com/go-ap/errors"
+)
+
+// MaxThreadFetches is the maximum number of missing ancestors ThreadNew loads using its Dereferencer
+const MaxThreadFetches = 100
+
+// ThreadNode is an item in a reply tree
+type ThreadNode struct {
+	Item Item
+	// Parent is the node the item replies to, nil for roots, orphans and the items which are part of a cycle
+	Parent *ThreadNode
+	// Replies are the nodes replying to the item, in chronological order
+	Replies []*ThreadNode
+}
+
+// Thread is a reply tree, built from the inReplyTo property of a list of objects.
+//
+// The items which are not replies are the Roots of the tree, the items replying to something
+// that's not available are Orphans, and the items which reply to each other in a loop are
+// detached from their parents and listed in Cycles, each with its own sub-tree.
+// All the lists of nodes are in chronological order, as given by ItemOrderTimestamp.
+type Thread struct {
+	Roots   []*ThreadNode
+	Orphans []*ThreadNode
+	Cycles  []*ThreadNode
+
+	nodes []*ThreadNode
+	index map[IRI]*ThreadNode
+}
+
+// ThreadNew builds the reply tree of the items.
+//
+// If d is not nil, it's used to load the ancestors which are missing from the items, up to
+// MaxThreadFetches of them. The items that fail to load are treated as missing, which makes their
+// replies orphans, and the first error which is not a NotFound one is returned along with the Thread.
+func ThreadNew(items ItemCollection, d Dereferencer) (*Thread, error) {
+	t := &Thread{index: make(map[IRI]*ThreadNode)}
+	for _, it := range items {
+		t.add(it)
+	}
+
+	var err error
+	if d != nil {
+		failed := make(map[IRI]struct{})
+		fetches := 0
+		for i := 0; i < len(t.nodes) && fetches < MaxThreadFetches; i++ {
+			parent := inReplyTo(t.nodes[i].Item)
+			if len(parent) == 0 || t.Node(parent) != nil {
+				continue
+			}
+			if _, ok := failed[parent]; ok {
+				continue
+			}
+			fetches++
+			it, lErr := d.Load(parent)
+			if lErr != nil || IsNil(it) {
+				failed[parent] = struct{}{}
+				if lErr != nil && !errors.IsNotFound(lErr) && err == nil {
+					err = lErr
+				}
+				continue
+			}
+			// the loaded parent is appended to the nodes, so its own parent gets loaded in turn
+			t.add(it)
+		}
+	}
+
+	for _, n := range t.nodes {
+		if parent := inReplyTo(n.Item); len(parent) > 0 {
+			n.Parent = t.Node(parent)
+		}
+	}
+	cyclic := t.detachCycles()
+
+	for _, n := range t.nodes {
+		switch {
+		case n.Parent != nil:
+			n.Parent.Replies = append(n.Parent.Replies, n)
+		case cyclic[n]:
+			t.Cycles = append(t.Cycles, n)
+		case len(inReplyTo(n.Item)) > 0:
+			t.Orphans = append(t.Orphans, n)
+		default:
+			t.Roots = append(t.Roots, n)
+		}
+	}
+	for _, n := range t.nodes {
+		sortNodes(n.Replies)
+	}
+	sortNodes(t.Roots)
+	sortNodes(t.Orphans)
+	sortNodes(t.Cycles)
+	return t, err
+}
+
+// add creates a node for the "it" item, if it's not already part of the thread
+func (t *Thread) add(it Item) {
+	if IsNil(it) || it.IsLink() {
+		return
+	}
+	iri := it.GetLink()
+	if len(iri) > 0 {
+		if t.Node(iri) != nil {
+			return
+		}
+	}
+	n := &ThreadNode{Item: it}
+	t.nodes = append(t.nodes, n)
+	if len(iri) > 0 {
+		t.index[iri] = n
+	}
+}
+
+// detachCycles removes the parent of the nodes which are part of a reply loop, and returns them
+func (t *Thread) detachCycles() map[*ThreadNode]bool {
+	cyclic := make(map[*ThreadNode]bool)
+	done := make(map[*ThreadNode]struct{})
+	for _, n := range t.nodes {
+		path := make(map[*ThreadNode]struct{})
+		var cycle []*ThreadNode
+		for cur := n; cur != nil; cur = cur.Parent {
+			if _, ok := done[cur]; ok {
+				break
+			}
+			if _, ok := path[cur]; ok {
+				// cur is where the loop closes, collect the nodes from it until we get back to it
+				for c := cur; ; {
+					cycle = append(cycle, c)
+					if c = c.Parent; c == cur {
+						break
+					}
+				}
+				break
+			}
+			path[cur] = struct{}{}
+		}
+		for p := range path {
+			done[p] = struct{}{}
+		}
+		for _, c := range cycle {
+			c.Parent = nil
+			cyclic[c] = true
+		}
+	}
+	return cyclic
+}
+
+// Node returns the node of the item identified by the iri, or nil if it's not part of the thread
+func (t *Thread) Node(iri IRI) *ThreadNode {
+	if n, ok := t.index[iri]; ok {
+		return n
+	}
+	for id, n := range t.index {
+		if id.Equals(iri, true) {
+			return n
+		}
+	}
+	return nil
+}
+
+// Items returns all the items in the thread
+func (t *Thread) Items() ItemCollection {
+	items := make(ItemCollection, 0, len(t.nodes))
+	for _, n := range t.nodes {
+		items = append(items, n.Item)
+	}
+	return items
+}
+
+// Ancestors returns the items the one identified by the iri replies to, starting with the root of its tree
+func (t *Thread) Ancestors(iri IRI) ItemCollection {
+	n := t.Node(iri)
+	if n == nil {
+		return nil
+	}
+	var ancestors ItemCollection
+	for p := n.Parent; p != nil; p = p.Parent {
+		ancestors = append(ItemCollection{p.Item}, ancestors...)
+	}
+	return ancestors
+}
+
+// Descendants returns all the replies to the item identified by the iri, depth first, with
+// each reply followed by its own replies
+func (t *Thread) Descendants(iri IRI) ItemCollection {
+	n := t.Node(iri)
+	if n == nil {
+		return nil
+	}
+	var descendants ItemCollection
+	var walk func(n *ThreadNode)
+	walk = func(n *ThreadNode) {
+		for _, r := range n.Replies {
+			descendants = append(descendants, r.Item)
+			walk(r)
+		}
+	}
+	walk(n)
+	return descendants
+}
+
+// Replies returns the direct replies to the item identified by the iri as an OrderedCollection,
+// with the ID of the item's replies collection
+func (t *Thread) Replies(iri IRI) (*OrderedCollection, error) {
+	n := t.Node(iri)
+	if n == nil {
+		return nil, errors.NotFoundf("%s is not part of the thread", iri)
+	}
+	col := OrderedCollectionNew(Replies.IRI(n.Item))
+	for _, r := range n.Replies {
+		col.OrderedItems = append(col.OrderedItems, r.Item)
+	}
+	col.TotalItems = uint(len(col.OrderedItems))
+	return col, nil
+}
+
+// Conversation returns the items in the thread which have the ctx context, in chronological order
+func (t *Thread) Conversation(ctx IRI) ItemCollection {
+	var items ItemCollection
+	for _, n := range t.nodes {
+		_ = OnObject(n.Item, func(o *Object) error {
+			if !IsNil(o.Context) && o.Context.GetLink().Equals(ctx, true) {
+				items = append(items, n.Item)
+			}
+			return nil
+		})
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return ItemOrderTimestamp(items[j], items[i])
+	})
+	return items
+}
+
+// inReplyTo returns the IRI of the first item "it" replies to
+func inReplyTo(it Item) IRI {
+	var iri IRI
+	_ = OnObject(it, func(o *Object) error {
+		for _, p := range objectsOf(o.InReplyTo) {
+			if !IsNil(p) {
+				iri = p.GetLink()
+				break
+			}
+		}
+		return nil
+	})
+	return iri
+}
+
+// sortNodes orders the nodes chronologically, ItemOrderTimestamp putting the newer items first
+func sortNodes(nodes []*ThreadNode) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return ItemOrderTimestamp(nodes[j].Item, nodes[i].Item)
+	})
+}
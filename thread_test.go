@@ -0,0 +1,126 @@
+package activitypub
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-ap/errors"
+)
+
+func mockReply(id, parent IRI, published time.Time) *Object {
+	ob := &Object{ID: id, Type: NoteType, Published: published}
+	if len(parent) > 0 {
+		ob.InReplyTo = parent
+	}
+	return ob
+}
+
+func threadIRIs(nodes []*ThreadNode) IRIs {
+	var iris IRIs
+	for _, n := range nodes {
+		iris = append(iris, n.Item.GetLink())
+	}
+	return iris
+}
+
+func itemIRIs(items ItemCollection) IRIs {
+	var iris IRIs
+	for _, it := range items {
+		iris = append(iris, it.GetLink())
+	}
+	return iris
+}
+
+func TestThreadNew(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(m int) time.Time { return now.Add(time.Duration(m) * time.Minute) }
+
+	root := mockReply("https://example.com/notes/root", "", at(0))
+	r1 := mockReply("https://example.com/notes/r1", root.ID, at(1))
+	r2 := mockReply("https://example.com/notes/r2", root.ID, at(5))
+	r11 := mockReply("https://example.com/notes/r11", r1.ID, at(2))
+	r111 := mockReply("https://example.com/notes/r111", r11.ID, at(3))
+	orphan := mockReply("https://example.com/notes/orphan", "https://example.com/notes/missing", at(4))
+	c1 := mockReply("https://example.com/notes/c1", "https://example.com/notes/c2", at(6))
+	c2 := mockReply("https://example.com/notes/c2", c1.ID, at(7))
+	c3 := mockReply("https://example.com/notes/c3", c2.ID, at(8))
+
+	th, err := ThreadNew(ItemCollection{r2, r111, c3, orphan, c2, root, r11, c1, r1}, nil)
+	if err != nil {
+		t.Fatalf("ThreadNew() error = %v", err)
+	}
+	if got, want := threadIRIs(th.Roots), (IRIs{root.ID}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Roots = %v, want %v", got, want)
+	}
+	if got, want := threadIRIs(th.Orphans), (IRIs{orphan.ID}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Orphans = %v, want %v", got, want)
+	}
+	if got, want := threadIRIs(th.Cycles), (IRIs{c1.ID, c2.ID}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Cycles = %v, want %v", got, want)
+	}
+	if got, want := itemIRIs(th.Descendants(root.ID)), (IRIs{r1.ID, r11.ID, r111.ID, r2.ID}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Descendants() = %v, want %v", got, want)
+	}
+	if got, want := itemIRIs(th.Ancestors(r111.ID)), (IRIs{root.ID, r1.ID, r11.ID}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Ancestors() = %v, want %v", got, want)
+	}
+	if got, want := itemIRIs(th.Descendants(c2.ID)), (IRIs{c3.ID}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Descendants() of a cycle = %v, want %v", got, want)
+	}
+	if th.Ancestors("https://example.com/notes/unknown") != nil {
+		t.Errorf("Ancestors() of an unknown item should be nil")
+	}
+
+	replies, err := th.Replies(root.ID)
+	if err != nil {
+		t.Fatalf("Replies() error = %v", err)
+	}
+	if replies.GetType() != OrderedCollectionType || replies.ID != "https://example.com/notes/root/replies" {
+		t.Errorf("Replies() = %s[%s], want an %s of the root's replies", replies.GetType(), replies.ID, OrderedCollectionType)
+	}
+	if got, want := itemIRIs(replies.OrderedItems), (IRIs{r1.ID, r2.ID}); !reflect.DeepEqual(got, want) || replies.TotalItems != 2 {
+		t.Errorf("Replies() items = %v, want %v", got, want)
+	}
+	if _, err = th.Replies("https://example.com/notes/unknown"); !errors.IsNotFound(err) {
+		t.Errorf("Replies() of an unknown item error = %v, want NotFound", err)
+	}
+}
+
+func TestThreadNew_Dereferencer(t *testing.T) {
+	root := mockReply("https://example.com/notes/root", "", time.Time{})
+	parent := mockReply("https://example.com/notes/parent", root.ID, time.Time{})
+	reply := mockReply("https://example.com/notes/reply", parent.ID, time.Time{})
+	orphan := mockReply("https://example.com/notes/orphan", "https://example.com/notes/missing", time.Time{})
+
+	s := MemoryStoreNew(root, parent)
+	th, err := ThreadNew(ItemCollection{reply, orphan}, s)
+	if err != nil {
+		t.Fatalf("ThreadNew() error = %v", err)
+	}
+	if got, want := itemIRIs(th.Ancestors(reply.ID)), (IRIs{root.ID, parent.ID}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Ancestors() = %v, want %v", got, want)
+	}
+	if got, want := threadIRIs(th.Orphans), (IRIs{orphan.ID}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Orphans = %v, want %v", got, want)
+	}
+
+	failing := LoadFn(func(iri IRI) (Item, error) {
+		return nil, errors.Newf("unable to load %s", iri)
+	})
+	if _, err = ThreadNew(ItemCollection{reply}, failing); err == nil {
+		t.Errorf("ThreadNew() should return the dereferencing error")
+	}
+}
+
+func TestThread_Conversation(t *testing.T) {
+	ctx := IRI("https://example.com/contexts/1")
+	first := &Object{ID: "https://example.com/notes/1", Type: NoteType, Context: ctx, Published: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	second := &Object{ID: "https://example.com/notes/2", Type: NoteType, Context: ctx, Published: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)}
+	other := &Object{ID: "https://example.com/notes/3", Type: NoteType, Context: IRI("https://example.com/contexts/2")}
+
+	th, _ := ThreadNew(ItemCollection{second, other, first}, nil)
+	if got, want := itemIRIs(th.Conversation(ctx)), (IRIs{first.ID, second.ID}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Conversation() = %v, want %v", got, want)
+	}
+}
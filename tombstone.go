@@ -106,6 +106,8 @@ type Tombstone struct {
 	// as a form of provenance, or to support future editing by clients.
 	// In general, clients do the conversion from source to content, not the other way around.
 	Source Source `jsonld:"source,omitempty"`
+
+	Extensions Extensions `jsonld:"-"`
 	// FormerType On a Tombstone object, the formerType property identifies the type of the object that was deleted.
 	FormerType ActivityVocabularyType `jsonld:"formerType,omitempty"`
 	// Deleted On a Tombstone object, the deleted property is a timestamp for when the object was deleted.
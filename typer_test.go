@@ -76,6 +76,18 @@ func TestCollectionTypes_Of(t *testing.T) {
 			},
 			want: IRI("test"),
 		},
+		{
+			name: "get followers iri of actor",
+			args: args{
+				o: &Actor{
+					ID:        "https://example.com/~jdoe",
+					Type:      PersonType,
+					Followers: IRI("https://example.com/~jdoe/subscribers"),
+				},
+				t: Followers,
+			},
+			want: IRI("https://example.com/~jdoe/subscribers"),
+		},
 	}
 
 	for _, test := range tests {
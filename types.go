@@ -7,6 +7,7 @@ type ActivityVocabularyTypes []ActivityVocabularyType
 var Types = ActivityVocabularyTypes{
 	LinkType,
 	MentionType,
+	HashtagType,
 
 	ArticleType,
 	AudioType,
@@ -63,4 +64,8 @@ var Types = ActivityVocabularyTypes{
 	ArriveType,
 	TravelType,
 	QuestionType,
+
+	EmojiType,
+	PropertyValueType,
+	IdentityProofType,
 }
@@ -1,5 +1,12 @@
 package activitypub
 
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-ap/errors"
+)
+
 // ValidationErrors is an aggregated error interface that allows
 // a Validator implementation to return all possible errors.
 type ValidationErrors interface {
@@ -14,3 +21,170 @@ type ValidationErrors interface {
 type Validator interface {
 	Validate(receiver IRI, incoming Item) (bool, ValidationErrors)
 }
+
+// ValidationErrorList is the ValidationErrors implementation returned by the DefaultValidator
+type ValidationErrorList []error
+
+var _ ValidationErrors = new(ValidationErrorList)
+
+// Error returns the messages of all the errors in the list
+func (v ValidationErrorList) Error() string {
+	msgs := make([]string, 0, len(v))
+	for _, err := range v {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Errors returns the errors in the list
+func (v ValidationErrorList) Errors() []error {
+	return v
+}
+
+// Unwrap returns the errors in the list, so they can be matched by errors.Is and errors.As
+func (v ValidationErrorList) Unwrap() []error {
+	return v
+}
+
+// Add appends the err error to the list. If err is a ValidationErrors itself, its errors are appended.
+func (v *ValidationErrorList) Add(err error) {
+	if err == nil {
+		return
+	}
+	if errs, ok := err.(ValidationErrors); ok {
+		for _, e := range errs.Errors() {
+			v.Add(e)
+		}
+		return
+	}
+	*v = append(*v, err)
+}
+
+// ValidationRule checks the incoming item received by the receiver against a single rule.
+// It can return multiple failures by returning a ValidationErrors.
+type ValidationRule func(receiver IRI, incoming Item) error
+
+// DefaultValidator is a Validator which checks the rules of the ActivityPub specification:
+//
+// - the IDs of the item, and of its actor, object and target, are absolute IRIs,
+// - activities have an actor,
+// - Create, Update, Delete, Follow, Add, Remove, Like, Block and Undo activities have an object,
+// - Add and Remove activities have a target,
+// - Questions don't have both oneOf and anyOf options,
+// - Tombstones have a formerType which is not Tombstone or a Link type.
+//
+// https://www.w3.org/TR/activitypub/#server-to-server-interactions
+type DefaultValidator struct {
+	// Rules are checked in addition to the default ones
+	Rules []ValidationRule
+}
+
+var _ Validator = DefaultValidator{}
+
+// ObjectRequiredActivityTypes are the activity types which need to have an object
+var ObjectRequiredActivityTypes = ActivityVocabularyTypes{
+	CreateType, UpdateType, DeleteType, FollowType, AddType, RemoveType, LikeType, BlockType, UndoType,
+}
+
+// TargetRequiredActivityTypes are the activity types which need to have a target
+var TargetRequiredActivityTypes = ActivityVocabularyTypes{AddType, RemoveType}
+
+// Validate checks the incoming item and returns all the failed rules
+func (d DefaultValidator) Validate(receiver IRI, incoming Item) (bool, ValidationErrors) {
+	errs := make(ValidationErrorList, 0)
+	if IsNil(incoming) {
+		errs.Add(errors.NotValidf("nil item"))
+		return false, &errs
+	}
+
+	if incoming.IsLink() {
+		errs.Add(validateAbsolute("IRI", incoming))
+	} else {
+		validateItem(&errs, incoming)
+	}
+	for _, rule := range d.Rules {
+		errs.Add(rule(receiver, incoming))
+	}
+
+	if len(errs) > 0 {
+		return false, &errs
+	}
+	return true, nil
+}
+
+// validateItem adds to errs the failures of the "it" item against the default rules
+func validateItem(errs ValidationErrors, it Item) {
+	typ := it.GetType()
+	if len(it.GetLink()) > 0 {
+		errs.Add(validateAbsolute(fmt.Sprintf("%s ID", typ), it))
+	}
+
+	if ActivityTypes.Contains(typ) || IntransitiveActivityTypes.Contains(typ) {
+		_ = OnIntransitiveActivity(it, func(act *IntransitiveActivity) error {
+			if IsNil(act.Actor) {
+				errs.Add(errors.NotValidf("missing actor for %s activity", typ))
+			} else {
+				errs.Add(validateAbsolute(fmt.Sprintf("%s actor", typ), act.Actor))
+			}
+			if IsNil(act.Target) {
+				if TargetRequiredActivityTypes.Contains(typ) {
+					errs.Add(errors.NotValidf("missing target for %s activity", typ))
+				}
+			} else {
+				errs.Add(validateAbsolute(fmt.Sprintf("%s target", typ), act.Target))
+			}
+			return nil
+		})
+	}
+	if ActivityTypes.Contains(typ) {
+		_ = OnActivity(it, func(act *Activity) error {
+			if IsNil(act.Object) {
+				if ObjectRequiredActivityTypes.Contains(typ) {
+					errs.Add(errors.NotValidf("missing object for %s activity", typ))
+				}
+				return nil
+			}
+			for _, ob := range objectsOf(act.Object) {
+				if IsNil(ob) {
+					continue
+				}
+				if ob.IsLink() {
+					errs.Add(validateAbsolute(fmt.Sprintf("%s object", typ), ob))
+					continue
+				}
+				validateItem(errs, ob)
+			}
+			return nil
+		})
+	}
+
+	switch typ {
+	case QuestionType:
+		_ = OnQuestion(it, func(q *Question) error {
+			if !IsNil(q.OneOf) && !IsNil(q.AnyOf) {
+				errs.Add(errors.NotValidf("%s can not have both oneOf and anyOf options", typ))
+			}
+			return nil
+		})
+	case TombstoneType:
+		_ = OnTombstone(it, func(t *Tombstone) error {
+			if t.FormerType == TombstoneType || LinkTypes.Contains(t.FormerType) {
+				errs.Add(errors.NotValidf("invalid formerType %s for %s", t.FormerType, typ))
+			}
+			return nil
+		})
+	}
+}
+
+// validateAbsolute returns an error if the IRI of "it" is not an absolute IRI.
+// Embedded items without an ID are considered valid.
+func validateAbsolute(name string, it Item) error {
+	iri := it.GetLink()
+	if len(iri) == 0 && !it.IsLink() {
+		return nil
+	}
+	if u, err := iri.URL(); err != nil || !u.IsAbs() || len(u.Host) == 0 {
+		return errors.NotValidf("%s %q is not an absolute IRI", name, iri)
+	}
+	return nil
+}
@@ -1,7 +1,117 @@
 package activitypub
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/go-ap/errors"
+)
 
 func TestDefaultValidator_Validate(t *testing.T) {
-	t.Skipf("TODO")
+	alice := IRI("https://example.com/~alice")
+	note := IRI("https://example.com/notes/1")
+	featured := IRI("https://example.com/~alice/featured")
+
+	tests := []struct {
+		name     string
+		rules    []ValidationRule
+		incoming Item
+		wantErrs int
+	}{
+		{
+			name:     "nil",
+			wantErrs: 1,
+		},
+		{
+			name:     "absolute IRI",
+			incoming: note,
+		},
+		{
+			name:     "relative IRI",
+			incoming: IRI("/notes/1"),
+			wantErrs: 1,
+		},
+		{
+			name:     "valid create",
+			incoming: &Activity{ID: "https://example.com/activities/1", Type: CreateType, Actor: alice, Object: &Object{Type: NoteType}},
+		},
+		{
+			name:     "missing actor and object",
+			incoming: &Activity{ID: "https://example.com/activities/1", Type: LikeType},
+			wantErrs: 2,
+		},
+		{
+			name:     "add without target",
+			incoming: &Activity{Type: AddType, Actor: alice, Object: note},
+			wantErrs: 1,
+		},
+		{
+			name:     "valid remove",
+			incoming: &Activity{Type: RemoveType, Actor: alice, Object: note, Target: featured},
+		},
+		{
+			name:     "relative IDs",
+			incoming: &Activity{ID: "activities/1", Type: UndoType, Actor: IRI("~alice"), Object: IRI("activities/0")},
+			wantErrs: 3,
+		},
+		{
+			name:     "arrive doesn't need an object",
+			incoming: &IntransitiveActivity{Type: ArriveType, Actor: alice},
+		},
+		{
+			name:     "question with both oneOf and anyOf",
+			incoming: &Question{Type: QuestionType, Actor: alice, OneOf: ItemCollection{note}, AnyOf: ItemCollection{note}},
+			wantErrs: 1,
+		},
+		{
+			name:     "tombstone of a tombstone",
+			incoming: &Tombstone{ID: note, Type: TombstoneType, FormerType: TombstoneType},
+			wantErrs: 1,
+		},
+		{
+			name:     "update with a tombstone",
+			incoming: &Activity{Type: UpdateType, Actor: alice, Object: &Tombstone{ID: note, Type: TombstoneType, FormerType: LinkType}},
+			wantErrs: 1,
+		},
+		{
+			name: "additional rules",
+			rules: []ValidationRule{
+				func(receiver IRI, incoming Item) error {
+					return errors.Forbiddenf("%s doesn't accept anything", receiver)
+				},
+			},
+			incoming: note,
+			wantErrs: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := DefaultValidator{Rules: tt.rules}
+			valid, errs := v.Validate("https://example.com/~bob/inbox", tt.incoming)
+			if valid != (tt.wantErrs == 0) {
+				t.Errorf("Validate() = %t, errors %v", valid, errs)
+			}
+			if tt.wantErrs == 0 {
+				if errs != nil {
+					t.Errorf("Validate() errors = %v, want none", errs)
+				}
+				return
+			}
+			if len(errs.Errors()) != tt.wantErrs {
+				t.Errorf("Validate() errors = %v, want %d errors", errs, tt.wantErrs)
+			}
+		})
+	}
+}
+
+func TestValidationErrorList_Add(t *testing.T) {
+	errs := ValidationErrorList{}
+	errs.Add(nil)
+	errs.Add(errors.NotValidf("first"))
+	errs.Add(&ValidationErrorList{errors.NotValidf("second"), errors.NotValidf("third")})
+	if len(errs.Errors()) != 3 {
+		t.Errorf("Add() = %v, want 3 errors", errs)
+	}
+	if errs.Error() != "first; second; third" {
+		t.Errorf("Error() = %q", errs.Error())
+	}
 }
@@ -0,0 +1,139 @@
+package activitypub
+
+// Visibility describes who can see an object or activity, as derived from its addressing
+type Visibility string
+
+const (
+	// PublicVisibility is for items addressed to the Public collection in their "to" property
+	PublicVisibility Visibility = "public"
+	// UnlistedVisibility is for items addressed to the Public collection only in their "cc" property,
+	// which are not shown in the public timelines
+	UnlistedVisibility Visibility = "unlisted"
+	// FollowersVisibility is for items addressed to the followers of their author, and not to the Public collection
+	FollowersVisibility Visibility = "followers"
+	// LimitedVisibility is for items addressed to other collections than the followers of their author,
+	// and not to the Public collection
+	LimitedVisibility Visibility = "limited"
+	// DirectVisibility is for items addressed only to individual actors
+	DirectVisibility Visibility = "direct"
+)
+
+// Visibility returns the visibility of the Object, based on its addressing and the
+// followers collection of the actor it's attributed to
+func (o Object) Visibility() Visibility {
+	var author Item
+	if authors := objectsOf(o.AttributedTo); len(authors) > 0 {
+		author = authors.First()
+	}
+	return visibilityOf(author, o.To, o.CC, o.Bto, o.BCC)
+}
+
+// Visibility returns the visibility of the Activity, based on its addressing and the
+// followers collection of its actor
+func (a Activity) Visibility() Visibility {
+	return visibilityOf(a.Actor, a.To, a.CC, a.Bto, a.BCC)
+}
+
+// Visibility returns the visibility of the IntransitiveActivity, based on its addressing and the
+// followers collection of its actor
+func (i IntransitiveActivity) Visibility() Visibility {
+	return visibilityOf(i.Actor, i.To, i.CC, i.Bto, i.BCC)
+}
+
+// visibilityOf classifies the addressing of an item authored by author, in the same way Mastodon does:
+// Public in "to" is public, Public in "cc" is unlisted, the author's followers in "to" is followers only.
+// The rest of the items are limited if they are addressed to a collection, or direct otherwise.
+func visibilityOf(author Item, to, cc, bto, bcc ItemCollection) Visibility {
+	for _, rec := range to {
+		if !IsNil(rec) && isPublicCollection(rec.GetLink()) {
+			return PublicVisibility
+		}
+	}
+	for _, rec := range cc {
+		if !IsNil(rec) && isPublicCollection(rec.GetLink()) {
+			return UnlistedVisibility
+		}
+	}
+	if followers := followersOf(author); len(followers) > 0 && to.Contains(followers) {
+		return FollowersVisibility
+	}
+	for _, aud := range []ItemCollection{to, cc, bto, bcc} {
+		for _, rec := range aud {
+			if isCollectionRecipient(rec) {
+				return LimitedVisibility
+			}
+		}
+	}
+	return DirectVisibility
+}
+
+// followersOf returns the IRI of the followers collection of the author
+func followersOf(author Item) IRI {
+	if IsNil(author) {
+		return ""
+	}
+	col := Followers.Of(author)
+	if IsNil(col) {
+		return ""
+	}
+	return col.GetLink()
+}
+
+// isCollectionRecipient returns true if rec is an embedded collection, or an IRI which looks like one
+func isCollectionRecipient(rec Item) bool {
+	if IsNil(rec) {
+		return false
+	}
+	if !rec.IsLink() {
+		return rec.IsCollection()
+	}
+	_, path := Split(rec.GetLink())
+	return path != Unknown
+}
+
+// Addressing returns the canonical "to" and "cc" recipients of an item authored by author, having
+// the v visibility, and mentioning the mentions actors or collections.
+//
+// The followers collection of the author is located using CollectionPath.Of, and the Public collection
+// is always addressed through its full IRI.
+func Addressing(v Visibility, author Item, mentions ...Item) (to ItemCollection, cc ItemCollection) {
+	followers := followersOf(author)
+	mentioned := make(ItemCollection, 0, len(mentions))
+	for _, m := range mentions {
+		if !IsNil(m) && !mentioned.Contains(m.GetLink()) {
+			mentioned = append(mentioned, m.GetLink())
+		}
+	}
+
+	switch v {
+	case PublicVisibility:
+		to = ItemCollection{PublicNS}
+		cc = withRecipient(followers, mentioned)
+	case UnlistedVisibility:
+		to = withRecipient(followers, nil)
+		cc = append(ItemCollection{PublicNS}, mentioned...)
+	case FollowersVisibility:
+		to = withRecipient(followers, nil)
+		cc = mentioned
+	default:
+		to = mentioned
+	}
+	if len(cc) == 0 {
+		cc = nil
+	}
+	return to, cc
+}
+
+// withRecipient returns the rest collection, with the iri recipient prepended if it's not empty
+func withRecipient(iri IRI, rest ItemCollection) ItemCollection {
+	col := make(ItemCollection, 0, len(rest)+1)
+	if len(iri) > 0 {
+		col = append(col, iri)
+	}
+	for _, it := range rest {
+		if !col.Contains(it) {
+			col = append(col, it)
+		}
+	}
+	return col
+}
@@ -0,0 +1,127 @@
+package activitypub
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestObject_Visibility(t *testing.T) {
+	alice := IRI("https://example.com/~alice")
+	bob := IRI("https://example.com/~bob")
+	followers := IRI("https://example.com/~alice/followers")
+
+	tests := []struct {
+		name string
+		ob   Object
+		want Visibility
+	}{
+		{
+			name: "public",
+			ob:   Object{AttributedTo: alice, To: ItemCollection{PublicNS}, CC: ItemCollection{followers}},
+			want: PublicVisibility,
+		},
+		{
+			name: "public compacted alias",
+			ob:   Object{AttributedTo: alice, To: ItemCollection{IRI("as:Public")}},
+			want: PublicVisibility,
+		},
+		{
+			name: "public short alias",
+			ob:   Object{AttributedTo: alice, To: ItemCollection{IRI("Public")}},
+			want: PublicVisibility,
+		},
+		{
+			name: "unlisted",
+			ob:   Object{AttributedTo: alice, To: ItemCollection{followers}, CC: ItemCollection{PublicNS}},
+			want: UnlistedVisibility,
+		},
+		{
+			name: "followers only",
+			ob:   Object{AttributedTo: alice, To: ItemCollection{followers}, CC: ItemCollection{bob}},
+			want: FollowersVisibility,
+		},
+		{
+			name: "followers collection of an embedded actor",
+			ob: Object{
+				AttributedTo: &Actor{ID: alice, Type: PersonType, Followers: IRI("https://example.com/followers/alice")},
+				To:           ItemCollection{IRI("https://example.com/followers/alice")},
+			},
+			want: FollowersVisibility,
+		},
+		{
+			name: "limited to someone else's followers",
+			ob:   Object{AttributedTo: alice, To: ItemCollection{IRI("https://example.com/~bob/followers")}},
+			want: LimitedVisibility,
+		},
+		{
+			name: "limited to an embedded collection",
+			ob:   Object{AttributedTo: alice, To: ItemCollection{&Collection{ID: "https://example.com/circles/1", Type: CollectionType}}},
+			want: LimitedVisibility,
+		},
+		{
+			name: "direct",
+			ob:   Object{AttributedTo: alice, To: ItemCollection{bob}},
+			want: DirectVisibility,
+		},
+		{
+			name: "no recipients",
+			ob:   Object{AttributedTo: alice},
+			want: DirectVisibility,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.ob.Visibility(); got != tt.want {
+				t.Errorf("Visibility() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestActivity_Visibility(t *testing.T) {
+	alice := IRI("https://example.com/~alice")
+	act := Activity{Type: CreateType, Actor: alice, To: ItemCollection{alice.AddPath("followers")}}
+	if got := act.Visibility(); got != FollowersVisibility {
+		t.Errorf("Visibility() = %s, want %s", got, FollowersVisibility)
+	}
+	act.CC = ItemCollection{IRI("as:Public")}
+	if got := act.Visibility(); got != UnlistedVisibility {
+		t.Errorf("Visibility() = %s, want %s", got, UnlistedVisibility)
+	}
+}
+
+func TestAddressing(t *testing.T) {
+	alice := &Actor{ID: "https://example.com/~alice", Type: PersonType}
+	bob := IRI("https://example.com/~bob")
+	followers := IRI("https://example.com/~alice/followers")
+
+	tests := []struct {
+		v      Visibility
+		to, cc ItemCollection
+	}{
+		{v: PublicVisibility, to: ItemCollection{PublicNS}, cc: ItemCollection{followers, bob}},
+		{v: UnlistedVisibility, to: ItemCollection{followers}, cc: ItemCollection{PublicNS, bob}},
+		{v: FollowersVisibility, to: ItemCollection{followers}, cc: ItemCollection{bob}},
+		{v: LimitedVisibility, to: ItemCollection{bob}},
+		{v: DirectVisibility, to: ItemCollection{bob}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.v), func(t *testing.T) {
+			to, cc := Addressing(tt.v, alice, bob, bob)
+			if !reflect.DeepEqual(to, tt.to) {
+				t.Errorf("Addressing() to = %v, want %v", to, tt.to)
+			}
+			if !reflect.DeepEqual(cc, tt.cc) {
+				t.Errorf("Addressing() cc = %v, want %v", cc, tt.cc)
+			}
+			if tt.v == LimitedVisibility {
+				// without addressing a collection, this is indistinguishable from direct
+				return
+			}
+			ob := Object{AttributedTo: alice, To: to, CC: cc}
+			if got := ob.Visibility(); got != tt.v {
+				t.Errorf("Visibility() of the addressed object = %s, want %s", got, tt.v)
+			}
+		})
+	}
+}
@@ -0,0 +1,252 @@
+// Package webfinger implements the WebFinger JSON Resource Descriptor documents used by
+// ActivityPub servers to map "acct:user@host" accounts to the IRIs of their actors.
+//
+// https://www.rfc-editor.org/rfc/rfc7033
+package webfinger
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/go-ap/errors"
+	vocab "github.com/snoymy/activitypub"
+	"github.com/valyala/fastjson"
+)
+
+const (
+	// WellKnownPath is the path at which servers publish WebFinger resources
+	WellKnownPath = "/.well-known/webfinger"
+	// ContentType is the media type of JRD documents
+	ContentType = "application/jrd+json"
+
+	// RelSelf is the link relation pointing to the ActivityPub actor of the account
+	RelSelf = "self"
+	// RelProfilePage is the link relation pointing to the HTML profile of the account
+	RelProfilePage = "http://webfinger.net/rel/profile-page"
+	// RelSubscribe is the link relation for the remote follow template used by Mastodon
+	RelSubscribe = "http://ostatus.org/schema/1.0/subscribe"
+
+	// ActivityJSONType is the media type of ActivityPub documents
+	ActivityJSONType = "application/activity+json"
+	// LDJSONType is the alternative media type of ActivityPub documents, with the ActivityStreams profile
+	LDJSONType = `application/ld+json; profile="https://www.w3.org/ns/activitystreams"`
+)
+
+// Link represents a link relation of a JRD document
+//
+// https://www.rfc-editor.org/rfc/rfc7033#section-4.4.4
+type Link struct {
+	Rel        string
+	Type       string
+	Href       vocab.IRI
+	Template   string
+	Titles     map[string]string
+	Properties map[string]string
+}
+
+// JRD represents a JSON Resource Descriptor document
+//
+// https://www.rfc-editor.org/rfc/rfc7033#section-4.4
+type JRD struct {
+	Subject    string
+	Aliases    []string
+	Properties map[string]string
+	Links      []Link
+}
+
+// Link returns the first link with the received relation and, if typ is not empty, media type.
+// It returns nil if no such link exists.
+func (j JRD) Link(rel, typ string) *Link {
+	for i, l := range j.Links {
+		if l.Rel != rel {
+			continue
+		}
+		if len(typ) > 0 && !sameMediaType(l.Type, typ) {
+			continue
+		}
+		return &j.Links[i]
+	}
+	return nil
+}
+
+// ActorIRI returns the IRI of the ActivityPub actor from the "self" link of the document
+func (j JRD) ActorIRI() (vocab.IRI, error) {
+	for _, typ := range []string{ActivityJSONType, LDJSONType} {
+		if l := j.Link(RelSelf, typ); l != nil && len(l.Href) > 0 {
+			return l.Href, nil
+		}
+	}
+	return "", errors.NotFoundf("no ActivityPub actor link for %s", j.Subject)
+}
+
+// sameMediaType compares two media types, ignoring the spaces between their parameters
+func sameMediaType(a, b string) bool {
+	norm := func(s string) string {
+		return strings.ToLower(strings.ReplaceAll(s, " ", ""))
+	}
+	return norm(a) == norm(b)
+}
+
+// MarshalJSON encodes the receiver object to a JSON document.
+func (j JRD) MarshalJSON() ([]byte, error) {
+	b := make([]byte, 0)
+	vocab.JSONWrite(&b, '{')
+	writeStringProp(&b, "subject", j.Subject)
+	if len(j.Aliases) > 0 {
+		a := make([]byte, 0)
+		vocab.JSONWrite(&a, '[')
+		for i, alias := range j.Aliases {
+			if i > 0 {
+				vocab.JSONWrite(&a, ',')
+			}
+			writeString(&a, alias)
+		}
+		vocab.JSONWrite(&a, ']')
+		vocab.JSONWriteProp(&b, "aliases", a)
+	}
+	writeMapProp(&b, "properties", j.Properties)
+	if len(j.Links) > 0 {
+		l := make([]byte, 0)
+		vocab.JSONWrite(&l, '[')
+		for i, link := range j.Links {
+			if i > 0 {
+				vocab.JSONWrite(&l, ',')
+			}
+			v, _ := link.MarshalJSON()
+			vocab.JSONWrite(&l, v...)
+		}
+		vocab.JSONWrite(&l, ']')
+		vocab.JSONWriteProp(&b, "links", l)
+	}
+	vocab.JSONWrite(&b, '}')
+	return b, nil
+}
+
+// UnmarshalJSON decodes an incoming JSON document into the receiver object.
+func (j *JRD) UnmarshalJSON(data []byte) error {
+	p := fastjson.Parser{}
+	val, err := p.ParseBytes(data)
+	if err != nil {
+		return err
+	}
+	return JSONLoadJRD(val, j)
+}
+
+// MarshalJSON encodes the receiver object to a JSON document.
+func (l Link) MarshalJSON() ([]byte, error) {
+	b := make([]byte, 0)
+	vocab.JSONWrite(&b, '{')
+	writeStringProp(&b, "rel", l.Rel)
+	writeStringProp(&b, "type", l.Type)
+	writeStringProp(&b, "href", l.Href.String())
+	writeStringProp(&b, "template", l.Template)
+	writeMapProp(&b, "titles", l.Titles)
+	writeMapProp(&b, "properties", l.Properties)
+	vocab.JSONWrite(&b, '}')
+	return b, nil
+}
+
+// UnmarshalJSON decodes an incoming JSON document into the receiver object.
+func (l *Link) UnmarshalJSON(data []byte) error {
+	p := fastjson.Parser{}
+	val, err := p.ParseBytes(data)
+	if err != nil {
+		return err
+	}
+	return JSONLoadLink(val, l)
+}
+
+// JSONLoadJRD loads the properties of val into j
+func JSONLoadJRD(val *fastjson.Value, j *JRD) error {
+	if val.Type() != fastjson.TypeObject {
+		return errors.NotValidf("JRD document must be a JSON object")
+	}
+	j.Subject = vocab.JSONGetString(val, "subject")
+	for _, a := range val.GetArray("aliases") {
+		if s, err := a.StringBytes(); err == nil {
+			j.Aliases = append(j.Aliases, string(s))
+		}
+	}
+	j.Properties = jsonGetMap(val, "properties")
+	for _, v := range val.GetArray("links") {
+		l := Link{}
+		if err := JSONLoadLink(v, &l); err != nil {
+			return err
+		}
+		j.Links = append(j.Links, l)
+	}
+	return nil
+}
+
+// JSONLoadLink loads the properties of val into l
+func JSONLoadLink(val *fastjson.Value, l *Link) error {
+	if val.Type() != fastjson.TypeObject {
+		return errors.NotValidf("JRD link must be a JSON object")
+	}
+	l.Rel = vocab.JSONGetString(val, "rel")
+	l.Type = vocab.JSONGetString(val, "type")
+	l.Href = vocab.IRI(vocab.JSONGetString(val, "href"))
+	l.Template = vocab.JSONGetString(val, "template")
+	l.Titles = jsonGetMap(val, "titles")
+	l.Properties = jsonGetMap(val, "properties")
+	return nil
+}
+
+// jsonGetMap loads the string members of the prop object, JSON null values are loaded as empty strings
+func jsonGetMap(val *fastjson.Value, prop string) map[string]string {
+	ob := val.GetObject(prop)
+	if ob == nil || ob.Len() == 0 {
+		return nil
+	}
+	m := make(map[string]string, ob.Len())
+	ob.Visit(func(key []byte, v *fastjson.Value) {
+		s, _ := v.StringBytes()
+		m[string(key)] = string(s)
+	})
+	return m
+}
+
+func writeString(b *[]byte, s string) {
+	// unlike the ActivityStreams IRIs, JRD titles and properties are free text,
+	// so they need to be escaped.
+	v, _ := json.Marshal(s)
+	vocab.JSONWrite(b, v...)
+}
+
+func writeStringProp(b *[]byte, n, s string) (notEmpty bool) {
+	if len(s) == 0 {
+		return false
+	}
+	v := make([]byte, 0, len(s)+2)
+	writeString(&v, s)
+	return vocab.JSONWriteProp(b, n, v)
+}
+
+func writeMapProp(b *[]byte, n string, m map[string]string) (notEmpty bool) {
+	if len(m) == 0 {
+		return false
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	v := make([]byte, 0)
+	vocab.JSONWrite(&v, '{')
+	for i, k := range keys {
+		if i > 0 {
+			vocab.JSONWrite(&v, ',')
+		}
+		writeString(&v, k)
+		vocab.JSONWrite(&v, ':')
+		if len(m[k]) == 0 {
+			vocab.JSONWriteS(&v, "null")
+			continue
+		}
+		writeString(&v, m[k])
+	}
+	vocab.JSONWrite(&v, '}')
+	return vocab.JSONWriteProp(b, n, v)
+}
@@ -0,0 +1,148 @@
+package webfinger
+
+import (
+	"reflect"
+	"testing"
+
+	vocab "github.com/snoymy/activitypub"
+)
+
+const mastodonJRD = `{"subject":"acct:jdoe@example.com","aliases":["https://example.com/@jdoe","https://example.com/users/jdoe"],"links":[{"rel":"http://webfinger.net/rel/profile-page","type":"text/html","href":"https://example.com/@jdoe"},{"rel":"self","type":"application/activity+json","href":"https://example.com/users/jdoe"},{"rel":"http://ostatus.org/schema/1.0/subscribe","template":"https://example.com/authorize_interaction?uri={uri}"}]}`
+
+var mastodonDoc = JRD{
+	Subject: "acct:jdoe@example.com",
+	Aliases: []string{"https://example.com/@jdoe", "https://example.com/users/jdoe"},
+	Links: []Link{
+		{Rel: RelProfilePage, Type: "text/html", Href: "https://example.com/@jdoe"},
+		{Rel: RelSelf, Type: ActivityJSONType, Href: "https://example.com/users/jdoe"},
+		{Rel: RelSubscribe, Template: "https://example.com/authorize_interaction?uri={uri}"},
+	},
+}
+
+func TestJRD_MarshalJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		j    JRD
+		want string
+	}{
+		{
+			name: "empty",
+			j:    JRD{},
+			want: `{}`,
+		},
+		{
+			name: "mastodon",
+			j:    mastodonDoc,
+			want: mastodonJRD,
+		},
+		{
+			name: "properties and titles",
+			j: JRD{
+				Subject:    "https://example.com/",
+				Properties: map[string]string{"http://example.com/ns/role": "instance", "http://example.com/ns/empty": ""},
+				Links: []Link{
+					{Rel: "author", Href: "https://example.com/~jdoe", Titles: map[string]string{"en": `John "jd" Doe`}},
+				},
+			},
+			want: `{"subject":"https://example.com/","properties":{"http://example.com/ns/empty":null,"http://example.com/ns/role":"instance"},"links":[{"rel":"author","href":"https://example.com/~jdoe","titles":{"en":"John \"jd\" Doe"}}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.j.MarshalJSON()
+			if err != nil {
+				t.Fatalf("MarshalJSON() error = %s", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("MarshalJSON() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJRD_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    JRD
+		wantErr bool
+	}{
+		{
+			name: "mastodon",
+			data: mastodonJRD,
+			want: mastodonDoc,
+		},
+		{
+			name: "null property",
+			data: `{"subject":"acct:jdoe@example.com","properties":{"http://example.com/ns/empty":null}}`,
+			want: JRD{Subject: "acct:jdoe@example.com", Properties: map[string]string{"http://example.com/ns/empty": ""}},
+		},
+		{
+			name:    "not an object",
+			data:    `["acct:jdoe@example.com"]`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid JSON",
+			data:    `{"subject":`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := JRD{}
+			err := got.UnmarshalJSON([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UnmarshalJSON() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJRD_ActorIRI(t *testing.T) {
+	tests := []struct {
+		name    string
+		j       JRD
+		want    vocab.IRI
+		wantErr bool
+	}{
+		{
+			name: "activity+json",
+			j:    mastodonDoc,
+			want: "https://example.com/users/jdoe",
+		},
+		{
+			name: "ld+json",
+			j: JRD{Links: []Link{
+				{Rel: RelSelf, Type: `application/ld+json;profile="https://www.w3.org/ns/activitystreams"`, Href: "https://example.com/~jdoe"},
+			}},
+			want: "https://example.com/~jdoe",
+		},
+		{
+			name: "self link with other type",
+			j: JRD{Links: []Link{
+				{Rel: RelSelf, Type: "text/html", Href: "https://example.com/~jdoe"},
+			}},
+			wantErr: true,
+		},
+		{
+			name:    "no links",
+			j:       JRD{Subject: "acct:jdoe@example.com"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.j.ActorIRI()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ActorIRI() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ActorIRI() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package webfinger
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-ap/errors"
+	vocab "github.com/snoymy/activitypub"
+)
+
+// Client is the interface of the HTTP client used by the Resolver, it is satisfied by *http.Client
+type Client interface {
+	Do(*http.Request) (*http.Response, error)
+}
+
+// Resolver looks up WebFinger resources on remote servers
+type Resolver struct {
+	// Client is used for the requests, if nil http.DefaultClient is used
+	Client Client
+}
+
+// NewResolver initializes a Resolver which uses the received HTTP client
+func NewResolver(c Client) *Resolver {
+	return &Resolver{Client: c}
+}
+
+// ParseAccount splits an account URI into its user and host parts.
+// Besides the "acct:user@host" form, the "@user@host" and "user@host" forms are accepted.
+func ParseAccount(acct string) (user, host string, err error) {
+	acct = strings.TrimPrefix(strings.TrimSpace(acct), "acct:")
+	acct = strings.TrimPrefix(acct, "@")
+	user, host, ok := strings.Cut(acct, "@")
+	if !ok || len(user) == 0 || len(host) == 0 || strings.ContainsAny(host, "@/") {
+		return "", "", errors.NotValidf("invalid account %q", acct)
+	}
+	return user, host, nil
+}
+
+// Lookup fetches the JRD document for the resource from the WebFinger endpoint of host
+func (r Resolver) Lookup(ctx context.Context, host, resource string) (*JRD, error) {
+	u := url.URL{
+		Scheme:   "https",
+		Host:     host,
+		Path:     WellKnownPath,
+		RawQuery: url.Values{"resource": []string{resource}}.Encode(),
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, errors.Annotatef(err, "unable to build WebFinger request")
+	}
+	req.Header.Set("Accept", ContentType)
+
+	cl := r.Client
+	if cl == nil {
+		cl = http.DefaultClient
+	}
+	res, err := cl.Do(req)
+	if err != nil {
+		return nil, errors.Annotatef(err, "unable to fetch WebFinger resource %s", resource)
+	}
+	defer res.Body.Close()
+
+	switch {
+	case res.StatusCode == http.StatusNotFound:
+		return nil, errors.NotFoundf("WebFinger resource %s not found", resource)
+	case res.StatusCode != http.StatusOK:
+		return nil, errors.Newf("unexpected status %d for WebFinger resource %s", res.StatusCode, resource)
+	}
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, errors.Annotatef(err, "unable to read WebFinger resource %s", resource)
+	}
+	j := JRD{}
+	if err = j.UnmarshalJSON(data); err != nil {
+		return nil, errors.NewNotValid(err, "invalid WebFinger resource %s", resource)
+	}
+	return &j, nil
+}
+
+// ResolveActor returns the IRI of the ActivityPub actor corresponding to the account
+func (r Resolver) ResolveActor(ctx context.Context, acct string) (vocab.IRI, error) {
+	user, host, err := ParseAccount(acct)
+	if err != nil {
+		return "", err
+	}
+	j, err := r.Lookup(ctx, host, "acct:"+user+"@"+host)
+	if err != nil {
+		return "", err
+	}
+	return j.ActorIRI()
+}
+
+// FromActor builds the JRD document describing the actor, with the account built from its
+// PreferredUsername and the host of its ID, and links to the actor and its profile page URL.
+func FromActor(it vocab.Item) (*JRD, error) {
+	var j *JRD
+	err := vocab.OnActor(it, func(a *vocab.Actor) error {
+		name := a.PreferredUsername.First().Value.String()
+		if len(name) == 0 {
+			return errors.NotValidf("actor %s has no preferred username", a.ID)
+		}
+		u, err := a.ID.URL()
+		if err != nil || len(u.Host) == 0 {
+			return errors.NotValidf("actor has invalid ID %q", a.ID)
+		}
+
+		j = &JRD{
+			Subject: "acct:" + name + "@" + u.Host,
+			Aliases: []string{a.ID.String()},
+			Links: []Link{
+				{Rel: RelSelf, Type: ActivityJSONType, Href: a.ID},
+			},
+		}
+		if !vocab.IsNil(a.URL) {
+			if profile := a.URL.GetLink(); len(profile) > 0 && profile != a.ID {
+				j.Aliases = append(j.Aliases, profile.String())
+				j.Links = append(j.Links, Link{Rel: RelProfilePage, Type: "text/html", Href: profile})
+			}
+		}
+		return nil
+	})
+	return j, err
+}
@@ -0,0 +1,161 @@
+package webfinger
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	vocab "github.com/snoymy/activitypub"
+)
+
+func TestParseAccount(t *testing.T) {
+	tests := []struct {
+		acct     string
+		wantUser string
+		wantHost string
+		wantErr  bool
+	}{
+		{acct: "acct:jdoe@example.com", wantUser: "jdoe", wantHost: "example.com"},
+		{acct: "@jdoe@example.com", wantUser: "jdoe", wantHost: "example.com"},
+		{acct: "jdoe@example.com:8443", wantUser: "jdoe", wantHost: "example.com:8443"},
+		{acct: "acct:jdoe", wantErr: true},
+		{acct: "@example.com", wantErr: true},
+		{acct: "jdoe@example.com/inbox", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.acct, func(t *testing.T) {
+			user, host, err := ParseAccount(tt.acct)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseAccount() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if user != tt.wantUser || host != tt.wantHost {
+				t.Errorf("ParseAccount() = %s, %s, want %s, %s", user, host, tt.wantUser, tt.wantHost)
+			}
+		})
+	}
+}
+
+func TestResolver_ResolveActor(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != WellKnownPath {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if accept := r.Header.Get("Accept"); accept != ContentType {
+			t.Errorf("WebFinger request Accept header = %s, want %s", accept, ContentType)
+		}
+		res := r.URL.Query().Get("resource")
+		if !strings.HasPrefix(res, "acct:jdoe@") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		j := JRD{
+			Subject: res,
+			Links:   []Link{{Rel: RelSelf, Type: ActivityJSONType, Href: "https://example.com/users/jdoe"}},
+		}
+		data, _ := j.MarshalJSON()
+		w.Header().Set("Content-Type", ContentType)
+		_, _ = w.Write(data)
+	}))
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "https://")
+
+	tests := []struct {
+		name    string
+		acct    string
+		want    vocab.IRI
+		wantErr bool
+	}{
+		{
+			name: "found",
+			acct: "acct:jdoe@" + host,
+			want: "https://example.com/users/jdoe",
+		},
+		{
+			name: "mention form",
+			acct: "@jdoe@" + host,
+			want: "https://example.com/users/jdoe",
+		},
+		{
+			name:    "not found",
+			acct:    "acct:alice@" + host,
+			wantErr: true,
+		},
+		{
+			name:    "invalid account",
+			acct:    "jdoe",
+			wantErr: true,
+		},
+	}
+	r := NewResolver(srv.Client())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.ResolveActor(context.Background(), tt.acct)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveActor() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ResolveActor() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromActor(t *testing.T) {
+	tests := []struct {
+		name    string
+		it      vocab.Item
+		want    *JRD
+		wantErr bool
+	}{
+		{
+			name: "with profile URL",
+			it: &vocab.Actor{
+				ID:                "https://example.com/users/jdoe",
+				Type:              vocab.PersonType,
+				PreferredUsername: vocab.DefaultNaturalLanguageValue("jdoe"),
+				URL:               vocab.IRI("https://example.com/@jdoe"),
+			},
+			want: &JRD{
+				Subject: "acct:jdoe@example.com",
+				Aliases: []string{"https://example.com/users/jdoe", "https://example.com/@jdoe"},
+				Links: []Link{
+					{Rel: RelSelf, Type: ActivityJSONType, Href: "https://example.com/users/jdoe"},
+					{Rel: RelProfilePage, Type: "text/html", Href: "https://example.com/@jdoe"},
+				},
+			},
+		},
+		{
+			name: "without profile URL",
+			it: &vocab.Actor{
+				ID:                "https://example.com/users/jdoe",
+				Type:              vocab.ServiceType,
+				PreferredUsername: vocab.DefaultNaturalLanguageValue("jdoe"),
+			},
+			want: &JRD{
+				Subject: "acct:jdoe@example.com",
+				Aliases: []string{"https://example.com/users/jdoe"},
+				Links:   []Link{{Rel: RelSelf, Type: ActivityJSONType, Href: "https://example.com/users/jdoe"}},
+			},
+		},
+		{
+			name:    "without preferred username",
+			it:      &vocab.Actor{ID: "https://example.com/users/jdoe", Type: vocab.PersonType},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FromActor(tt.it)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FromActor() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FromActor() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}